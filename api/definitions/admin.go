@@ -0,0 +1,28 @@
+package definitions
+
+import "time"
+
+// ErrorRecord describes a request that ended in a server error
+type ErrorRecord struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	Path    string    `json:"path"`
+	Status  int       `json:"status"`
+	Message string    `json:"message"`
+}
+
+// ReadOnlyState reports or sets whether the server refuses writes
+type ReadOnlyState struct {
+	ReadOnly bool `json:"read_only"`
+}
+
+// AdminStats reports the repository's internal state and the server's
+// uptime. The repository fields other than users are zero when the
+// repository does not track them.
+type AdminStats struct {
+	Users       int    `json:"users"`
+	SoftDeleted int    `json:"soft_deleted"`
+	NextID      int    `json:"next_id"`
+	MemoryBytes int64  `json:"memory_bytes"`
+	Uptime      string `json:"uptime" example:"1h2m3s"`
+}
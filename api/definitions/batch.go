@@ -0,0 +1,18 @@
+package definitions
+
+import "encoding/json"
+
+// BatchRequest is one sub-request of a batch. Body, if given, is sent as
+// the sub-request's JSON body.
+type BatchRequest struct {
+	Method string          `json:"method" example:"POST"`
+	Path   string          `json:"path" example:"/users"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+// BatchResponse is the outcome of one sub-request of a batch. Body holds the
+// response as JSON, or as a string if it was not JSON, and is omitted if empty.
+type BatchResponse struct {
+	Status int             `json:"status" example:"201"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
@@ -9,4 +9,121 @@ type CalculatorRequest struct {
 // CalculatorResponse represents a generic calculator operation response
 type CalculatorResponse struct {
 	Result float64 `json:"result"`
-}
\ No newline at end of file
+}
+
+// DotProductRequest represents the request body for a dot product
+type DotProductRequest struct {
+	A []float64 `json:"a"`
+	B []float64 `json:"b"`
+}
+
+// MatrixMultiplyRequest represents the request body for multiplying matrix
+// a by matrix b, each given as an array of rows
+type MatrixMultiplyRequest struct {
+	A [][]float64 `json:"a"`
+	B [][]float64 `json:"b"`
+}
+
+// MatrixResponse holds a matrix result as an array of rows
+type MatrixResponse struct {
+	Result [][]float64 `json:"result"`
+}
+
+// VarianceRequest represents the request body for variance and standard
+// deviation. Sample selects the sample (n-1) rather than population variance.
+type VarianceRequest struct {
+	Values []float64 `json:"values"`
+	Sample bool      `json:"sample"`
+}
+
+// VarianceResponse reports the variance and standard deviation of a dataset
+type VarianceResponse struct {
+	Variance float64 `json:"variance"`
+	StdDev   float64 `json:"stddev"`
+}
+
+// ComplexNumber is a complex number with real part Re and imaginary part Im
+type ComplexNumber struct {
+	Re float64 `json:"re" example:"1"`
+	Im float64 `json:"im" example:"2"`
+}
+
+// MapRequest represents the request body for applying one operation to
+// every element of values, with operand as its second operand
+type MapRequest struct {
+	Values  []float64 `json:"values"`
+	Op      string    `json:"op" example:"multiply"`
+	Operand float64   `json:"operand" example:"2"`
+}
+
+// ComplexRequest represents the request body for an operation on complex
+// numbers a and b
+type ComplexRequest struct {
+	A ComplexNumber `json:"a"`
+	B ComplexNumber `json:"b"`
+}
+
+// ComplexResponse holds the complex result of an operation
+type ComplexResponse struct {
+	Result ComplexNumber `json:"result"`
+}
+
+// OperationPropertiesResponse reports the algebraic properties of a binary
+// operation. Identity is omitted when the operation has none.
+type OperationPropertiesResponse struct {
+	Operation   string   `json:"operation" example:"add"`
+	Commutative bool     `json:"commutative"`
+	Associative bool     `json:"associative"`
+	HasIdentity bool     `json:"has_identity"`
+	Identity    *float64 `json:"identity,omitempty" example:"0"`
+}
+
+// MovingAverageRequest represents the request body for a trailing moving
+// average of values over window values
+type MovingAverageRequest struct {
+	Values []float64 `json:"values"`
+	Window int       `json:"window" example:"3"`
+}
+
+// ValuesRequest represents the request body for a statistic of values
+type ValuesRequest struct {
+	Values []float64 `json:"values"`
+}
+
+// PercentileRequest represents the request body for the p-th percentile of
+// values
+type PercentileRequest struct {
+	Values []float64 `json:"values"`
+	P      *float64  `json:"p" example:"95"`
+}
+
+// EvaluateRequest represents the request body for evaluating an expression,
+// with values for the variables it uses
+type EvaluateRequest struct {
+	Expr string             `json:"expr" example:"x * y + 2"`
+	Vars map[string]float64 `json:"vars,omitempty"`
+}
+
+// BatchEvalResult is the outcome of evaluating one expression in a batch.
+// Exactly one of Result and Error is set.
+type BatchEvalResult struct {
+	Index  int      `json:"index"`
+	Expr   string   `json:"expr"`
+	Result *float64 `json:"result,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// BatchEvalResponse reports the outcome of a batch of expressions in input order
+type BatchEvalResponse struct {
+	Results   []BatchEvalResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// MoneyRequest represents the request body for an operation on two dollar
+// amounts such as "$1,234.56"
+type MoneyRequest struct {
+	A  string `json:"a" example:"$1,234.56"`
+	B  string `json:"b" example:"$10"`
+	Op string `json:"op" example:"add" enums:"add,subtract"`
+}
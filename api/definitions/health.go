@@ -0,0 +1,24 @@
+package definitions
+
+// HealthResponse reports that the server is up, for how long, and which
+// build is running. Version and commit are omitted when not set at build time.
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Uptime  string `json:"uptime"`
+	Version string `json:"version,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+}
+
+// HealthCheckResult is the outcome of one registered health check
+type HealthCheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DetailedHealthResponse aggregates every registered health check. Status is
+// "ok" only when all checks pass, and "unavailable" otherwise.
+type DetailedHealthResponse struct {
+	Status string              `json:"status"`
+	Checks []HealthCheckResult `json:"checks"`
+}
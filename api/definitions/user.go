@@ -26,12 +26,67 @@ type UserResponse struct {
 	Email    string `json:"email"`
 }
 
+// UserSummary is the reduced user returned by GET /users?view=summary for
+// list views, leaving out the email address
+type UserSummary struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
 // UsersResponse represents a list of users
 type UsersResponse struct {
 	Users []UserResponse `json:"users"`
+	Total int            `json:"total"`
+}
+
+// UsersPage is a page of users listed by cursor. NextCursor is passed as the
+// cursor query parameter to fetch the following page, and is empty on the
+// last one.
+type UsersPage struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+// PagedUsersResponse is a page of users listed by offset, along with what a
+// client needs to render pagination controls. Data holds users, or their
+// projections when fields or view are given.
+type PagedUsersResponse struct {
+	Data interface{} `json:"data" swaggertype:"array,object"`
+	Page PageInfo    `json:"page"`
+}
+
+// PageInfo describes a page of a list. Limit is 0 when the page is
+// unbounded, and HasMore reports whether items follow the page.
+type PageInfo struct {
+	Offset  int  `json:"offset"`
+	Limit   int  `json:"limit"`
+	Total   int  `json:"total"`
+	HasMore bool `json:"has_more"`
 }
 
 // ErrorResponse represents an API error
 type ErrorResponse struct {
 	Error string `json:"error"`
-}
\ No newline at end of file
+}
+
+// BulkUserResult is the outcome of creating or updating one user in a bulk request.
+// Exactly one of User and Error is set.
+type BulkUserResult struct {
+	Index int           `json:"index"`
+	User  *UserResponse `json:"user,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+// BulkUsersResponse reports the outcome of a bulk request in input order
+type BulkUsersResponse struct {
+	Results []BulkUserResult `json:"results"`
+	Created int              `json:"created"`
+	Failed  int              `json:"failed"`
+}
+
+// BulkUpdateResponse reports the outcome of a bulk update in input order
+type BulkUpdateResponse struct {
+	Results []BulkUserResult `json:"results"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+}
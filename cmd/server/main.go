@@ -1,9 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
+	"flag"
 	"fmt"
+	"io"
 	"log"
-	"net/http"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	_ "go-testing/docs" // Import for swagger
 	"go-testing/internal/api"
@@ -11,17 +21,275 @@ import (
 	"go-testing/internal/database"
 )
 
+// Build metadata, injected at link time:
+//
+//	go build -ldflags "-X main.version=v1.0.0 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version string
+	commit  string
+)
+
 func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file; serves HTTPS with HTTP/2 when set with -tls-key")
+	tlsKey := flag.String("tls-key", "", "TLS private key file")
+	debug := flag.Bool("debug", false, "mount pprof profiling handlers under /debug/pprof/")
+	accessLog := flag.Bool("access-log", false, "write a Common Log Format line to stdout for every request")
+	auditLog := flag.String("audit-log", "", "append a JSON line to this file for every user created, updated or deleted")
+	drainTimeout := flag.Duration("drain-timeout", 10*time.Second, "how long shutdown waits for in-flight requests before closing connections")
+	idempotencyTTL := flag.Duration("idempotency-ttl", api.DefaultIdempotencyTTL, "how long responses are replayed for a repeated Idempotency-Key; 0 disables idempotency keys")
+	duplicateWindow := flag.Duration("duplicate-window", 0, "how long a POST with the same client, URI and body as an earlier one is rejected as a duplicate; 0 disables")
+	requestTimeout := flag.Duration("request-timeout", 0, "cancel requests that take longer than this and report the deadline in X-Deadline; 0 disables")
+	slowThreshold := flag.Duration("slow-request-threshold", api.DefaultSlowRequestThreshold, "how long a request may take before it is logged as slow; 0 disables")
+	cacheTTL := flag.Duration("cache-ttl", 0, "how long successful GET responses under /users are cached; 0 disables the cache")
+	cacheSize := flag.Int("cache-size", 1000, "how many responses the cache holds")
+	canaryPercent := flag.Int("canary-percent", 0, "percentage of clients, bucketed by IP, whose requests are tagged as canary traffic; 0 disables tagging")
+	strictQuery := flag.Bool("strict-query", false, "reject calculator requests with query parameters the endpoint does not accept")
+	strictAccept := flag.Bool("strict-accept", false, "respond 406 to requests whose Accept header rules out JSON instead of sending JSON anyway")
+	validateUTF8 := flag.Bool("validate-utf8", false, "reject requests whose body is not valid UTF-8 instead of replacing the invalid bytes")
+	pageEnvelope := flag.Bool("page-envelope", false, "wrap GET /users results in {\"data\",\"page\"} with offset, limit, total and has_more")
+	flag.Parse()
+	if (*tlsCert == "") != (*tlsKey == "") {
+		log.Fatal("-tls-cert and -tls-key must be set together")
+	}
+
 	// Initialize database repository
-	repo := database.NewUserRepository()
-	
+	dsn := os.Getenv("USERS_DSN")
+	if dsn == "" {
+		dsn = "memory://"
+	}
+	repoOpts := []database.RepositoryOption{
+		database.WithLogger(slog.Default()),
+		database.WithUniqueEmails(),
+		database.WithIDOffset(envInt("USERS_ID_OFFSET", 1)),
+	}
+	if key := os.Getenv("USERS_EMAIL_KEY"); key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			log.Fatalf("Decoding USERS_EMAIL_KEY: %v", err)
+		}
+		repoOpts = append(repoOpts, database.WithEmailEncryption(decoded))
+	}
+	retention := envDuration("USERS_SOFT_DELETE_RETENTION")
+	if retention > 0 {
+		repoOpts = append(repoOpts, database.WithSoftDelete(retention))
+	}
+	if interval := envDuration("USERS_FLUSH_INTERVAL"); interval > 0 {
+		repoOpts = append(repoOpts, database.WithFlushInterval(interval))
+	}
+	repo, err := database.NewRepositoryFromDSN(dsn, repoOpts...)
+	if err != nil {
+		log.Fatalf("Opening repository: %v", err)
+	}
+	if closer, ok := repo.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				log.Printf("Closing repository: %v", err)
+			}
+		}()
+	}
+	if compactor, ok := repo.(interface {
+		StartCompaction(interval time.Duration)
+	}); ok && retention > 0 {
+		compactor.StartCompaction(max(retention/2, time.Second))
+	}
+	if err := repo.Migrate(); err != nil {
+		log.Fatalf("Migrating repository: %v", err)
+	}
+	if retries := envInt("USERS_RETRIES", 0); retries > 0 {
+		backoff := envDuration("USERS_RETRY_BACKOFF")
+		if backoff == 0 {
+			backoff = 100 * time.Millisecond
+		}
+		repo = database.NewRetryingUserRepository(repo, retries, backoff)
+	}
+	if threshold := envInt("USERS_BREAKER_THRESHOLD", 0); threshold > 0 {
+		resetTimeout := envDuration("USERS_BREAKER_RESET_TIMEOUT")
+		if resetTimeout == 0 {
+			resetTimeout = 30 * time.Second
+		}
+		repo = database.NewCircuitBreakerRepository(repo, threshold, resetTimeout)
+	}
+
 	// Initialize calculator service
 	calc := calculator.NewCalculator()
-	
+
 	// Initialize API server with dependencies
-	server := api.NewServer(repo, calc)
-	
+	opts := []api.Option{
+		api.WithCalculatorSessions(30 * time.Minute),
+		api.WithBuildInfo(api.BuildInfo{Version: version, Commit: commit}),
+		api.WithDrainTimeout(*drainTimeout),
+		api.WithRequestTimeout(*requestTimeout),
+		api.WithLogger(slog.Default()),
+		api.WithSlowRequestThreshold(*slowThreshold),
+	}
+	if *debug {
+		opts = append(opts, api.WithPprof())
+	}
+	if *idempotencyTTL > 0 {
+		opts = append(opts, api.WithIdempotency(*idempotencyTTL))
+	}
+	if *duplicateWindow > 0 {
+		opts = append(opts, api.WithDuplicateDetection(*duplicateWindow))
+	}
+	if *cacheTTL > 0 {
+		opts = append(opts, api.WithResponseCache(*cacheTTL, *cacheSize))
+	}
+	if *canaryPercent > 0 {
+		opts = append(opts, api.WithCanary(*canaryPercent))
+	}
+	if *strictQuery {
+		opts = append(opts, api.WithStrictQuery())
+	}
+	if *strictAccept {
+		opts = append(opts, api.WithStrictAccept())
+	}
+	if *validateUTF8 {
+		opts = append(opts, api.WithUTF8Validation())
+	}
+	if *pageEnvelope {
+		opts = append(opts, api.WithPageEnvelope())
+	}
+	if *accessLog {
+		opts = append(opts, api.WithAccessLog(os.Stdout))
+	}
+	if *auditLog != "" {
+		f, err := os.OpenFile(*auditLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			log.Fatalf("Opening audit log: %v", err)
+		}
+		defer f.Close()
+		opts = append(opts, api.WithAuditLog(f))
+	}
+	if routes := os.Getenv("DISABLED_ROUTES"); routes != "" {
+		opts = append(opts, api.WithDisabledRoutes(strings.Split(routes, ",")...))
+	}
+	if limits := os.Getenv("CONCURRENCY_LIMITS"); limits != "" {
+		opts = append(opts, api.WithConcurrencyLimits(parseConcurrencyLimits(limits)))
+	}
+	if ops := os.Getenv("CALCULATOR_OPERATIONS"); ops != "" {
+		opts = append(opts, api.WithCalculatorOperations(strings.Split(ops, ",")...))
+	}
+	if hosts := os.Getenv("ALLOWED_HOSTS"); hosts != "" {
+		opts = append(opts, api.WithAllowedHosts(strings.Split(hosts, ",")...))
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		opts = append(opts, api.WithJWTAuth([]byte(secret)))
+	}
+	if keys := os.Getenv("API_KEYS"); keys != "" {
+		opts = append(opts, api.WithAPIKeys(parseAPIKeys(keys)))
+	}
+	if roles := os.Getenv("API_KEY_ROLES"); roles != "" {
+		opts = append(opts, api.WithAPIKeyRoles(parseAPIKeyRoles(roles)))
+	}
+	if secret := os.Getenv("REQUEST_SIGNING_SECRET"); secret != "" {
+		opts = append(opts, api.WithRequestSigning([]byte(secret)))
+	}
+	opts = append(opts, api.WithPageSizes(
+		envInt("USERS_DEFAULT_PAGE_SIZE", api.DefaultPageSize),
+		envInt("USERS_MAX_PAGE_SIZE", api.DefaultMaxPageSize),
+	))
+	server := api.NewServer(repo, calc, opts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Start server
-	fmt.Println("Starting server on :8080...")
-	log.Fatal(http.ListenAndServe(":8080", server.Router()))
-}
\ No newline at end of file
+	go func() {
+		if *tlsCert != "" {
+			fmt.Printf("Starting server on %s (HTTPS)...\n", *addr)
+		} else {
+			fmt.Printf("Starting server on %s...\n", *addr)
+		}
+		if err := server.RunTLS(*addr, *tlsCert, *tlsKey); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// Shut down cleanly on interrupt
+	<-ctx.Done()
+	fmt.Println("Shutting down...")
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		log.Printf("Server shutdown: %v", err)
+	}
+}
+
+// parseAPIKeys reads comma-separated identity=key pairs, such as
+// "ci=k3y,alice=s3cret", into a map from key to identity. A bare key has no
+// identity.
+func parseAPIKeys(value string) map[string]string {
+	keys := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		identity, key, ok := strings.Cut(entry, "=")
+		if !ok {
+			identity, key = "", entry
+		}
+		keys[key] = strings.TrimSpace(identity)
+	}
+	return keys
+}
+
+// parseAPIKeyRoles reads comma-separated identity=role pairs, such as
+// "alice=admin,ci=reader". An identity listed more than once has every role.
+func parseAPIKeyRoles(value string) map[string][]string {
+	roles := map[string][]string{}
+	for _, entry := range strings.Split(value, ",") {
+		identity, role, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(role) == "" {
+			log.Printf("Ignoring invalid API key role %q", entry)
+			continue
+		}
+		identity = strings.TrimSpace(identity)
+		roles[identity] = append(roles[identity], strings.TrimSpace(role))
+	}
+	return roles
+}
+
+// parseConcurrencyLimits reads comma-separated route=limit pairs, such as
+// "POST /users=10,/calculator/evaluate=50", skipping invalid entries
+func parseConcurrencyLimits(value string) map[string]int {
+	limits := map[string]int{}
+	for _, entry := range strings.Split(value, ",") {
+		i := strings.LastIndex(entry, "=")
+		limit, err := strconv.Atoi(entry[i+1:])
+		if i < 0 || err != nil || limit < 1 {
+			log.Printf("Ignoring invalid concurrency limit %q", entry)
+			continue
+		}
+		limits[strings.TrimSpace(entry[:i])] = limit
+	}
+	return limits
+}
+
+// envInt reads a positive integer from the named environment variable,
+// falling back to def when it is unset or invalid
+func envInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		log.Printf("Ignoring invalid %s=%q, using %d", name, value, def)
+		return def
+	}
+	return n
+}
+
+// envDuration reads a duration such as "72h" from the environment variable
+// name, returning 0 if it is unset or invalid
+func envDuration(name string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Ignoring invalid %s=%q", name, value)
+		return 0
+	}
+	return d
+}
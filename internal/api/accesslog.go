@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// clfTimeFormat is the timestamp layout of the Common Log Format
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// accessLog writes one line per request to an io.Writer, serializing writes
+// so lines from concurrent requests never interleave
+type accessLog struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// logAccess writes a Common Log Format line for every request once it has
+// been handled, as read by Apache and Nginx log analyzers:
+//
+//	127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /users HTTP/1.1" 200 2326
+func (s *Server) logAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received := s.now()
+		sw := &statusWriter{ResponseWriter: w}
+
+		next.ServeHTTP(sw, r)
+		sw.finish()
+
+		host := clientIP(r)
+		uri := r.RequestURI
+		if uri == "" {
+			uri = r.URL.RequestURI()
+		}
+		size := "-"
+		if sw.size > 0 {
+			size = strconv.FormatInt(sw.size, 10)
+		}
+
+		s.accessLog.mutex.Lock()
+		defer s.accessLog.mutex.Unlock()
+		fmt.Fprintf(s.accessLog.w, "%s - - [%s] \"%s %s %s\" %d %s\n",
+			host, received.Format(clfTimeFormat), r.Method, uri, r.Proto, sw.status, size)
+	})
+}
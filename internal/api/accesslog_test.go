@@ -0,0 +1,61 @@
+package api
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clfPattern matches a Common Log Format line
+var clfPattern = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "(\S+) (\S+) (\S+)" (\d{3}) (\d+|-)$`)
+
+// TestAccessLog tests that each request produces a Common Log Format line
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	now := time.Date(2025, 3, 14, 15, 9, 26, 0, time.FixedZone("", -7*60*60))
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(),
+		WithAccessLog(&logs), WithClock(func() time.Time { return now }))
+	handler := server.Router()
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		expectedLine string
+	}{
+		{"Success", "GET", "/calculator/add?a=2&b=3",
+			`192.0.2.1 - - [14/Mar/2025:15:09:26 -0700] "GET /calculator/add?a=2&b=3 HTTP/1.1" 200 13`},
+		{"Error", "GET", "/calculator/add?a=2",
+			`192.0.2.1 - - [14/Mar/2025:15:09:26 -0700] "GET /calculator/add?a=2 HTTP/1.1" 400 26`},
+		{"Empty body", "OPTIONS", "/users",
+			`192.0.2.1 - - [14/Mar/2025:15:09:26 -0700] "OPTIONS /users HTTP/1.1" 204 -`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logs.Reset()
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			line := strings.TrimSuffix(logs.String(), "\n")
+			require.Regexp(t, clfPattern, line)
+			assert.Equal(t, tc.expectedLine, line)
+
+			size := clfPattern.FindStringSubmatch(line)[9]
+			if size != "-" {
+				assert.Equal(t, size, strconv.Itoa(rec.Body.Len()))
+			}
+		})
+	}
+}
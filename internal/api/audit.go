@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-testing/internal/database"
+)
+
+// requestIDHeader carries the ID that ties a request to its audit entries.
+// Clients may send their own; otherwise one is generated.
+const requestIDHeader = "X-Request-ID"
+
+// AuditEntry is one line of the audit log, recording a user that was
+// created, updated or deleted
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	Identity  string    `json:"identity,omitempty"` // only when authentication is enabled
+	Action    string    `json:"action"`             // create, update or delete
+	UserID    int       `json:"user_id"`
+}
+
+// auditLog appends JSON lines to an io.Writer, serializing writes so entries
+// from concurrent requests never interleave
+type auditLog struct {
+	mutex sync.Mutex
+	enc   *json.Encoder
+}
+
+func newAuditLog(w io.Writer) *auditLog {
+	return &auditLog{enc: json.NewEncoder(w)}
+}
+
+func (l *auditLog) record(entry AuditEntry) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.enc.Encode(entry)
+}
+
+// auditedRepository records every successful write made through it during
+// one request in the audit log
+type auditedRepository struct {
+	database.UserRepository
+
+	log       *auditLog
+	now       func() time.Time
+	requestID string
+	identity  string
+}
+
+// auditMutations gives every request an ID, echoed in the X-Request-ID
+// response header, and wraps the request's repository so its writes are
+// audited. It runs inside cacheRepoLookups and after authentication, so it
+// wraps the request's view of the repository and knows who made the request.
+func (s *Server) auditMutations(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = rand.Text()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		audited := &auditedRepository{
+			UserRepository: s.users(r),
+			log:            s.auditLog,
+			now:            s.now,
+			requestID:      requestID,
+			identity:       requestIdentity(r.Context()),
+		}
+		ctx := context.WithValue(r.Context(), repoContextKey, database.UserRepository(audited))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// CreateUser creates the user and audits it
+func (a *auditedRepository) CreateUser(user *database.User) error {
+	if err := a.UserRepository.CreateUser(user); err != nil {
+		return err
+	}
+	a.record("create", user.ID)
+	return nil
+}
+
+// UpdateUser updates the user and audits it
+func (a *auditedRepository) UpdateUser(user *database.User) error {
+	if err := a.UserRepository.UpdateUser(user); err != nil {
+		return err
+	}
+	a.record("update", user.ID)
+	return nil
+}
+
+// DeleteUser deletes the user and audits it
+func (a *auditedRepository) DeleteUser(id int) error {
+	if err := a.UserRepository.DeleteUser(id); err != nil {
+		return err
+	}
+	a.record("delete", id)
+	return nil
+}
+
+// UpdateUsers updates users through the wrapped repository and audits each
+// one that was updated
+func (a *auditedRepository) UpdateUsers(users []*database.User) []error {
+	errs := updateUsers(a.UserRepository, users)
+	for i, user := range users {
+		if errs[i] == nil {
+			a.record("update", user.ID)
+		}
+	}
+	return errs
+}
+
+func (a *auditedRepository) record(action string, userID int) {
+	a.log.record(AuditEntry{
+		Time:      a.now(),
+		RequestID: a.requestID,
+		Identity:  a.identity,
+		Action:    action,
+		UserID:    userID,
+	})
+}
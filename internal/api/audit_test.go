@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuditLog tests the entries recorded for a create-then-delete sequence
+func TestAuditLog(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	handler := NewServer(database.NewUserRepository(), calculator.NewCalculator(),
+		WithAuditLog(&buf), WithJWTAuth(testJWTKey), WithClock(func() time.Time { return now })).Router()
+	token := signToken(t, jwt.MapClaims{"sub": "alice", "role": "admin", "exp": time.Now().Add(time.Hour).Unix()})
+
+	send := func(method, path, requestID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if requestID != "" {
+			req.Header.Set(requestIDHeader, requestID)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	created := send("POST", "/users", "req-1", `{"username":"bob","email":"bob@example.com"}`)
+	require.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, "req-1", created.Header().Get(requestIDHeader))
+
+	// Failed writes and reads are not audited
+	require.Equal(t, http.StatusNotFound, send("DELETE", "/users/99", "", "").Code)
+	require.Equal(t, http.StatusOK, send("GET", "/users/1", "", "").Code)
+
+	now = now.Add(time.Minute)
+	deleted := send("DELETE", "/users/1", "", "")
+	require.Equal(t, http.StatusNoContent, deleted.Code)
+	generatedID := deleted.Header().Get(requestIDHeader)
+	assert.NotEmpty(t, generatedID)
+
+	var entries []AuditEntry
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var entry AuditEntry
+		require.NoError(t, dec.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	assert.Equal(t, []AuditEntry{
+		{Time: now.Add(-time.Minute), RequestID: "req-1", Identity: "alice", Action: "create", UserID: 1},
+		{Time: now, RequestID: generatedID, Identity: "alice", Action: "delete", UserID: 1},
+	}, entries)
+}
+
+// TestAuditLogWithoutAuth tests that entries omit the identity when
+// authentication is off
+func TestAuditLogWithoutAuth(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewServer(database.NewUserRepository(), calculator.NewCalculator(), WithAuditLog(&buf)).Router()
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"username":"bob","email":"bob@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.NotContains(t, entry, "identity")
+	assert.Equal(t, "create", entry["action"])
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// contextKey is the type of the request context keys set by this package
+type contextKey string
+
+const (
+	claimsContextKey   contextKey = "claims"
+	identityContextKey contextKey = "identity"
+)
+
+// ClaimsFromContext returns the JWT claims of an authenticated request
+func ClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(jwt.MapClaims)
+	return claims, ok
+}
+
+// IdentityFromContext returns the identity of the API key a request was
+// authenticated with
+func IdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(identityContextKey).(string)
+	return identity, ok
+}
+
+// requestIdentity returns who made a request: the identity of its API key,
+// or the subject of its JWT. It is empty when authentication is off.
+func requestIdentity(ctx context.Context) string {
+	if identity, ok := IdentityFromContext(ctx); ok {
+		return identity
+	}
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		subject, _ := claims.GetSubject()
+		return subject
+	}
+	return ""
+}
+
+// jwtAuth requires an "Authorization: Bearer <jwt>" header signed with the
+// configured HMAC key and not expired. The token's claims are placed in the
+// request context for handlers and later middleware.
+func (s *Server) jwtAuth(next http.Handler) http.Handler {
+	keyFunc := func(*jwt.Token) (interface{}, error) {
+		return s.jwtKey, nil
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// OPTIONS only describes a route, so discovery needs no credentials
+		if r.Method == http.MethodOptions || isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			respondUnauthorized(w, r, "Missing bearer token")
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(tokenString, claims, keyFunc,
+			jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}),
+			jwt.WithExpirationRequired(),
+		)
+		if err != nil {
+			respondUnauthorized(w, r, "Invalid or expired token")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), claimsContextKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// apiKeyAuth requires an X-API-Key header matching one of the configured
+// keys, and places the identity the key belongs to in the request context.
+// It rejects a request using only its headers, so a client that sent
+// "Expect: 100-continue" is refused before it uploads the body: the server
+// only sends 100 Continue once a handler starts reading the body.
+func (s *Server) apiKeyAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, ok := s.apiKeyIdentity(r.Header.Get("X-API-Key"))
+		if !ok {
+			respondError(w, r, http.StatusUnauthorized, "Invalid or missing API key")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), identityContextKey, identity)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// apiKeyIdentity returns the identity of key if it is one of the configured
+// API keys. Every key is compared in constant time, so response timing leaks
+// neither key prefixes nor which key matched.
+func (s *Server) apiKeyIdentity(key string) (string, bool) {
+	identity, valid := "", false
+	for candidate, owner := range s.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+			identity, valid = owner, true
+		}
+	}
+	return identity, key != "" && valid
+}
+
+// respondUnauthorized responds with 401 and a bearer challenge
+func respondUnauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer`)
+	respondError(w, r, http.StatusUnauthorized, message)
+}
+
+// isPublicPath reports whether path is served without authentication
+func isPublicPath(path string) bool {
+	return isHealthCheck(path) || strings.HasPrefix(path, "/swagger/")
+}
+
+// requireRole only lets requests whose authenticated identity carries role
+// through to next, responding 403 otherwise. It is a no-op when the server
+// has no authentication configured, since there is no identity to check.
+func (s *Server) requireRole(role string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authEnabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		roles, authenticated := s.rolesFromContext(r.Context())
+		if !authenticated {
+			respondUnauthorized(w, r, "Authentication required")
+			return
+		}
+
+		for _, granted := range roles {
+			if granted == role {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		respondError(w, r, http.StatusForbidden, "Insufficient privileges")
+	})
+}
+
+// requireAdmin guards the /admin/ endpoints: it lets only requests with the
+// admin role through to next, and refuses every request with 403 when the
+// server has no authentication configured, since anyone could call them
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	restricted := s.requireRole("admin", next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authEnabled() {
+			respondError(w, r, http.StatusForbidden, "Admin endpoints require authentication to be configured")
+			return
+		}
+
+		restricted.ServeHTTP(w, r)
+	})
+}
+
+// authEnabled reports whether requests are authenticated
+func (s *Server) authEnabled() bool {
+	return s.jwtKey != nil || len(s.apiKeys) > 0
+}
+
+// rolesFromContext returns the roles of the authenticated identity: those
+// granted to its API key's identity with WithAPIKeyRoles, and those read from
+// the JWT "role" (string) or "roles" (array of strings) claims
+func (s *Server) rolesFromContext(ctx context.Context) ([]string, bool) {
+	identity, hasIdentity := IdentityFromContext(ctx)
+	claims, hasClaims := ClaimsFromContext(ctx)
+	if !hasIdentity && !hasClaims {
+		return nil, false
+	}
+
+	var roles []string
+	if hasIdentity {
+		roles = append(roles, s.apiKeyRoles[identity]...)
+	}
+	if role, ok := claims["role"].(string); ok {
+		roles = append(roles, role)
+	}
+	if list, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range list {
+			if role, ok := role.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	return roles, true
+}
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testJWTKey = []byte("test-secret")
+
+// testAdminKey is the API key withTestAdmin configures
+const testAdminKey = "admin-key"
+
+// withTestAdmin configures testAdminKey as the only API key, for an identity
+// with the admin role
+func withTestAdmin() Option {
+	return func(s *Server) {
+		WithAPIKeys(map[string]string{testAdminKey: "admin"})(s)
+		WithAPIKeyRoles(map[string][]string{"admin": {"admin"}})(s)
+	}
+}
+
+// asAdmin authenticates r with testAdminKey
+func asAdmin(r *http.Request) *http.Request {
+	r.Header.Set("X-API-Key", testAdminKey)
+	return r
+}
+
+// signToken creates an HS256 token with the given claims
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testJWTKey)
+	require.NoError(t, err)
+	return token
+}
+
+// TestJWTAuth tests bearer token validation
+func TestJWTAuth(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithJWTAuth(testJWTKey))
+	handler := server.Router()
+
+	valid := signToken(t, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+	expired := signToken(t, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix()})
+
+	// Tamper with the payload while keeping the original signature
+	parts := strings.Split(valid, ".")
+	forged := signToken(t, jwt.MapClaims{"sub": "mallory", "exp": time.Now().Add(time.Hour).Unix()})
+	tampered := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+
+	tests := []struct {
+		name           string
+		path           string
+		authorization  string
+		expectedStatus int
+	}{
+		{"Valid token", "/calculator/add?a=1&b=2", "Bearer " + valid, http.StatusOK},
+		{"Expired token", "/calculator/add?a=1&b=2", "Bearer " + expired, http.StatusUnauthorized},
+		{"Tampered signature", "/calculator/add?a=1&b=2", "Bearer " + tampered, http.StatusUnauthorized},
+		{"Missing token", "/calculator/add?a=1&b=2", "", http.StatusUnauthorized},
+		{"Wrong scheme", "/calculator/add?a=1&b=2", "Basic " + valid, http.StatusUnauthorized},
+		{"Documentation is public", "/swagger/index.html", "", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+// TestJWTAuthClaimsInContext tests that validated claims reach the handler
+func TestJWTAuthClaimsInContext(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithJWTAuth(testJWTKey))
+
+	var subject interface{}
+	handler := server.jwtAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		assert.True(t, ok)
+		subject = claims["sub"]
+	}))
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "alice", subject)
+}
+
+// TestRequireRole tests that deleting users is restricted to admins
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name           string
+		claims         jwt.MapClaims
+		expectDelete   bool
+		expectedStatus int
+	}{
+		{"Admin role", jwt.MapClaims{"sub": "alice", "role": "admin"}, true, http.StatusNoContent},
+		{"Admin in roles list", jwt.MapClaims{"sub": "alice", "roles": []string{"user", "admin"}}, true, http.StatusNoContent},
+		{"Regular user", jwt.MapClaims{"sub": "bob", "role": "user"}, false, http.StatusForbidden},
+		{"No role", jwt.MapClaims{"sub": "carol"}, false, http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			if tc.expectDelete {
+				mockRepo.On("DeleteUser", 1).Return(nil).Once()
+			}
+			server := NewServer(mockRepo, calculator.NewCalculator(), WithJWTAuth(testJWTKey))
+
+			tc.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			req := httptest.NewRequest("DELETE", "/users/1", nil)
+			req.Header.Set("Authorization", "Bearer "+signToken(t, tc.claims))
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRequireRoleWithAPIKeys tests that with only API keys configured,
+// deleting users is restricted to keys whose identity has the admin role
+func TestRequireRoleWithAPIKeys(t *testing.T) {
+	tests := []struct {
+		name           string
+		apiKey         string
+		expectDelete   bool
+		expectedStatus int
+	}{
+		{"Admin identity", "admin-key", true, http.StatusNoContent},
+		{"Identity with other roles", "reader-key", false, http.StatusForbidden},
+		{"Identity without roles", "ci-key", false, http.StatusForbidden},
+		{"No key", "", false, http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			if tc.expectDelete {
+				mockRepo.On("DeleteUser", 1).Return(nil).Once()
+			}
+			server := NewServer(mockRepo, calculator.NewCalculator(),
+				WithAPIKeys(map[string]string{"admin-key": "alice", "reader-key": "bob", "ci-key": "ci"}),
+				WithAPIKeyRoles(map[string][]string{"alice": {"admin"}, "bob": {"reader"}}))
+
+			req := httptest.NewRequest("DELETE", "/users/1", nil)
+			if tc.apiKey != "" {
+				req.Header.Set("X-API-Key", tc.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRequireRoleWithoutAuth tests that authorization is skipped when authentication is off
+func TestRequireRoleWithoutAuth(t *testing.T) {
+	server, mockRepo, _ := setupTestServer()
+	mockRepo.On("DeleteUser", 1).Return(nil).Once()
+
+	req := httptest.NewRequest("DELETE", "/users/1", nil)
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAPIKeyAuth tests X-API-Key validation
+func TestAPIKeyAuth(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithAPIKeys(map[string]string{"key-one": "alice", " key-two ": "ci", "": "nobody"}))
+	handler := server.Router()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		apiKey         string
+		expectedStatus int
+	}{
+		{"First key", "GET", "/calculator/add?a=1&b=2", "key-one", http.StatusOK},
+		{"Second key is trimmed", "GET", "/calculator/add?a=1&b=2", "key-two", http.StatusOK},
+		{"Wrong key", "GET", "/calculator/add?a=1&b=2", "key-three", http.StatusUnauthorized},
+		{"Key prefix", "GET", "/calculator/add?a=1&b=2", "key-", http.StatusUnauthorized},
+		{"Missing key", "GET", "/calculator/add?a=1&b=2", "", http.StatusUnauthorized},
+		{"Health is public", "GET", "/health", "", http.StatusOK},
+		{"OPTIONS is public", "OPTIONS", "/users", "", http.StatusNoContent},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.apiKey != "" {
+				req.Header.Set("X-API-Key", tc.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
+
+// TestAPIKeyIdentityInContext tests that the identity of the API key used
+// reaches the handler
+func TestAPIKeyIdentityInContext(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(),
+		WithAPIKeys(map[string]string{"key-one": "alice", "key-two": "ci"}))
+
+	for key, expected := range map[string]string{"key-one": "alice", "key-two": "ci"} {
+		t.Run(expected, func(t *testing.T) {
+			var identity string
+			var ok bool
+			handler := server.apiKeyAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				identity, ok = IdentityFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/users", nil)
+			req.Header.Set("X-API-Key", key)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.True(t, ok)
+			assert.Equal(t, expected, identity)
+		})
+	}
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, ok := IdentityFromContext(httptest.NewRequest("GET", "/health", nil).Context())
+		assert.False(t, ok)
+	})
+}
+
+// TestAPIKeyExpectContinue tests that a client waiting for 100 Continue is
+// refused on its headers alone when the API key is wrong, and invited to send
+// the body when it is right
+func TestAPIKeyExpectContinue(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithAPIKeys(map[string]string{"secret": "alice"}))
+	ts := httptest.NewServer(server.Router())
+	defer ts.Close()
+
+	// sendHeaders starts a bulk upload announcing a large body, without sending it
+	sendHeaders := func(t *testing.T, apiKey string) (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+		require.NoError(t, err)
+		require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+		_, err = fmt.Fprintf(conn, "POST /users/bulk HTTP/1.1\r\nHost: example.com\r\n"+
+			"X-API-Key: %s\r\nContent-Type: application/json\r\nContent-Length: 2\r\n"+
+			"Expect: 100-continue\r\n\r\n", apiKey)
+		require.NoError(t, err)
+		return conn, bufio.NewReader(conn)
+	}
+
+	t.Run("Bad key is rejected before the body", func(t *testing.T) {
+		conn, reader := sendHeaders(t, "wrong")
+		defer conn.Close()
+
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Good key gets 100 Continue", func(t *testing.T) {
+		conn, reader := sendHeaders(t, "secret")
+		defer conn.Close()
+
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusContinue, resp.StatusCode)
+
+		_, err = conn.Write([]byte("[]"))
+		require.NoError(t, err)
+		resp, err = http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
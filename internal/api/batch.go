@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go-testing/api/definitions"
+)
+
+const (
+	// batchPath is where batches are posted; batches cannot nest
+	batchPath = "/batch"
+	// maxBatchRequests bounds the number of sub-requests in one batch
+	maxBatchRequests = 100
+)
+
+// batch godoc
+// @Summary Make several API calls in one request
+// @Description Dispatch up to 100 sub-requests through the API in order, as if each had been
+// @Description made on its own with this request's headers, and return their responses in the
+// @Description same order. A failed sub-request does not stop the others or fail the batch.
+// @Tags batch
+// @Accept json
+// @Produce json
+// @Param requests body []definitions.BatchRequest true "Sub-requests"
+// @Success 200 {array} definitions.BatchResponse
+// @Failure 400 {object} map[string]string
+// @Router /batch [post]
+func (s *Server) batch(router http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqs []definitions.BatchRequest
+		if err := decodeJSON(w, r, &reqs); err != nil {
+			respondDecodeError(w, r, err)
+			return
+		}
+		if len(reqs) > maxBatchRequests {
+			respondError(w, r, http.StatusBadRequest, fmt.Sprintf("at most %d requests per batch", maxBatchRequests))
+			return
+		}
+
+		resps := make([]definitions.BatchResponse, len(reqs))
+		for i, req := range reqs {
+			resps[i] = dispatchBatchRequest(router, r, req)
+		}
+		respondJSON(w, r, http.StatusOK, resps)
+	}
+}
+
+// dispatchBatchRequest serves one sub-request of the batch parent through
+// router and captures its response
+func dispatchBatchRequest(router http.Handler, parent *http.Request, req definitions.BatchRequest) definitions.BatchResponse {
+	if req.Method == "" || !strings.HasPrefix(req.Path, "/") {
+		return batchError(http.StatusBadRequest, "method and a path starting with / are required")
+	}
+	if req.Path == batchPath || strings.HasPrefix(req.Path, batchPath+"?") {
+		return batchError(http.StatusBadRequest, "batches cannot be nested")
+	}
+
+	sub, err := http.NewRequestWithContext(parent.Context(), strings.ToUpper(req.Method), req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		return batchError(http.StatusBadRequest, "invalid method or path")
+	}
+	// Sub-requests carry the batch's credentials, but not its body's
+	// description or idempotency key
+	sub.Header = parent.Header.Clone()
+	sub.Header.Del("Content-Length")
+	sub.Header.Del("Idempotency-Key")
+	sub.Header.Del("Content-Type")
+	if len(req.Body) > 0 {
+		sub.Header.Set("Content-Type", "application/json")
+	}
+	sub.Host = parent.Host
+	sub.RemoteAddr = parent.RemoteAddr
+	sub.TLS = parent.TLS
+
+	rec := newBufferedResponse()
+	router.ServeHTTP(rec, sub)
+
+	resp := definitions.BatchResponse{Status: rec.status}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	body := bytes.TrimSpace(rec.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		resp.Body = body
+	default:
+		resp.Body, _ = json.Marshal(string(body))
+	}
+	return resp
+}
+
+// batchError is a sub-request response for a sub-request that was never
+// dispatched
+func batchError(status int, message string) definitions.BatchResponse {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	return definitions.BatchResponse{Status: status, Body: body}
+}
+
+// bufferedResponse is an http.ResponseWriter that keeps the response in memory
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header)}
+}
+
+// Header returns the response headers
+func (w *bufferedResponse) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the first status written
+func (w *bufferedResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write buffers b, implying a 200 status if none was written
+func (w *bufferedResponse) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
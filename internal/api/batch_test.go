@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postBatch posts body to /batch and decodes the sub-request responses
+func postBatch(t *testing.T, handler http.Handler, body string) []definitions.BatchResponse {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resps []definitions.BatchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resps))
+	return resps
+}
+
+// TestBatch tests that sub-requests are dispatched in order, so a user
+// created early in a batch can be fetched later in it
+func TestBatch(t *testing.T) {
+	repo := database.NewUserRepository()
+	handler := NewServer(repo, calculator.NewCalculator()).Router()
+
+	resps := postBatch(t, handler, `[
+		{"method":"POST","path":"/users","body":{"username":"alice","email":"alice@example.com"}},
+		{"method":"GET","path":"/users/1"},
+		{"method":"DELETE","path":"/users/1"}
+	]`)
+	require.Len(t, resps, 3)
+
+	assert.Equal(t, http.StatusCreated, resps[0].Status)
+	var created database.User
+	require.NoError(t, json.Unmarshal(resps[0].Body, &created))
+	assert.Equal(t, 1, created.ID)
+
+	assert.Equal(t, http.StatusOK, resps[1].Status)
+	var fetched database.User
+	require.NoError(t, json.Unmarshal(resps[1].Body, &fetched))
+	assert.Equal(t, created, fetched)
+
+	assert.Equal(t, http.StatusNoContent, resps[2].Status)
+	assert.Empty(t, resps[2].Body)
+}
+
+// TestBatchFailures tests that failed sub-requests are reported without
+// failing the batch
+func TestBatchFailures(t *testing.T) {
+	handler := NewServer(database.NewUserRepository(), calculator.NewCalculator()).Router()
+
+	resps := postBatch(t, handler, `[
+		{"method":"GET","path":"/users/99"},
+		{"method":"POST","path":"/users","body":"alice"},
+		{"method":"POST","path":"/batch","body":[]},
+		{"method":"GET","path":"users"},
+		{"method":"GET","path":"/calculator/add?a=1&b=2"}
+	]`)
+	require.Len(t, resps, 5)
+
+	assert.Equal(t, http.StatusNotFound, resps[0].Status)
+	assert.JSONEq(t, `{"error":"User not found"}`, string(resps[0].Body))
+	assert.Equal(t, http.StatusBadRequest, resps[1].Status)
+	assert.Equal(t, http.StatusBadRequest, resps[2].Status)
+	assert.JSONEq(t, `{"error":"batches cannot be nested"}`, string(resps[2].Body))
+	assert.Equal(t, http.StatusBadRequest, resps[3].Status)
+	assert.Equal(t, http.StatusOK, resps[4].Status)
+	assert.JSONEq(t, `{"result":3}`, string(resps[4].Body))
+}
+
+// TestBatchLimits tests batches rejected as a whole
+func TestBatchLimits(t *testing.T) {
+	handler := NewServer(database.NewUserRepository(), calculator.NewCalculator()).Router()
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{"Not an array", `{"method":"GET","path":"/health"}`, "request body must be an array"},
+		{"Too many requests", "[" + strings.Repeat(`{"method":"GET","path":"/health"},`, maxBatchRequests) + `{"method":"GET","path":"/health"}]`,
+			"at most 100 requests per batch"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/batch", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, `{"error":"`+tc.expectedError+`"}`, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/database"
+)
+
+const (
+	// DefaultBulkWorkers is the number of users a bulk request processes at once
+	DefaultBulkWorkers = 4
+	// maxBulkUsers bounds the size of a single bulk request
+	maxBulkUsers = 1000
+)
+
+// createUsersBulk godoc
+// @Summary Create users in bulk
+// @Description Validate and create up to 1000 users concurrently. Results are
+// @Description returned in input order, each with the created user or the reason it failed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param users body []database.User true "Users to create"
+// @Success 200 {object} definitions.BulkUsersResponse
+// @Failure 400 {object} map[string]string
+// @Router /users/bulk [post]
+func (s *Server) createUsersBulk(w http.ResponseWriter, r *http.Request) {
+	var users []*database.User
+	if err := decodeJSON(w, r, &users); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+	if len(users) > maxBulkUsers {
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("at most %d users per request", maxBulkUsers))
+		return
+	}
+
+	resp := definitions.BulkUsersResponse{Results: make([]definitions.BulkUserResult, len(users))}
+	repo := s.users(r)
+	forEachIndex(len(users), s.bulkWorkers, func(i int) {
+		resp.Results[i] = createBulkUser(repo, i, users[i])
+	})
+
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			resp.Failed++
+		} else {
+			resp.Created++
+		}
+	}
+
+	respondJSON(w, r, http.StatusOK, resp)
+}
+
+// createBulkUser validates and creates the user at index i of a bulk request
+func createBulkUser(repo database.UserRepository, i int, user *database.User) definitions.BulkUserResult {
+	result := definitions.BulkUserResult{Index: i}
+	if user == nil {
+		result.Error = "user is required"
+		return result
+	}
+
+	user.ID = 0
+	user.Normalize()
+	if err := user.Validate(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := repo.CreateUser(user); err != nil {
+		switch {
+		case errors.Is(err, database.ErrEmailTaken):
+			result.Error = "Email already in use"
+		case errors.Is(err, database.ErrCapacityExceeded):
+			result.Error = "User capacity exceeded"
+		case errors.Is(err, database.ErrCircuitOpen):
+			result.Error = "User repository unavailable"
+		default:
+			result.Error = "Error creating user"
+		}
+		return result
+	}
+
+	result.User = &definitions.UserResponse{ID: user.ID, Username: user.Username, Email: user.Email}
+	return result
+}
+
+// updateUsersBulk godoc
+// @Summary Update users in bulk
+// @Description Replace up to 1000 users, each identified by the id in its body.
+// @Description The batch is applied as one repository operation; results are
+// @Description returned in input order, each with the updated user or the reason it failed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param users body []database.User true "Users to update"
+// @Success 200 {object} definitions.BulkUpdateResponse
+// @Failure 400 {object} map[string]string
+// @Router /users/bulk-update [post]
+func (s *Server) updateUsersBulk(w http.ResponseWriter, r *http.Request) {
+	var users []*database.User
+	if err := decodeJSON(w, r, &users); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+	if len(users) > maxBulkUsers {
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("at most %d users per request", maxBulkUsers))
+		return
+	}
+
+	resp := definitions.BulkUpdateResponse{Results: make([]definitions.BulkUserResult, len(users))}
+
+	// Only users that pass validation reach the repository; valid remembers
+	// the input index of each
+	var valid []int
+	for i, user := range users {
+		resp.Results[i].Index = i
+		if err := validateBulkUpdate(user); err != nil {
+			resp.Results[i].Error = err.Error()
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	batch := make([]*database.User, len(valid))
+	for j, i := range valid {
+		batch[j] = users[i]
+	}
+	for j, err := range updateUsers(s.users(r), batch) {
+		result := &resp.Results[valid[j]]
+		switch {
+		case err == nil:
+			user := batch[j]
+			result.User = &definitions.UserResponse{ID: user.ID, Username: user.Username, Email: user.Email}
+		case errors.Is(err, database.ErrUserNotFound):
+			result.Error = "User not found"
+		case errors.Is(err, database.ErrEmailTaken):
+			result.Error = "Email already in use"
+		case errors.Is(err, database.ErrCircuitOpen):
+			result.Error = "User repository unavailable"
+		default:
+			result.Error = "Error updating user"
+		}
+	}
+
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			resp.Failed++
+		} else {
+			resp.Updated++
+		}
+	}
+
+	respondJSON(w, r, http.StatusOK, resp)
+}
+
+// validateBulkUpdate normalizes and checks one user of a bulk update
+func validateBulkUpdate(user *database.User) error {
+	if user == nil {
+		return errors.New("user is required")
+	}
+	if user.ID <= 0 {
+		return errors.New("id must be a positive integer")
+	}
+	user.Normalize()
+	return user.Validate()
+}
+
+// updateUsers updates users in one call when repo supports it, and one at a
+// time otherwise
+func updateUsers(repo database.UserRepository, users []*database.User) []error {
+	if bulk, ok := repo.(database.BulkUpdater); ok {
+		return bulk.UpdateUsers(users)
+	}
+
+	errs := make([]error, len(users))
+	for i, user := range users {
+		errs[i] = repo.UpdateUser(user)
+	}
+	return errs
+}
+
+// forEachIndex calls fn for every index in [0, n) using at most workers
+// goroutines, and returns once all calls have finished. Callers collect
+// results by index, so they come back in input order whatever the scheduling.
+func forEachIndex(n, workers int, fn func(i int)) {
+	workers = max(1, min(workers, n))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
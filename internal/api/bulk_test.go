@@ -0,0 +1,185 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateUsersBulk tests that a large batch keeps input order and error positions
+func TestCreateUsersBulk(t *testing.T) {
+	repo := database.NewUserRepository()
+	server := NewServer(repo, calculator.NewCalculator(), WithBulkWorkers(8))
+
+	const batch = 500
+	users := make([]database.User, batch)
+	for i := range users {
+		users[i] = database.User{Username: fmt.Sprintf("bulk%d", i), Email: fmt.Sprintf("bulk%d@example.com", i)}
+		if i%7 == 3 {
+			users[i].Email = "invalid"
+		}
+	}
+	body, err := json.Marshal(users)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/users/bulk", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp definitions.BulkUsersResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Results, batch)
+
+	failed := 0
+	for i, result := range resp.Results {
+		assert.Equal(t, i, result.Index)
+		if i%7 == 3 {
+			failed++
+			assert.Nil(t, result.User, "index %d", i)
+			assert.Equal(t, "invalid user: email is not a valid address", result.Error, "index %d", i)
+			continue
+		}
+
+		assert.Empty(t, result.Error, "index %d", i)
+		require.NotNil(t, result.User, "index %d", i)
+		assert.Equal(t, users[i].Username, result.User.Username)
+		assert.NotZero(t, result.User.ID)
+	}
+	assert.Equal(t, failed, resp.Failed)
+	assert.Equal(t, batch-failed, resp.Created)
+
+	stored, err := repo.ListUsers()
+	require.NoError(t, err)
+	assert.Len(t, stored, batch-failed)
+}
+
+// TestCreateUsersBulkErrors tests rejecting malformed and oversized bulk requests
+func TestCreateUsersBulkErrors(t *testing.T) {
+	server := NewServer(database.NewUserRepository(), calculator.NewCalculator())
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedBody string
+	}{
+		{"Not an array", `{"username":"user1"}`, `{"error":"request body must be an array"}`},
+		{"Too many users", "[" + strings.Repeat(`{},`, maxBulkUsers) + "{}]", `{"error":"at most 1000 users per request"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users/bulk", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestUpdateUsersBulk tests bulk updates with and without unknown IDs
+func TestUpdateUsersBulk(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedErrors []string
+		expectedNames  []string
+	}{
+		{
+			name:           "All present",
+			body:           `[{"id":2,"username":"robert","email":"Robert@Example.com"},{"id":1,"username":"alicia","email":"alicia@example.com"}]`,
+			expectedErrors: []string{"", ""},
+			expectedNames:  []string{"alicia", "robert"},
+		},
+		{
+			name:           "Missing ID",
+			body:           `[{"id":1,"username":"alicia","email":"alicia@example.com"},{"id":99,"username":"ghost","email":"ghost@example.com"},{"username":"noid","email":"noid@example.com"}]`,
+			expectedErrors: []string{"", "User not found", "id must be a positive integer"},
+			expectedNames:  []string{"alicia", "bob"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := database.NewUserRepository(
+				&database.User{Username: "alice", Email: "alice@example.com"},
+				&database.User{Username: "bob", Email: "bob@example.com"},
+			)
+			server := NewServer(repo, calculator.NewCalculator())
+
+			req := httptest.NewRequest("POST", "/users/bulk-update", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			var resp definitions.BulkUpdateResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.Len(t, resp.Results, len(tc.expectedErrors))
+
+			failed := 0
+			for i, result := range resp.Results {
+				assert.Equal(t, i, result.Index)
+				assert.Equal(t, tc.expectedErrors[i], result.Error, "index %d", i)
+				if tc.expectedErrors[i] != "" {
+					failed++
+					assert.Nil(t, result.User, "index %d", i)
+				} else {
+					assert.NotNil(t, result.User, "index %d", i)
+				}
+			}
+			assert.Equal(t, failed, resp.Failed)
+			assert.Equal(t, len(tc.expectedErrors)-failed, resp.Updated)
+
+			stored, err := repo.ListUsers()
+			require.NoError(t, err)
+			names := make([]string, len(stored))
+			for i, user := range stored {
+				names[i] = user.Username
+			}
+			assert.Equal(t, tc.expectedNames, names)
+		})
+	}
+}
+
+// TestForEachIndex tests that the worker pool visits every index once within its bound
+func TestForEachIndex(t *testing.T) {
+	for _, workers := range []int{0, 1, 3, 100} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			var active, peak atomic.Int32
+			visits := make([]atomic.Int32, 50)
+
+			forEachIndex(len(visits), workers, func(i int) {
+				n := active.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				visits[i].Add(1)
+				active.Add(-1)
+			})
+
+			for i := range visits {
+				assert.Equal(t, int32(1), visits[i].Load(), "index %d", i)
+			}
+			assert.LessOrEqual(t, peak.Load(), int32(max(1, workers)))
+		})
+	}
+}
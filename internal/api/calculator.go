@@ -0,0 +1,951 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"net/http"
+	"strconv"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/calculator"
+	pkgcalculator "go-testing/pkg/calculator"
+)
+
+// maxBatchExpressions bounds the size of a single batch-eval request
+const maxBatchExpressions = 1000
+
+// almostEqual godoc
+// @Summary Compare two numbers within a tolerance
+// @Description Report whether a and b differ by no more than epsilon (default 1e-9)
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param a query number true "First number"
+// @Param b query number true "Second number"
+// @Param epsilon query number false "Tolerance"
+// @Success 200 {object} map[string]bool
+// @Failure 400 {object} map[string]string
+// @Router /calculator/almost-equal [get]
+func (s *Server) almostEqual(w http.ResponseWriter, r *http.Request) {
+	first, second := s.operandNames[0], s.operandNames[1]
+	p, err := s.parseParams(r,
+		paramSpec{Name: first, Type: numberParam, Required: true},
+		paramSpec{Name: second, Type: numberParam, Required: true},
+		paramSpec{Name: "epsilon", Type: numberParam},
+	)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.calculator.RecordOperation("almost-equal")
+	epsilon := pkgcalculator.DefaultEpsilon
+	if p.Has("epsilon") {
+		epsilon = p.Float("epsilon")
+	}
+	if epsilon < 0 {
+		respondError(w, r, http.StatusBadRequest, "epsilon must not be negative")
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string]bool{"result": s.pubCalc.AlmostEqual(p.Float(first), p.Float(second), epsilon)})
+}
+
+// createSession godoc
+// @Summary Create a calculator session
+// @Description Start a stateful calculation with a running value of zero
+// @Tags calculator
+// @Produce json
+// @Success 201 {object} calculator.SessionState
+// @Failure 503 {object} map[string]string
+// @Router /calculator/sessions [post]
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	state, err := s.sessions.Create(r.Context())
+	if err != nil {
+		respondSessionError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, http.StatusCreated, state)
+}
+
+// getSession godoc
+// @Summary Get a calculator session
+// @Description Get the running value and history of a calculator session
+// @Tags calculator
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} calculator.SessionState
+// @Failure 404 {object} map[string]string
+// @Router /calculator/sessions/{id} [get]
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	state, err := s.sessions.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondSessionError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, state)
+}
+
+// applySession godoc
+// @Summary Apply an operation to a calculator session
+// @Description Apply add, subtract, multiply or divide with operand b to the session's running value.
+// @Description With no-record=true the result is returned without updating the running value or history.
+// @Tags calculator
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param op path string true "Operation" Enums(add, subtract, multiply, divide)
+// @Param b query number true "Operand"
+// @Param no-record query bool false "Preview the result without recording it"
+// @Success 200 {object} calculator.SessionState
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /calculator/sessions/{id}/{op} [post]
+func (s *Server) applySession(w http.ResponseWriter, r *http.Request) {
+	p, err := s.parseParams(r,
+		paramSpec{Name: "b", Type: numberParam, Required: true},
+		paramSpec{Name: "no-record", Type: boolParam},
+	)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	apply := s.sessions.Apply
+	if p.Bool("no-record") {
+		apply = s.sessions.Preview
+	}
+	state, err := apply(r.Context(), r.PathValue("id"), r.PathValue("op"), p.Float("b"))
+	if err != nil {
+		respondSessionError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, state)
+}
+
+// respondSessionError maps session store errors to HTTP responses
+func respondSessionError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, calculator.ErrSessionNotFound):
+		respondError(w, r, http.StatusNotFound, "Session not found")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		respondError(w, r, http.StatusServiceUnavailable, "Request cancelled")
+	case errors.Is(err, calculator.ErrUnknownOperation):
+		respondError(w, r, http.StatusBadRequest, err.Error())
+	default:
+		respondCalculationError(w, r, err)
+	}
+}
+
+// errNonFiniteResult is reported for results that overflow to an infinity or
+// are NaN, which JSON cannot represent
+var errNonFiniteResult = errors.New("result is not a finite number")
+
+// respondCalculationError maps calculator errors to HTTP responses. Errors
+// caused by the operands are the client's fault; anything else is ours.
+func respondCalculationError(w http.ResponseWriter, r *http.Request, err error) {
+	status, msg := calculationError(err)
+	respondError(w, r, status, msg)
+}
+
+// calculationError returns the status and message respondCalculationError
+// responds to err with
+func calculationError(err error) (int, string) {
+	switch {
+	case errors.Is(err, pkgcalculator.ErrDivideByZero):
+		return http.StatusBadRequest, "Division by zero"
+	case errors.Is(err, pkgcalculator.ErrNegativeInput),
+		errors.Is(err, pkgcalculator.ErrOverflow),
+		errors.Is(err, pkgcalculator.ErrLengthMismatch),
+		errors.Is(err, pkgcalculator.ErrTooFewValues),
+		errors.Is(err, pkgcalculator.ErrInvalidExpression),
+		errors.Is(err, pkgcalculator.ErrUndefinedVariable),
+		errors.Is(err, pkgcalculator.ErrUnknownRoundingMode),
+		errors.Is(err, pkgcalculator.ErrPlacesOutOfRange),
+		errors.Is(err, pkgcalculator.ErrInvalidMoney),
+		errors.Is(err, pkgcalculator.ErrInvalidMatrix),
+		errors.Is(err, pkgcalculator.ErrDimensionMismatch),
+		errors.Is(err, pkgcalculator.ErrInvalidWindow),
+		errors.Is(err, pkgcalculator.ErrNonPositiveLog),
+		errors.Is(err, pkgcalculator.ErrInvalidBase),
+		errors.Is(err, pkgcalculator.ErrUnknownOperation),
+		errors.Is(err, pkgcalculator.ErrInvalidMultiple),
+		errors.Is(err, pkgcalculator.ErrPercentileOutOfRange):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, errNonFiniteResult):
+		return http.StatusUnprocessableEntity, err.Error()
+	default:
+		return http.StatusInternalServerError, "Calculation failed"
+	}
+}
+
+// divideFraction divides with rational arithmetic and responds with the exact
+// result as a string like "1/3", or "2" when it simplifies to an integer
+func (s *Server) divideFraction(w http.ResponseWriter, r *http.Request) {
+	first, second := s.operandNames[0], s.operandNames[1]
+	if err := s.checkQueryParams(r, first, second, "as"); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	values := s.operandValues(r, first, second)
+
+	a, ok := new(big.Rat).SetString(values.Get(first))
+	if !ok {
+		respondError(w, r, http.StatusBadRequest, first+" must be a number or fraction")
+		return
+	}
+	b, ok := new(big.Rat).SetString(values.Get(second))
+	if !ok {
+		respondError(w, r, http.StatusBadRequest, second+" must be a number or fraction")
+		return
+	}
+
+	s.calculator.RecordOperation("divide")
+	result, err := s.pubCalc.DivideRat(a, b)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string]string{"result": result.RatString()})
+}
+
+// fibonacci godoc
+// @Summary Compute a Fibonacci number
+// @Description Return the nth Fibonacci number, for 0 <= n <= 93
+// @Tags calculator
+// @Produce json
+// @Param n query int true "Index in the Fibonacci sequence"
+// @Success 200 {object} map[string]uint64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/fibonacci [get]
+func (s *Server) fibonacci(w http.ResponseWriter, r *http.Request) {
+	p, err := s.parseParams(r, paramSpec{Name: "n", Type: intParam, Required: true})
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.calculator.RecordOperation("fibonacci")
+	result, err := s.pubCalc.Fibonacci(p.Int("n"))
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string]uint64{"result": result})
+}
+
+// minOf godoc
+// @Summary Return the smaller of two numbers
+// @Description Return the minimum of a and b. NaN operands are rejected.
+// @Tags calculator
+// @Produce json
+// @Param a query number true "First number"
+// @Param b query number true "Second number"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/min [get]
+func (s *Server) minOf(w http.ResponseWriter, r *http.Request) {
+	a, b, err := s.getOperands(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.calculator.RecordOperation("min")
+	s.respondResult(w, r, "min", s.pubCalc.MinOf(a, b))
+}
+
+// maxOf godoc
+// @Summary Return the larger of two numbers
+// @Description Return the maximum of a and b. NaN operands are rejected.
+// @Tags calculator
+// @Produce json
+// @Param a query number true "First number"
+// @Param b query number true "Second number"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/max [get]
+func (s *Server) maxOf(w http.ResponseWriter, r *http.Request) {
+	a, b, err := s.getOperands(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.calculator.RecordOperation("max")
+	s.respondResult(w, r, "max", s.pubCalc.MaxOf(a, b))
+}
+
+// abs godoc
+// @Summary Return the absolute value of a number
+// @Description Return the absolute value of a. NaN operands are rejected.
+// @Tags calculator
+// @Produce json
+// @Param a query number true "Number"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/abs [get]
+func (s *Server) abs(w http.ResponseWriter, r *http.Request) {
+	a, err := s.getOperand(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.calculator.RecordOperation("abs")
+	s.respondResult(w, r, "abs", s.pubCalc.Abs(a))
+}
+
+// sign godoc
+// @Summary Return the sign of a number
+// @Description Return -1, 0 or 1 as a is negative, zero (including negative zero) or positive. NaN operands are rejected.
+// @Tags calculator
+// @Produce json
+// @Param a query number true "Number"
+// @Success 200 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Router /calculator/sign [get]
+func (s *Server) sign(w http.ResponseWriter, r *http.Request) {
+	a, err := s.getOperand(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.calculator.RecordOperation("sign")
+	respondJSON(w, r, http.StatusOK, map[string]int{"result": s.pubCalc.Sign(a)})
+}
+
+// hypot godoc
+// @Summary Return the hypotenuse of a right triangle
+// @Description Return sqrt(a*a + b*b) without overflowing in the intermediate squares,
+// @Description so legs as large as 1e300 work. NaN operands are rejected.
+// @Tags calculator
+// @Produce json
+// @Param a query number true "First leg"
+// @Param b query number true "Second leg"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /calculator/hypot [get]
+func (s *Server) hypot(w http.ResponseWriter, r *http.Request) {
+	a, b, err := s.getOperands(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.calculator.RecordOperation("hypot")
+	s.respondResult(w, r, "hypot", s.pubCalc.Hypotenuse(a, b))
+}
+
+// logarithm godoc
+// @Summary Return a logarithm
+// @Description Return the logarithm of a in base (default e, the natural logarithm).
+// @Description a must be positive and base positive and not 1.
+// @Tags calculator
+// @Produce json
+// @Param a query number true "Positive number"
+// @Param base query string false "Base, a positive number other than 1, or e"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/log [get]
+func (s *Server) logarithm(w http.ResponseWriter, r *http.Request) {
+	first := s.operandNames[0]
+	p, err := s.parseParams(r,
+		paramSpec{Name: first, Type: numberParam, Required: true},
+		paramSpec{Name: "base", Type: stringParam},
+	)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	base := math.E
+	if raw := p.String("base"); raw != "" && raw != "e" {
+		base, err = strconv.ParseFloat(raw, 64)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, "base must be a number or e")
+			return
+		}
+	}
+
+	s.calculator.RecordOperation("log")
+	result, err := s.pubCalc.Log(p.Float(first), base)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	s.respondResult(w, r, "log", result)
+}
+
+// operationProperties godoc
+// @Summary Describe an operation's algebraic properties
+// @Description Return whether a binary operation is commutative and associative,
+// @Description and its identity element if it has one
+// @Tags calculator
+// @Produce json
+// @Param op query string true "Operation" Enums(add, subtract, multiply, divide)
+// @Success 200 {object} definitions.OperationPropertiesResponse
+// @Failure 400 {object} map[string]string
+// @Router /calculator/properties [get]
+func (s *Server) operationProperties(w http.ResponseWriter, r *http.Request) {
+	p, err := s.parseParams(r, paramSpec{Name: "op", Type: stringParam, Required: true})
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	op := p.String("op")
+	props, err := s.pubCalc.Properties(op)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	resp := definitions.OperationPropertiesResponse{
+		Operation:   op,
+		Commutative: props.Commutative,
+		Associative: props.Associative,
+		HasIdentity: props.HasIdentity,
+	}
+	if props.HasIdentity {
+		resp.Identity = &props.Identity
+	}
+	respondJSON(w, r, http.StatusOK, resp)
+}
+
+// roundToMultiple godoc
+// @Summary Round a number to the nearest multiple
+// @Description Round a to the nearest multiple of multiple, with ties away from zero,
+// @Description such as a price to a pricing tier. multiple must be positive.
+// @Tags calculator
+// @Produce json
+// @Param a query number true "Number"
+// @Param multiple query number true "Positive multiple to round to"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/round-to-multiple [get]
+func (s *Server) roundToMultiple(w http.ResponseWriter, r *http.Request) {
+	first := s.operandNames[0]
+	p, err := s.parseParams(r,
+		paramSpec{Name: first, Type: numberParam, Required: true},
+		paramSpec{Name: "multiple", Type: numberParam, Required: true},
+	)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.calculator.RecordOperation("round-to-multiple")
+	result, err := s.pubCalc.RoundToMultiple(p.Float(first), p.Float("multiple"))
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	s.respondResult(w, r, "round-to-multiple", result)
+}
+
+// round godoc
+// @Summary Round a number
+// @Description Round a to places decimal places (default 0; negative rounds to tens, hundreds...)
+// @Description using mode: half-up (default, ties away from zero), half-even (banker's), floor, ceil or trunc
+// @Tags calculator
+// @Produce json
+// @Param a query number true "Number"
+// @Param places query int false "Decimal places, between -15 and 15"
+// @Param mode query string false "Rounding mode" Enums(half-up, half-even, floor, ceil, trunc)
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/round [get]
+func (s *Server) round(w http.ResponseWriter, r *http.Request) {
+	first := s.operandNames[0]
+	p, err := s.parseParams(r,
+		paramSpec{Name: first, Type: numberParam, Required: true},
+		paramSpec{Name: "places", Type: intParam},
+		paramSpec{Name: "mode", Type: stringParam},
+	)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mode := pkgcalculator.RoundHalfUp
+	if p.Has("mode") {
+		mode = p.String("mode")
+	}
+
+	s.calculator.RecordOperation("round")
+	result, err := s.pubCalc.RoundMode(p.Float(first), p.Int("places"), mode)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	s.respondResult(w, r, "round", result)
+}
+
+// dotProduct godoc
+// @Summary Compute a dot product
+// @Description Return the dot product of two equal-length vectors; two empty vectors give 0
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param vectors body definitions.DotProductRequest true "Vectors a and b"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/dot [post]
+func (s *Server) dotProduct(w http.ResponseWriter, r *http.Request) {
+	var req definitions.DotProductRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	s.calculator.RecordOperation("dot")
+	result, err := s.pubCalc.DotProduct(req.A, req.B)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	s.respondResult(w, r, "dot", result)
+}
+
+// complexArithmetic godoc
+// @Summary Operate on complex numbers
+// @Description Add, subtract, multiply or divide complex numbers a and b, each given as
+// @Description {"re":..,"im":..}. Dividing by 0+0i is an error.
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param op path string true "Operation" Enums(add, subtract, multiply, divide)
+// @Param operands body definitions.ComplexRequest true "Complex numbers a and b"
+// @Success 200 {object} definitions.ComplexResponse
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /calculator/complex/{op} [post]
+func (s *Server) complexArithmetic(w http.ResponseWriter, r *http.Request) {
+	var req definitions.ComplexRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+	a := complex(req.A.Re, req.A.Im)
+	b := complex(req.B.Re, req.B.Im)
+
+	op := r.PathValue("op")
+	var result complex128
+	var err error
+	switch op {
+	case "add":
+		result = s.pubCalc.AddComplex(a, b)
+	case "subtract":
+		result = s.pubCalc.SubtractComplex(a, b)
+	case "multiply":
+		result = s.pubCalc.MultiplyComplex(a, b)
+	case "divide":
+		result, err = s.pubCalc.DivideComplex(a, b)
+	default:
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("unknown operation %q", op))
+		return
+	}
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+	if cmplx.IsInf(result) || cmplx.IsNaN(result) {
+		respondCalculationError(w, r, errNonFiniteResult)
+		return
+	}
+
+	s.calculator.RecordOperation("complex-" + op)
+	respondJSON(w, r, http.StatusOK, definitions.ComplexResponse{
+		Result: definitions.ComplexNumber{Re: real(result), Im: imag(result)},
+	})
+}
+
+// movingAverage godoc
+// @Summary Compute a moving average
+// @Description Return the trailing moving average of values over window values. Element i of
+// @Description the result averages values[i] to values[i+window-1], so it has len(values)-window+1
+// @Description elements. window must be between 1 and the number of values.
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param series body definitions.MovingAverageRequest true "Values and window"
+// @Success 200 {object} map[string][]float64
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /calculator/moving-average [post]
+func (s *Server) movingAverage(w http.ResponseWriter, r *http.Request) {
+	var req definitions.MovingAverageRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	s.calculator.RecordOperation("moving-average")
+	result, err := s.pubCalc.MovingAverage(req.Values, req.Window)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+	averages := make([]*float64, len(result))
+	for i := range result {
+		averages[i] = &result[i]
+	}
+	if !s.roundResults(w, r, "moving-average", averages...) {
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string][]float64{"result": result})
+}
+
+// percentile godoc
+// @Summary Compute a percentile
+// @Description Return the p-th percentile of values, interpolating linearly between the two
+// @Description nearest ranks, so p=50 is the median. p must be between 0 and 100.
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param series body definitions.PercentileRequest true "Values and percentile"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/percentile [post]
+func (s *Server) percentile(w http.ResponseWriter, r *http.Request) {
+	var req definitions.PercentileRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+	if req.P == nil {
+		respondError(w, r, http.StatusBadRequest, "p is required")
+		return
+	}
+
+	s.calculator.RecordOperation("percentile")
+	result, err := s.pubCalc.Percentile(req.Values, *req.P)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	s.respondResult(w, r, "percentile", result)
+}
+
+// mapOp godoc
+// @Summary Apply an operation to every value
+// @Description Apply op (add, subtract, multiply or divide) to each of values with operand as the
+// @Description second operand, such as multiplying every element by 2. Dividing by a zero operand is
+// @Description rejected before any element is processed.
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param mapping body definitions.MapRequest true "Values, operation and operand"
+// @Success 200 {object} map[string][]float64
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /calculator/map [post]
+func (s *Server) mapOp(w http.ResponseWriter, r *http.Request) {
+	var req definitions.MapRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	result, err := s.pubCalc.MapOp(req.Values, req.Op, req.Operand)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+	for _, v := range result {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			respondCalculationError(w, r, errNonFiniteResult)
+			return
+		}
+	}
+
+	s.calculator.RecordOperation("map")
+	respondJSON(w, r, http.StatusOK, map[string][]float64{"result": result})
+}
+
+// median godoc
+// @Summary Compute the median
+// @Description Return the middle value of values, or the mean of the two middle values when
+// @Description there is an even number of them
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param dataset body definitions.ValuesRequest true "Values"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/median [post]
+func (s *Server) median(w http.ResponseWriter, r *http.Request) {
+	var req definitions.ValuesRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	s.calculator.RecordOperation("median")
+	result, err := s.pubCalc.Median(req.Values)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	s.respondResult(w, r, "median", result)
+}
+
+// mode godoc
+// @Summary Compute the modes
+// @Description Return every value occurring most often in values, in ascending order
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param dataset body definitions.ValuesRequest true "Values"
+// @Success 200 {object} map[string][]float64
+// @Failure 400 {object} map[string]string
+// @Router /calculator/mode [post]
+func (s *Server) mode(w http.ResponseWriter, r *http.Request) {
+	var req definitions.ValuesRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	s.calculator.RecordOperation("mode")
+	result, err := s.pubCalc.Mode(req.Values)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string][]float64{"result": result})
+}
+
+// maxMatrixSize bounds the rows and columns of matrices posted to the API
+const maxMatrixSize = 100
+
+// matrixMultiply godoc
+// @Summary Multiply two matrices
+// @Description Return the product of a (m×n) and b (n×p), given as arrays of rows.
+// @Description Matrices must be rectangular, non-empty and at most 100×100.
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param matrices body definitions.MatrixMultiplyRequest true "Matrices a and b"
+// @Success 200 {object} definitions.MatrixResponse
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /calculator/matrix-multiply [post]
+func (s *Server) matrixMultiply(w http.ResponseWriter, r *http.Request) {
+	var req definitions.MatrixMultiplyRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+	for _, m := range [][][]float64{req.A, req.B} {
+		if len(m) > maxMatrixSize || len(m) > 0 && len(m[0]) > maxMatrixSize {
+			respondError(w, r, http.StatusBadRequest, fmt.Sprintf("matrices are limited to %dx%d", maxMatrixSize, maxMatrixSize))
+			return
+		}
+	}
+
+	s.calculator.RecordOperation("matrix-multiply")
+	result, err := s.pubCalc.MatrixMultiply(req.A, req.B)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+	for _, row := range result {
+		for _, v := range row {
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				respondCalculationError(w, r, errNonFiniteResult)
+				return
+			}
+		}
+	}
+
+	respondJSON(w, r, http.StatusOK, definitions.MatrixResponse{Result: result})
+}
+
+// variance godoc
+// @Summary Compute variance and standard deviation
+// @Description Return the population variance and standard deviation of values, or the sample
+// @Description variants when sample is true. Population needs one value and sample needs two.
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param dataset body definitions.VarianceRequest true "Values and variant"
+// @Success 200 {object} definitions.VarianceResponse
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /calculator/variance [post]
+func (s *Server) variance(w http.ResponseWriter, r *http.Request) {
+	var req definitions.VarianceRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	s.calculator.RecordOperation("variance")
+	variance, err := s.pubCalc.Variance(req.Values, req.Sample)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+	stdDev, err := s.pubCalc.StdDev(req.Values, req.Sample)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+	if !s.roundResults(w, r, "variance", &variance, &stdDev) {
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, definitions.VarianceResponse{Variance: variance, StdDev: stdDev})
+}
+
+// evaluate godoc
+// @Summary Evaluate an arithmetic expression
+// @Description Evaluate an expression of numbers, variables, + - * / and parentheses,
+// @Description such as x * y + 2 with vars {"x":3,"y":4}. Using a variable missing from vars is an error naming it.
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param expression body definitions.EvaluateRequest true "Expression and variables"
+// @Success 200 {object} map[string]float64
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /calculator/evaluate [post]
+func (s *Server) evaluate(w http.ResponseWriter, r *http.Request) {
+	var req definitions.EvaluateRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	s.calculator.RecordOperation("evaluate")
+	result, err := s.pubCalc.EvaluateWithVars(req.Expr, req.Vars)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	s.respondResult(w, r, "evaluate", result)
+}
+
+// batchEvaluate godoc
+// @Summary Evaluate many arithmetic expressions
+// @Description Evaluate up to 1000 expressions, such as ["1 + 2", "(3 - 1) * 4"], without variables.
+// @Description Results come back in input order; an expression that fails gets its own error
+// @Description without failing the rest.
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param expressions body []string true "Expressions to evaluate"
+// @Success 200 {object} definitions.BatchEvalResponse
+// @Failure 400 {object} map[string]string
+// @Router /calculator/batch-eval [post]
+func (s *Server) batchEvaluate(w http.ResponseWriter, r *http.Request) {
+	var exprs []string
+	if err := decodeJSON(w, r, &exprs); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+	if len(exprs) > maxBatchExpressions {
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("at most %d expressions per request", maxBatchExpressions))
+		return
+	}
+
+	s.calculator.RecordOperation("batch-eval")
+	resp := definitions.BatchEvalResponse{Results: make([]definitions.BatchEvalResult, len(exprs))}
+	for i, expr := range exprs {
+		resp.Results[i] = definitions.BatchEvalResult{Index: i, Expr: expr}
+		result, err := s.pubCalc.Evaluate(expr)
+		if err == nil && (math.IsInf(result, 0) || math.IsNaN(result)) {
+			err = errNonFiniteResult
+		}
+		if err != nil {
+			_, resp.Results[i].Error = calculationError(err)
+			resp.Failed++
+			continue
+		}
+		resp.Results[i].Result = &result
+		resp.Succeeded++
+	}
+
+	respondJSON(w, r, http.StatusOK, resp)
+}
+
+// money godoc
+// @Summary Add or subtract dollar amounts
+// @Description Parse two amounts such as $1,234.56, apply add or subtract, and
+// @Description return the result formatted the same way, rounded to cents
+// @Tags calculator
+// @Accept json
+// @Produce json
+// @Param amounts body definitions.MoneyRequest true "Amounts and operation"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /calculator/money [post]
+func (s *Server) money(w http.ResponseWriter, r *http.Request) {
+	var req definitions.MoneyRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	// Amounts are added as whole cents, so results are exact
+	a, err := pkgcalculator.ParseCents(req.A)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+	b, err := pkgcalculator.ParseCents(req.B)
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+
+	var result int64
+	switch req.Op {
+	case "add":
+		result, err = pkgcalculator.AddCents(a, b)
+	case "subtract":
+		result, err = pkgcalculator.SubtractCents(a, b)
+	default:
+		respondError(w, r, http.StatusBadRequest, "op must be add or subtract")
+		return
+	}
+	if err != nil {
+		respondCalculationError(w, r, err)
+		return
+	}
+	s.calculator.RecordOperation("money")
+
+	respondJSON(w, r, http.StatusOK, map[string]string{"result": pkgcalculator.FormatCents(result)})
+}
+
+// usageStats godoc
+// @Summary Report calculator usage
+// @Description Return how often each calculator operation has been invoked since startup
+// @Tags calculator
+// @Produce json
+// @Success 200 {object} map[string]int64
+// @Router /calculator/stats/usage [get]
+func (s *Server) usageStats(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, http.StatusOK, s.calculator.OperationCounts())
+}
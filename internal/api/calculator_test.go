@@ -0,0 +1,827 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAlmostEqualEndpoint tests the almost-equal endpoint
+func TestAlmostEqualEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedResult bool
+	}{
+		{"Within default epsilon", "/calculator/almost-equal?a=0.30000000000000004&b=0.3", http.StatusOK, true},
+		{"Outside default epsilon", "/calculator/almost-equal?a=1&b=1.001", http.StatusOK, false},
+		{"Within custom epsilon", "/calculator/almost-equal?a=1&b=1.001&epsilon=0.01", http.StatusOK, true},
+		{"Negative epsilon", "/calculator/almost-equal?a=1&b=1&epsilon=-1", http.StatusBadRequest, false},
+		{"Missing operand", "/calculator/almost-equal?a=1", http.StatusBadRequest, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				var response map[string]bool
+				err := json.NewDecoder(rec.Body).Decode(&response)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, response["result"])
+			}
+		})
+	}
+}
+
+// TestCalculatorSessions tests the session endpoints and shutdown of the eviction goroutine
+func TestCalculatorSessions(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithCalculatorSessions(time.Minute))
+	handler := server.Router()
+
+	// Create a session
+	req := httptest.NewRequest("POST", "/calculator/sessions", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var state calculator.SessionState
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	require.NotEmpty(t, state.ID)
+
+	// Apply operations to the running value
+	for _, url := range []string{
+		"/calculator/sessions/" + state.ID + "/add?b=6",
+		"/calculator/sessions/" + state.ID + "/multiply?b=7",
+	} {
+		req = httptest.NewRequest("POST", url, nil)
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/calculator/sessions/"+state.ID, nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	assert.Equal(t, 42.0, state.Value)
+	assert.Len(t, state.History, 2)
+
+	// Errors
+	req = httptest.NewRequest("GET", "/calculator/sessions/unknown", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	req = httptest.NewRequest("POST", "/calculator/sessions/"+state.ID+"/divide?b=0", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Cancelled request contexts are not applied
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req = httptest.NewRequest("POST", "/calculator/sessions/"+state.ID+"/add?b=1", nil).WithContext(ctx)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// Shutdown returns only once the eviction goroutine has exited
+	done := make(chan error)
+	go func() {
+		done <- server.Shutdown(context.Background())
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("session eviction goroutine did not exit on shutdown")
+	}
+}
+
+// TestCalculatorSessionsTTL tests that a non-positive TTL leaves sessions
+// disabled and a tiny one still starts the eviction goroutine
+func TestCalculatorSessionsTTL(t *testing.T) {
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithCalculatorSessions(ttl))
+		rec := httptest.NewRecorder()
+		server.Router().ServeHTTP(rec, httptest.NewRequest("POST", "/calculator/sessions", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, ttl)
+	}
+
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithCalculatorSessions(time.Nanosecond))
+	defer server.Shutdown(context.Background())
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, httptest.NewRequest("POST", "/calculator/sessions", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+// TestCalculatorSessionPreview tests that a no-record operation returns its
+// result without appearing in the session's history
+func TestCalculatorSessionPreview(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithCalculatorSessions(time.Minute))
+	defer server.Shutdown(context.Background())
+	handler := server.Router()
+
+	send := func(method, url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, url, nil))
+		return rec
+	}
+
+	var state calculator.SessionState
+	rec := send("POST", "/calculator/sessions")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	sessionURL := "/calculator/sessions/" + state.ID
+	require.Equal(t, http.StatusOK, send("POST", sessionURL+"/add?b=6").Code)
+
+	rec = send("POST", sessionURL+"/add?b=4&no-record=true")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	assert.Equal(t, 10.0, state.Value)
+	assert.Len(t, state.History, 1)
+
+	rec = send("GET", sessionURL)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	assert.Equal(t, 6.0, state.Value, "a no-record operation must not update the running value")
+	assert.Equal(t, []calculator.HistoryEntry{{Operation: "add", Operand: 6, Result: 6}}, state.History)
+
+	// no-record=false records as usual
+	require.Equal(t, http.StatusOK, send("POST", sessionURL+"/add?b=1&no-record=false").Code)
+	rec = send("GET", sessionURL)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	assert.Equal(t, 7.0, state.Value)
+
+	// Boolean forms other than true and false are accepted too
+	require.Equal(t, http.StatusOK, send("POST", sessionURL+"/add?b=1&no-record=yes").Code)
+	rec = send("GET", sessionURL)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	assert.Equal(t, 7.0, state.Value)
+
+	assert.Equal(t, http.StatusBadRequest, send("POST", sessionURL+"/add?b=1&no-record=maybe").Code)
+}
+
+// TestCalculatorSessionsDisabled tests that session endpoints are absent by default
+func TestCalculatorSessionsDisabled(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	req := httptest.NewRequest("POST", "/calculator/sessions", nil)
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestOperandSources tests reading operands from query, JSON and form bodies
+func TestOperandSources(t *testing.T) {
+	tests := []struct {
+		name           string
+		sources        []OperandSource
+		url            string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedResult float64
+	}{
+		{"Query", nil, "/calculator/add?a=1&b=2", "", "", http.StatusOK, 3},
+		{"Form post", nil, "/calculator/add", "application/x-www-form-urlencoded", "a=5&b=3", http.StatusOK, 8},
+		{"JSON body", nil, "/calculator/add", "application/json", `{"a":5,"b":4}`, http.StatusOK, 9},
+		{"JSON string operands", nil, "/calculator/add", "application/json", `{"a":"5","b":"5"}`, http.StatusOK, 10},
+		{"Query wins by default", nil, "/calculator/add?a=1&b=1", "application/json", `{"a":5,"b":4}`, http.StatusOK, 2},
+		{"Configured precedence", []OperandSource{OperandsFromJSON, OperandsFromQuery}, "/calculator/add?a=1&b=1", "application/json", `{"a":5,"b":4}`, http.StatusOK, 9},
+		{"Incomplete source falls through", nil, "/calculator/add?a=1", "application/json", `{"a":5,"b":4}`, http.StatusOK, 9},
+		{"Disabled source is ignored", []OperandSource{OperandsFromQuery}, "/calculator/add", "application/x-www-form-urlencoded", "a=5&b=3", http.StatusBadRequest, 0},
+		{"Body ignored without matching content type", nil, "/calculator/add", "text/plain", "a=5&b=3", http.StatusBadRequest, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []Option
+			if tc.sources != nil {
+				opts = append(opts, WithOperandSources(tc.sources...))
+			}
+			server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), opts...)
+
+			req := httptest.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				var response map[string]float64
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tc.expectedResult, response["result"])
+			}
+		})
+	}
+}
+
+// TestDivideAsFraction tests exact fraction results from the divide endpoint
+func TestDivideAsFraction(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedResult string
+	}{
+		{"One third", "/calculator/divide?a=1&b=3&as=fraction", http.StatusOK, "1/3"},
+		{"Simplifies to integer", "/calculator/divide?a=4&b=2&as=fraction", http.StatusOK, "2"},
+		{"Fraction operand", "/calculator/divide?a=1/2&b=3&as=fraction", http.StatusOK, "1/6"},
+		{"Zero divisor", "/calculator/divide?a=1&b=0&as=fraction", http.StatusBadRequest, ""},
+		{"Invalid operand", "/calculator/divide?a=one&b=3&as=fraction", http.StatusBadRequest, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				var response map[string]string
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tc.expectedResult, response["result"])
+			}
+		})
+	}
+}
+
+// TestFibonacciEndpoint tests the fibonacci endpoint
+func TestFibonacciEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedResult uint64
+	}{
+		{"F(0)", "/calculator/fibonacci?n=0", http.StatusOK, 0},
+		{"F(10)", "/calculator/fibonacci?n=10", http.StatusOK, 55},
+		{"F(93)", "/calculator/fibonacci?n=93", http.StatusOK, 12200160415121876738},
+		{"Overflow", "/calculator/fibonacci?n=94", http.StatusBadRequest, 0},
+		{"Negative", "/calculator/fibonacci?n=-1", http.StatusBadRequest, 0},
+		{"Not an integer", "/calculator/fibonacci?n=1.5", http.StatusBadRequest, 0},
+		{"Missing", "/calculator/fibonacci", http.StatusBadRequest, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				var response map[string]uint64
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tc.expectedResult, response["result"])
+			}
+		})
+	}
+}
+
+// TestUsageStats tests that calculator operations are counted by type
+func TestUsageStats(t *testing.T) {
+	server, _, calc := setupTestServer()
+	router := server.Router()
+
+	urls := []string{
+		"/calculator/add?a=1&b=2",
+		"/calculator/add?a=3&b=4",
+		"/calculator/add?a=5&b=6",
+		"/calculator/divide?a=6&b=3",
+		"/calculator/divide?a=1&b=0",
+		"/calculator/add?a=oops&b=1", // rejected before the operation runs
+	}
+	for _, url := range urls {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	}
+
+	expected := map[string]int64{"add": 3, "divide": 2}
+	assert.Equal(t, expected, calc.OperationCounts())
+
+	req := httptest.NewRequest("GET", "/calculator/stats/usage", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]int64
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, expected, response)
+}
+
+// TestDotProductEndpoint tests the dot product endpoint
+func TestDotProductEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Equal length", `{"a":[1,2,3],"b":[4,5,6]}`, http.StatusOK, `{"result":32}`},
+		{"Empty vectors", `{"a":[],"b":[]}`, http.StatusOK, `{"result":0}`},
+		{"Length mismatch", `{"a":[1,2],"b":[1]}`, http.StatusBadRequest, `{"error":"vector length mismatch: 2 and 1"}`},
+		{"Invalid body", `{"a":"oops"}`, http.StatusBadRequest, `{"error":"field a must be an array"}`},
+		{"Overflow", `{"a":[1e308],"b":[10]}`, http.StatusUnprocessableEntity, `{"error":"result is not a finite number"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/dot", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestVarianceEndpoint tests the variance endpoint
+func TestVarianceEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Population", `{"values":[2,4,4,4,5,5,7,9]}`, http.StatusOK, `{"variance":4,"stddev":2}`},
+		{"Sample", `{"values":[1,3],"sample":true}`, http.StatusOK, `{"variance":2,"stddev":1.4142135623730951}`},
+		{"Too few for sample", `{"values":[1],"sample":true}`, http.StatusBadRequest,
+			`{"error":"too few values: need at least 2, got 1"}`},
+		{"Empty", `{"values":[]}`, http.StatusBadRequest, `{"error":"too few values: need at least 1, got 0"}`},
+		{"Invalid body", `{"values":"oops"}`, http.StatusBadRequest, `{"error":"field values must be an array"}`},
+		{"Overflow", `{"values":[1e308,-1e308]}`, http.StatusUnprocessableEntity, `{"error":"result is not a finite number"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/variance", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestMovingAverageEndpoint tests the moving average endpoint
+func TestMovingAverageEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Trailing window", `{"values":[1,2,3,4,5],"window":3}`, http.StatusOK, `{"result":[2,3,4]}`},
+		{"Window of 1", `{"values":[1,2],"window":1}`, http.StatusOK, `{"result":[1,2]}`},
+		{"Window of len", `{"values":[1,2],"window":2}`, http.StatusOK, `{"result":[1.5]}`},
+		{"Missing window", `{"values":[1,2]}`, http.StatusBadRequest, `{"error":"invalid window: must be between 1 and 2, got 0"}`},
+		{"Window too long", `{"values":[1,2],"window":3}`, http.StatusBadRequest, `{"error":"invalid window: must be between 1 and 2, got 3"}`},
+		{"Overflow", `{"values":[1e308,1e308],"window":2}`, http.StatusUnprocessableEntity, `{"error":"result is not a finite number"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/moving-average", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestPercentileEndpoint tests the percentile endpoint
+func TestPercentileEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Median", `{"values":[5,1,4,2,3],"p":50}`, http.StatusOK, `{"result":3}`},
+		{"Interpolated", `{"values":[10,20,30,40,50],"p":95}`, http.StatusOK, `{"result":48}`},
+		{"Minimum", `{"values":[5,1,4],"p":0}`, http.StatusOK, `{"result":1}`},
+		{"Maximum", `{"values":[5,1,4],"p":100}`, http.StatusOK, `{"result":5}`},
+		{"Opposite extremes", `{"values":[-1e308,1e308],"p":75}`, http.StatusOK, `{"result":5e307}`},
+		{"Empty", `{"values":[],"p":50}`, http.StatusBadRequest, `{"error":"too few values: need at least 1, got 0"}`},
+		{"Out of range", `{"values":[1],"p":101}`, http.StatusBadRequest, `{"error":"percentile must be between 0 and 100, got 101"}`},
+		{"Missing p", `{"values":[1]}`, http.StatusBadRequest, `{"error":"p is required"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/percentile", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestMapEndpoint tests applying an operation to every value
+func TestMapEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Add", `{"values":[1,2,4],"op":"add","operand":3}`, http.StatusOK, `{"result":[4,5,7]}`},
+		{"Multiply", `{"values":[1,2,4],"op":"multiply","operand":2}`, http.StatusOK, `{"result":[2,4,8]}`},
+		{"Empty", `{"values":[],"op":"multiply","operand":2}`, http.StatusOK, `{"result":[]}`},
+		{"Divide by zero", `{"values":[1,2],"op":"divide","operand":0}`, http.StatusBadRequest, `{"error":"Division by zero"}`},
+		{"Unknown operation", `{"values":[1],"op":"power","operand":2}`, http.StatusBadRequest, `{"error":"unknown operation \"power\""}`},
+		{"Too large", `{"values":[1e308],"op":"multiply","operand":10}`, http.StatusUnprocessableEntity, `{"error":"result is not a finite number"}`},
+		{"Too small", `{"values":[-1e308],"op":"subtract","operand":1e308}`, http.StatusUnprocessableEntity, `{"error":"result is not a finite number"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/map", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestMedianModeEndpoints tests the median and mode endpoints
+func TestMedianModeEndpoints(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Median of odd count", "/calculator/median", `{"values":[9,1,5]}`, http.StatusOK, `{"result":5}`},
+		{"Median of even count", "/calculator/median", `{"values":[9,1,5,2]}`, http.StatusOK, `{"result":3.5}`},
+		{"Median of opposite extremes", "/calculator/median", `{"values":[-1e308,1e308]}`, http.StatusOK, `{"result":0}`},
+		{"Median of none", "/calculator/median", `{"values":[]}`, http.StatusBadRequest, `{"error":"too few values: need at least 1, got 0"}`},
+		{"Single mode", "/calculator/mode", `{"values":[1,2,2,3]}`, http.StatusOK, `{"result":[2]}`},
+		{"Multimodal", "/calculator/mode", `{"values":[3,1,3,2,1]}`, http.StatusOK, `{"result":[1,3]}`},
+		{"Mode of none", "/calculator/mode", `{}`, http.StatusBadRequest, `{"error":"too few values: need at least 1, got 0"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestMatrixMultiplyEndpoint tests the matrix multiplication endpoint
+func TestMatrixMultiplyEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+	tooTall := "[" + strings.Repeat("[1],", maxMatrixSize) + "[1]]"
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"2x2 product", `{"a":[[1,2],[3,4]],"b":[[5,6],[7,8]]}`, http.StatusOK, `{"result":[[19,22],[43,50]]}`},
+		{"Dimension mismatch", `{"a":[[1,2],[3,4]],"b":[[1,2,3]]}`, http.StatusBadRequest,
+			`{"error":"matrix dimension mismatch: a is 2x2 and b is 1x3"}`},
+		{"Ragged input", `{"a":[[1,2],[3]],"b":[[1],[2]]}`, http.StatusBadRequest,
+			`{"error":"invalid matrix: a is ragged: row 1 has 1 columns, row 0 has 2"}`},
+		{"Missing matrix", `{"a":[[1]]}`, http.StatusBadRequest, `{"error":"invalid matrix: b is empty"}`},
+		{"Too large", `{"a":` + tooTall + `,"b":[[1]]}`, http.StatusBadRequest, `{"error":"matrices are limited to 100x100"}`},
+		{"Overflowing result", `{"a":[[1e308,1e308]],"b":[[10],[10]]}`, http.StatusUnprocessableEntity, `{"error":"result is not a finite number"}`},
+		{"Invalid body", `{"a":[1,2]}`, http.StatusBadRequest, `{"error":"field a.0 must be an array"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/matrix-multiply", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestComplexEndpoint tests arithmetic on complex numbers
+func TestComplexEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+	const operands = `{"a":{"re":1,"im":2},"b":{"re":3,"im":-1}}`
+
+	tests := []struct {
+		name           string
+		op             string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Add", "add", operands, http.StatusOK, `{"result":{"re":4,"im":1}}`},
+		{"Subtract", "subtract", operands, http.StatusOK, `{"result":{"re":-2,"im":3}}`},
+		{"Multiply", "multiply", operands, http.StatusOK, `{"result":{"re":5,"im":5}}`},
+		{"Divide", "divide", `{"a":{"re":5,"im":5},"b":{"re":1,"im":2}}`, http.StatusOK, `{"result":{"re":3,"im":-1}}`},
+		{"Divide by zero", "divide", `{"a":{"re":1,"im":2},"b":{"re":0,"im":0}}`, http.StatusBadRequest, `{"error":"Division by zero"}`},
+		{"Overflowing result", "multiply", `{"a":{"re":1e308,"im":1e308},"b":{"re":10,"im":0}}`, http.StatusUnprocessableEntity,
+			`{"error":"result is not a finite number"}`},
+		{"Unknown operation", "power", operands, http.StatusBadRequest, `{"error":"unknown operation \"power\""}`},
+		{"Invalid body", "add", `{"a":{"re":"1"}}`, http.StatusBadRequest, `{"error":"field a.re must be a number"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/complex/"+tc.op, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestEvaluateEndpoint tests the expression evaluation endpoint
+func TestEvaluateEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Defined variables", `{"expr":"x * y + 2","vars":{"x":3,"y":4}}`, http.StatusOK, `{"result":14}`},
+		{"No variables", `{"expr":"(1 + 2) * 3"}`, http.StatusOK, `{"result":9}`},
+		{"Missing variable", `{"expr":"x * y + 2","vars":{"x":3}}`, http.StatusBadRequest, `{"error":"undefined variable \"y\""}`},
+		{"Division by zero", `{"expr":"x / 0","vars":{"x":1}}`, http.StatusBadRequest, `{"error":"Division by zero"}`},
+		{"Invalid expression", `{"expr":"1 +"}`, http.StatusBadRequest, `{"error":"invalid expression: unexpected end of expression"}`},
+		{"Invalid body", `{"expr":1}`, http.StatusBadRequest, `{"error":"field expr must be a string"}`},
+		{"Overflow", `{"expr":"1e308*10"}`, http.StatusUnprocessableEntity, `{"error":"result is not a finite number"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/evaluate", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestBatchEvaluateEndpoint tests that each expression in a batch succeeds
+// or fails on its own
+func TestBatchEvaluateEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Mixed", `["1 + 2", "1 +", "(3 - 1) * 4", "1 / 0", "x * 2"]`, http.StatusOK, `{"results":[
+			{"index":0,"expr":"1 + 2","result":3},
+			{"index":1,"expr":"1 +","error":"invalid expression: unexpected end of expression"},
+			{"index":2,"expr":"(3 - 1) * 4","result":8},
+			{"index":3,"expr":"1 / 0","error":"Division by zero"},
+			{"index":4,"expr":"x * 2","error":"undefined variable \"x\""}
+		],"succeeded":2,"failed":3}`},
+		{"Overflow", `["1+1", "1/0", "1e308*10"]`, http.StatusOK, `{"results":[
+			{"index":0,"expr":"1+1","result":2},
+			{"index":1,"expr":"1/0","error":"Division by zero"},
+			{"index":2,"expr":"1e308*10","error":"result is not a finite number"}
+		],"succeeded":1,"failed":2}`},
+		{"Zero result", `["2 - 2"]`, http.StatusOK, `{"results":[{"index":0,"expr":"2 - 2","result":0}],"succeeded":1,"failed":0}`},
+		{"Empty", `[]`, http.StatusOK, `{"results":[],"succeeded":0,"failed":0}`},
+		{"Not strings", `[1, 2]`, http.StatusBadRequest, `{"error":"field 0 must be a string"}`},
+		{"Too many", "[" + strings.Repeat(`"1",`, maxBatchExpressions) + `"1"]`, http.StatusBadRequest, `{"error":"at most 1000 expressions per request"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/batch-eval", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestMoneyEndpoint tests operations on dollar amounts
+func TestMoneyEndpoint(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Add with grouping", `{"a":"$1,234.56","b":"$10","op":"add"}`, http.StatusOK, `{"result":"$1,244.56"}`},
+		{"Subtract below zero", `{"a":"5","b":"$7.25","op":"subtract"}`, http.StatusOK, `{"result":"-$2.25"}`},
+		{"Cents do not drift", `{"a":"$0.10","b":"$0.20","op":"add"}`, http.StatusOK, `{"result":"$0.30"}`},
+		{"Malformed amount", `{"a":"$12,34","b":"$1","op":"add"}`, http.StatusBadRequest, `{"error":"invalid money amount: \"$12,34\""}`},
+		{"Amount out of range", `{"a":"99999999999999999999","b":"1","op":"add"}`, http.StatusBadRequest,
+			`{"error":"invalid money amount: \"99999999999999999999\" is out of range"}`},
+		{"Result out of range", `{"a":"$92,233,720,368,547,758.07","b":"$0.01","op":"add"}`, http.StatusBadRequest,
+			`{"error":"invalid money amount: result is out of range"}`},
+		{"Unknown operation", `{"a":"$1","b":"$1","op":"multiply"}`, http.StatusBadRequest, `{"error":"op must be add or subtract"}`},
+		{"Invalid body", `{"a":1}`, http.StatusBadRequest, `{"error":"field a must be a string"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/calculator/money", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestMinMaxEndpoints tests the min, max, abs, sign, hypot, log and round endpoints
+func TestMinMaxEndpoints(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Min", "/calculator/min?a=3&b=-2", http.StatusOK, `{"result":-2}`},
+		{"Max", "/calculator/max?a=3&b=-2", http.StatusOK, `{"result":3}`},
+		{"Min of equal values", "/calculator/min?a=4&b=4", http.StatusOK, `{"result":4}`},
+		{"Max of equal values", "/calculator/max?a=4&b=4", http.StatusOK, `{"result":4}`},
+		{"NaN operand", "/calculator/min?a=NaN&b=1", http.StatusBadRequest, `{"error":"a must be a number"}`},
+		{"Missing operand", "/calculator/max?a=1", http.StatusBadRequest, `{"error":"b is required"}`},
+		{"Abs", "/calculator/abs?a=-2.5", http.StatusOK, `{"result":2.5}`},
+		{"Abs of negative zero", "/calculator/abs?a=-0", http.StatusOK, `{"result":0}`},
+		{"Sign negative", "/calculator/sign?a=-2.5", http.StatusOK, `{"result":-1}`},
+		{"Sign positive", "/calculator/sign?a=7", http.StatusOK, `{"result":1}`},
+		{"Sign of negative zero", "/calculator/sign?a=-0", http.StatusOK, `{"result":0}`},
+		{"Sign of NaN", "/calculator/sign?a=NaN", http.StatusBadRequest, `{"error":"a must be a number"}`},
+		{"Abs missing operand", "/calculator/abs", http.StatusBadRequest, `{"error":"a is required"}`},
+		{"Natural log", "/calculator/log?a=1", http.StatusOK, `{"result":0}`},
+		{"Natural log with base e", "/calculator/log?a=2.718281828459045&base=e", http.StatusOK, `{"result":1}`},
+		{"Log base 2", "/calculator/log?a=8&base=2", http.StatusOK, `{"result":3}`},
+		{"Log base 10", "/calculator/log?a=100&base=10", http.StatusOK, `{"result":2}`},
+		{"Log of zero", "/calculator/log?a=0&base=10", http.StatusBadRequest,
+			`{"error":"logarithm is only defined for positive numbers, got 0"}`},
+		{"Log base one", "/calculator/log?a=5&base=1", http.StatusBadRequest,
+			`{"error":"logarithm base must be positive and not 1, got 1"}`},
+		{"Log invalid base", "/calculator/log?a=5&base=ten", http.StatusBadRequest, `{"error":"base must be a number or e"}`},
+		{"Hypot", "/calculator/hypot?a=3&b=-4", http.StatusOK, `{"result":5}`},
+		{"Hypot of large legs", "/calculator/hypot?a=-1e300&b=0", http.StatusOK, `{"result":1e300}`},
+		{"Hypot too large", "/calculator/hypot?a=1.5e308&b=1.5e308", http.StatusUnprocessableEntity, `{"error":"result is not a finite number"}`},
+		{"Round half-up by default", "/calculator/round?a=2.5", http.StatusOK, `{"result":3}`},
+		{"Round half-even", "/calculator/round?a=2.5&mode=half-even", http.StatusOK, `{"result":2}`},
+		{"Round half-even odd", "/calculator/round?a=3.5&mode=half-even", http.StatusOK, `{"result":4}`},
+		{"Round places", "/calculator/round?a=1.2345&places=2&mode=floor", http.StatusOK, `{"result":1.23}`},
+		{"Round unknown mode", "/calculator/round?a=2.5&mode=up", http.StatusBadRequest, `{"error":"unknown rounding mode \"up\""}`},
+		{"Round places out of range", "/calculator/round?a=2.5&places=20", http.StatusBadRequest, `{"error":"places must be between -15 and 15"}`},
+		{"Round 7 to multiple of 5", "/calculator/round-to-multiple?a=7&multiple=5", http.StatusOK, `{"result":5}`},
+		{"Round negative to multiple", "/calculator/round-to-multiple?a=-8&multiple=5", http.StatusOK, `{"result":-10}`},
+		{"Round to zero multiple", "/calculator/round-to-multiple?a=7&multiple=0", http.StatusBadRequest,
+			`{"error":"multiple must be a positive number, got 0"}`},
+		{"Round to multiple without multiple", "/calculator/round-to-multiple?a=7", http.StatusBadRequest, `{"error":"multiple is required"}`},
+		{"Round non-integer places", "/calculator/round?a=2.5&places=1.5", http.StatusBadRequest, `{"error":"places must be an integer"}`},
+		{"Properties of add", "/calculator/properties?op=add", http.StatusOK,
+			`{"operation":"add","commutative":true,"associative":true,"has_identity":true,"identity":0}`},
+		{"Properties of subtract", "/calculator/properties?op=subtract", http.StatusOK,
+			`{"operation":"subtract","commutative":false,"associative":false,"has_identity":false}`},
+		{"Properties of unknown op", "/calculator/properties?op=modulo", http.StatusBadRequest, `{"error":"unknown operation \"modulo\""}`},
+		{"Properties without op", "/calculator/properties", http.StatusBadRequest, `{"error":"op is required"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestOperandNames tests calculator endpoints reading configured operand names
+func TestOperandNames(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Default names", nil, "GET", "/calculator/add?a=2&b=3", "", http.StatusOK, `{"result":5}`},
+		{"Configured names", []Option{WithOperandNames("x", "y")}, "GET", "/calculator/subtract?x=5&y=3", "", http.StatusOK, `{"result":2}`},
+		{"Configured names in JSON body", []Option{WithOperandNames("x", "y")}, "POST", "/calculator/multiply", `{"x":4,"y":5}`, http.StatusOK, `{"result":20}`},
+		{"Configured names for fractions", []Option{WithOperandNames("first", "second")}, "GET", "/calculator/divide?first=1&second=3&as=fraction", "", http.StatusOK, `{"result":"1/3"}`},
+		{"Configured names for almost-equal", []Option{WithOperandNames("x", "y")}, "GET", "/calculator/almost-equal?x=1&y=1", "", http.StatusOK, `{"result":true}`},
+		{"Default names ignored once renamed", []Option{WithOperandNames("x", "y")}, "GET", "/calculator/add?a=2&b=3", "", http.StatusBadRequest, `{"error":"x is required"}`},
+		{"Errors use configured names", []Option{WithOperandNames("x", "y")}, "GET", "/calculator/add?x=2&y=three", "", http.StatusBadRequest, `{"error":"y must be a number"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), tc.opts...)
+
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
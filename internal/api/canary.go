@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// canaryContextKey marks requests chosen for the canary
+const canaryContextKey contextKey = "canary"
+
+// IsCanary reports whether a request was chosen for the canary by the
+// canary middleware enabled with WithCanary
+func IsCanary(ctx context.Context) bool {
+	canary, _ := ctx.Value(canaryContextKey).(bool)
+	return canary
+}
+
+// tagCanary decides whether each request is a canary request, records the
+// decision in the request context and reports it in the X-Canary response
+// header. An X-Canary request header of true or false forces the decision;
+// otherwise clients are bucketed by IP, so each client consistently gets the
+// same answer.
+func (s *Server) tagCanary(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		canary, err := strconv.ParseBool(r.Header.Get("X-Canary"))
+		if err != nil {
+			canary = canaryBucket(clientIP(r)) < s.canaryPercent
+		}
+
+		w.Header().Set("X-Canary", strconv.FormatBool(canary))
+		ctx := context.WithValue(r.Context(), canaryContextKey, canary)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// canaryBucket maps a client to one of 100 buckets
+func canaryBucket(client string) int {
+	h := fnv.New32a()
+	h.Write([]byte(client))
+	return int(h.Sum32() % 100)
+}
+
+// clientIP returns the address of the client connected to the server
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCanaryHeader tests that the canary decision is reported and can be
+// forced by the client
+func TestCanaryHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		percent  int
+		header   string
+		expected string
+	}{
+		{"Nobody at 0%", 0, "", "false"},
+		{"Everybody at 100%", 100, "", "true"},
+		{"Opt in", 0, "true", "true"},
+		{"Opt out", 100, "false", "false"},
+		{"Invalid header is ignored", 100, "maybe", "true"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithCanary(tc.percent))
+			var canary bool
+			handler := server.tagCanary(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				canary = IsCanary(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/health", nil)
+			if tc.header != "" {
+				req.Header.Set("X-Canary", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected, rec.Header().Get("X-Canary"))
+			assert.Equal(t, tc.expected == "true", canary)
+		})
+	}
+}
+
+// TestCanaryPercentage tests that roughly the configured share of clients
+// are canaries and that each client always gets the same answer
+func TestCanaryPercentage(t *testing.T) {
+	handler := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithCanary(20)).Router()
+
+	canaryFor := func(ip string) string {
+		req := httptest.NewRequest("GET", "/health", nil)
+		req.RemoteAddr = ip + ":12345"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Header().Get("X-Canary")
+	}
+
+	const clients = 5000
+	canaries := 0
+	for i := range clients {
+		ip := fmt.Sprintf("10.%d.%d.%d", i/65536, i/256%256, i%256)
+		first := canaryFor(ip)
+		if first == "true" {
+			canaries++
+		}
+		assert.Equal(t, first, canaryFor(ip), "client %s", ip)
+	}
+
+	assert.InDelta(t, 0.20, float64(canaries)/clients, 0.03)
+}
+
+// TestCanaryWithResponseCache tests that a cached response reports the
+// canary decision for the request it is served to
+func TestCanaryWithResponseCache(t *testing.T) {
+	repo := database.NewUserRepository(&database.User{Username: "alice", Email: "alice@example.com"})
+	handler := NewServer(repo, calculator.NewCalculator(), WithCanary(0), WithResponseCache(time.Minute, 10)).Router()
+
+	get := func(canary string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		req.Header.Set("X-Canary", canary)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, "true", get("true").Header().Get("X-Canary"))
+	hit := get("false")
+	assert.Equal(t, "HIT", hit.Header().Get("X-Cache"))
+	assert.Equal(t, "false", hit.Header().Get("X-Canary"))
+}
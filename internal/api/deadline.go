@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// deadlineHeader tells clients when the server gives up on their request
+const deadlineHeader = "X-Deadline"
+
+// enforceDeadline gives every request a context that is cancelled once the
+// request timeout passes, and reports the absolute deadline in the
+// X-Deadline header as an RFC 3339 UTC timestamp, so clients can align
+// their own timeouts with the server's. Handlers that honour their context
+// stop working on the request when it is cancelled.
+func (s *Server) enforceDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+		defer cancel()
+
+		// The header reports the context's own deadline, which follows the
+		// real clock rather than the server's injectable one
+		deadline, _ := ctx.Deadline()
+		w.Header().Set(deadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// maxBufferedBodyBytes bounds the request bodies middleware and handlers
+// read into memory
+const maxBufferedBodyBytes = 10 << 20
+
+// errBodyTooLarge is returned by decodeJSON for bodies over maxBufferedBodyBytes
+var errBodyTooLarge = errors.New("request body is too large")
+
+// bufferBody reads the whole request body for middleware to inspect, leaving
+// a copy in r.Body for the handler. If the body cannot be read it responds
+// 413 or 400 and returns false.
+func bufferBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBufferedBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(w, r, http.StatusRequestEntityTooLarge, "request body is too large")
+		} else {
+			respondError(w, r, http.StatusBadRequest, "error reading request body")
+		}
+		return nil, false
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+// decodeJSON decodes the request body, up to maxBufferedBodyBytes of it,
+// into v. Its errors are meant for the client, saying where a malformed body
+// went wrong rather than just that it did; respondDecodeError sends them.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBufferedBodyBytes)).Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var tooLarge *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		return errBodyTooLarge
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("invalid JSON at byte %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Errorf("field %s must be %s", typeErr.Field, jsonTypeName(typeErr.Type))
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("request body must be %s", jsonTypeName(typeErr.Type))
+	case errors.Is(err, io.EOF):
+		return errors.New("request body is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("invalid JSON: unexpected end of input")
+	default:
+		return errors.New("Invalid request body")
+	}
+}
+
+// respondDecodeError responds to an error from decodeJSON with 413 for an
+// oversized body and 400 otherwise
+func respondDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errBodyTooLarge) {
+		respondError(w, r, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	respondError(w, r, http.StatusBadRequest, err.Error())
+}
+
+// jsonTypeName describes the JSON value a Go type is decoded from
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// duplicateStore remembers recent POST submissions by a hash of the client,
+// request URI and body, so accidental double submits can be refused
+type duplicateStore struct {
+	mutex   sync.Mutex
+	window  time.Duration
+	expires map[[sha256.Size]byte]time.Time
+}
+
+func newDuplicateStore(window time.Duration) *duplicateStore {
+	return &duplicateStore{window: window, expires: map[[sha256.Size]byte]time.Time{}}
+}
+
+// claim records a submission, returning false if the same one was recorded
+// within the window
+func (st *duplicateStore) claim(key [sha256.Size]byte, now time.Time) bool {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	for k, expires := range st.expires {
+		if now.After(expires) {
+			delete(st.expires, k)
+		}
+	}
+
+	if _, ok := st.expires[key]; ok {
+		return false
+	}
+	st.expires[key] = now.Add(st.window)
+	return true
+}
+
+// release forgets a submission, so it can be sent again straight away
+func (st *duplicateStore) release(key [sha256.Size]byte) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	delete(st.expires, key)
+}
+
+// rejectDuplicates refuses a request creating users with 409 when the same
+// client sent the same body to the same URI within the duplicate window,
+// without needing the client's cooperation. Other POSTs, such as calculator
+// operations, are meant to be repeated and are let through. When idempotency
+// keys are enabled, requests with an Idempotency-Key are left to that
+// middleware. Submissions that fail on the server are forgotten so they can
+// be retried.
+func (s *Server) rejectDuplicates(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hasKey := s.idempotency != nil && r.Header.Get("Idempotency-Key") != ""
+		if !createsUsers(r) || hasKey {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, ok := bufferBody(w, r)
+		if !ok {
+			return
+		}
+
+		client := requestIdentity(r.Context())
+		if client == "" {
+			client = clientIP(r)
+		}
+		h := sha256.New()
+		for _, part := range []string{client, r.URL.RequestURI()} {
+			h.Write([]byte(part))
+			h.Write([]byte{0})
+		}
+		h.Write(body)
+		var key [sha256.Size]byte
+		h.Sum(key[:0])
+
+		if !s.duplicates.claim(key, s.now()) {
+			respondError(w, r, http.StatusConflict, "An identical request was submitted moments ago")
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		sw.finish()
+
+		if sw.status >= http.StatusInternalServerError {
+			s.duplicates.release(key)
+		}
+	})
+}
+
+// createsUsers reports whether r is a request to create users
+func createsUsers(r *http.Request) bool {
+	return r.Method == http.MethodPost && (r.URL.Path == "/users" || r.URL.Path == "/users/bulk")
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDuplicateDetection tests that an identical POST is rejected within the
+// window and accepted again after it
+func TestDuplicateDetection(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo := new(database.MockUserRepository)
+	handler := NewServer(mockRepo, calculator.NewCalculator(),
+		WithDuplicateDetection(5*time.Second), WithClock(func() time.Time { return now })).Router()
+
+	const alice = `{"username":"alice","email":"alice@example.com"}`
+	mockRepo.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(nil).Once()
+	require.Equal(t, http.StatusCreated, sendWithKey(handler, "POST", "/users", "", alice).Code)
+
+	now = now.Add(time.Second)
+	rec := sendWithKey(handler, "POST", "/users", "", alice)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{"error":"An identical request was submitted moments ago"}`, rec.Body.String())
+
+	// A different body is not a duplicate
+	mockRepo.On("CreateUser", &database.User{Username: "bob", Email: "bob@example.com"}).Return(nil).Once()
+	assert.Equal(t, http.StatusCreated,
+		sendWithKey(handler, "POST", "/users", "", `{"username":"bob","email":"bob@example.com"}`).Code)
+
+	// Once the window has passed the same body is handled again
+	now = now.Add(5 * time.Second)
+	mockRepo.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(nil).Once()
+	assert.Equal(t, http.StatusCreated, sendWithKey(handler, "POST", "/users", "", alice).Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDuplicateDetectionRequests tests which repeated requests are let through
+func TestDuplicateDetectionRequests(t *testing.T) {
+	const alice = `{"username":"alice","email":"alice@example.com"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		key            string
+		opts           []Option
+		setup          func(*database.MockUserRepository)
+		expectedStatus int
+	}{
+		{"Repeated POST", "POST", "/users", "", nil, func(m *database.MockUserRepository) {
+			m.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(nil).Once()
+		}, http.StatusConflict},
+		{"Repeated PUT", "PUT", "/users/1", "", nil, func(m *database.MockUserRepository) {
+			m.On("UpdateUser", &database.User{ID: 1, Username: "alice", Email: "alice@example.com"}).Return(nil).Twice()
+		}, http.StatusOK},
+		{"Server error is retried", "POST", "/users", "", nil, func(m *database.MockUserRepository) {
+			m.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(assert.AnError).Once()
+			m.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(nil).Once()
+		}, http.StatusCreated},
+		{"Idempotency-Key is left to idempotency", "POST", "/users", "k", []Option{WithIdempotency(time.Hour)}, func(m *database.MockUserRepository) {
+			m.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(nil).Once()
+		}, http.StatusCreated},
+		{"Idempotency-Key without idempotency", "POST", "/users", "k", nil, func(m *database.MockUserRepository) {
+			m.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(nil).Once()
+		}, http.StatusConflict},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			tc.setup(mockRepo)
+			opts := append([]Option{WithDuplicateDetection(time.Minute)}, tc.opts...)
+			handler := NewServer(mockRepo, calculator.NewCalculator(), opts...).Router()
+
+			sendWithKey(handler, tc.method, tc.path, tc.key, alice)
+			rec := sendWithKey(handler, tc.method, tc.path, tc.key, alice)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestDuplicateDetectionIgnoresOtherPosts tests that POSTs other than user
+// creation, such as calculator operations, can be repeated
+func TestDuplicateDetectionIgnoresOtherPosts(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(),
+		WithDuplicateDetection(time.Minute), WithCalculatorSessions(time.Minute))
+	defer server.Shutdown(context.Background())
+	handler := server.Router()
+
+	rec := sendWithKey(handler, "POST", "/calculator/sessions", "", "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var state calculator.SessionState
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+
+	for _, path := range []string{"/calculator/sessions/" + state.ID + "/add?b=1", "/calculator/dot"} {
+		for range 2 {
+			assert.Equal(t, http.StatusOK, sendWithKey(handler, "POST", path, "", `{"a":[1],"b":[2]}`).Code, path)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/database"
+)
+
+// userFields holds the JSON names of the fields a user can be projected to
+var userFields = jsonFieldNames(reflect.TypeOf(database.User{}))
+
+// parseFields reads the comma-separated fields query parameter, dropping
+// names that are not user fields. A nil result means the full user.
+func parseFields(r *http.Request) []string {
+	var fields []string
+	for _, name := range strings.Split(r.URL.Query().Get("fields"), ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := userFields[name]; ok {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// projectUser returns user reduced to fields, or user itself when fields is nil
+func projectUser(user *database.User, fields []string) interface{} {
+	if fields == nil {
+		return user
+	}
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		return user
+	}
+	var full map[string]json.RawMessage
+	if err := json.Unmarshal(data, &full); err != nil {
+		return user
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, name := range fields {
+		if value, ok := full[name]; ok {
+			projected[name] = value
+		}
+	}
+	return projected
+}
+
+// projectUsers applies projectUser to every user in the list
+func projectUsers(users []*database.User, fields []string) []interface{} {
+	projected := make([]interface{}, 0, len(users))
+	for _, user := range users {
+		projected = append(projected, projectUser(user, fields))
+	}
+	return projected
+}
+
+// summarizeUsers returns the summary view of users
+func summarizeUsers(users []*database.User) []definitions.UserSummary {
+	summaries := make([]definitions.UserSummary, 0, len(users))
+	for _, user := range users {
+		summaries = append(summaries, definitions.UserSummary{ID: user.ID, Username: user.Username})
+	}
+	return summaries
+}
+
+// jsonFieldNames returns the JSON names of a struct type's exported fields
+func jsonFieldNames(t reflect.Type) map[string]struct{} {
+	names := make(map[string]struct{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names[name] = struct{}{}
+	}
+	return names
+}
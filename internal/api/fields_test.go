@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestListUsersFields tests projecting every user in the list to the requested fields
+func TestListUsersFields(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		opts         []Option
+		expectedBody string
+	}{
+		{"Subset", "/users?fields=id,email", nil,
+			`[{"id":1,"email":"user1@example.com"},{"id":2,"email":"user2@example.com"},{"id":3,"email":"user3@example.com"}]`},
+		{"Unknown names ignored", "/users?fields=username,password", nil,
+			`[{"username":"user1"},{"username":"user2"},{"username":"user3"}]`},
+		{"Empty returns full objects", "/users?fields=", nil,
+			`[{"id":1,"username":"user1","email":"user1@example.com"},{"id":2,"username":"user2","email":"user2@example.com"},{"id":3,"username":"user3","email":"user3@example.com"}]`},
+		{"Only unknown names returns full objects", "/users?fields=password", nil,
+			`[{"id":1,"username":"user1","email":"user1@example.com"},{"id":2,"username":"user2","email":"user2@example.com"},{"id":3,"username":"user3","email":"user3@example.com"}]`},
+		{"Envelope", "/users?fields=id", []Option{WithUserListEnvelope()},
+			`{"users":[{"id":1},{"id":2},{"id":3}],"total":3}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			mockRepo.On("ListUsers").Return(seedUsers(3), nil)
+			server := NewServer(mockRepo, calculator.NewCalculator(), tc.opts...)
+
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestListUsersSummaryView tests that the summary view lists users without
+// their emails
+func TestListUsersSummaryView(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		opts           []Option
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Summary", "/users?view=summary", nil, http.StatusOK,
+			`[{"id":1,"username":"user1"},{"id":2,"username":"user2"},{"id":3,"username":"user3"}]`},
+		{"Summary ignores fields", "/users?view=summary&fields=email", nil, http.StatusOK,
+			`[{"id":1,"username":"user1"},{"id":2,"username":"user2"},{"id":3,"username":"user3"}]`},
+		{"Summary envelope", "/users?view=summary", []Option{WithUserListEnvelope()}, http.StatusOK,
+			`{"users":[{"id":1,"username":"user1"},{"id":2,"username":"user2"},{"id":3,"username":"user3"}],"total":3}`},
+		{"Full", "/users?view=full&limit=1", nil, http.StatusOK,
+			`[{"id":1,"username":"user1","email":"user1@example.com"}]`},
+		{"Unknown view", "/users?view=compact", nil, http.StatusBadRequest, `{"error":"view must be full or summary"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			mockRepo.On("ListUsers").Return(seedUsers(3), nil)
+			server := NewServer(mockRepo, calculator.NewCalculator(), tc.opts...)
+
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			if strings.Contains(tc.url, "view=summary") {
+				assert.NotContains(t, rec.Body.String(), "email")
+			}
+		})
+	}
+}
+
+// TestGetUserFields tests projecting a single user to the requested fields
+func TestGetUserFields(t *testing.T) {
+	server, mockRepo, _ := setupTestServer()
+	mockRepo.On("GetUser", 1).Return(&database.User{ID: 1, Username: "user1", Email: "user1@example.com"}, nil)
+
+	req := httptest.NewRequest("GET", "/users/1?fields=email", nil)
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"email":"user1@example.com"}`, rec.Body.String())
+}
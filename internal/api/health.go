@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"go-testing/api/definitions"
+)
+
+// BuildInfo identifies the running build. Both fields are usually injected
+// at link time with -ldflags "-X main.version=... -X main.commit=...".
+type BuildInfo struct {
+	Version string
+	Commit  string
+}
+
+// HealthChecker is a component whose health /health/detailed reports
+type HealthChecker interface {
+	// Name identifies the component in health reports
+	Name() string
+	// Check returns an error if the component is unhealthy
+	Check(ctx context.Context) error
+}
+
+// healthCheckTimeout bounds how long /health/detailed waits for each check
+const healthCheckTimeout = 5 * time.Second
+
+// RegisterHealthChecker adds a checker to those reported by /health/detailed.
+// It is safe to call while the server is running.
+func (s *Server) RegisterHealthChecker(checker HealthChecker) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.healthCheckers = append(s.healthCheckers, checker)
+}
+
+// health godoc
+// @Summary Report server health
+// @Description Report that the server is up, how long it has been running, and
+// @Description the build version and commit when they were set at build time
+// @Tags health
+// @Produce json
+// @Success 200 {object} definitions.HealthResponse
+// @Router /health [get]
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, http.StatusOK, definitions.HealthResponse{
+		Status:  "ok",
+		Uptime:  s.now().Sub(s.startedAt).Truncate(time.Second).String(),
+		Version: s.buildInfo.Version,
+		Commit:  s.buildInfo.Commit,
+	})
+}
+
+// detailedHealth godoc
+// @Summary Report the health of each component
+// @Description Run every registered health check concurrently and report each
+// @Description result. Responds 503 if any check fails.
+// @Tags health
+// @Produce json
+// @Success 200 {object} definitions.DetailedHealthResponse
+// @Failure 503 {object} definitions.DetailedHealthResponse
+// @Router /health/detailed [get]
+func (s *Server) detailedHealth(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	checkers := slices.Clone(s.healthCheckers)
+	s.mutex.Unlock()
+
+	resp := definitions.DetailedHealthResponse{
+		Status: "ok",
+		Checks: make([]definitions.HealthCheckResult, len(checkers)),
+	}
+	forEachIndex(len(checkers), len(checkers), func(i int) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		resp.Checks[i] = definitions.HealthCheckResult{Name: checkers[i].Name(), Status: "ok"}
+		if err := checkers[i].Check(ctx); err != nil {
+			resp.Checks[i].Status = "fail"
+			resp.Checks[i].Error = err.Error()
+		}
+	})
+
+	status := http.StatusOK
+	for _, check := range resp.Checks {
+		if check.Status != "ok" {
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	respondJSON(w, r, status, resp)
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getHealth requests GET /health and decodes the response
+func getHealth(t *testing.T, handler http.Handler) (definitions.HealthResponse, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &raw))
+	var resp definitions.HealthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp, raw
+}
+
+// TestHealthUptime tests that the reported uptime grows with the clock
+func TestHealthUptime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithClock(clock))
+	router := server.Router()
+
+	first, _ := getHealth(t, router)
+	assert.Equal(t, "ok", first.Status)
+	assert.Equal(t, "0s", first.Uptime)
+
+	now = now.Add(90 * time.Second)
+	second, _ := getHealth(t, router)
+	assert.Equal(t, "1m30s", second.Uptime)
+
+	firstUptime, err := time.ParseDuration(first.Uptime)
+	require.NoError(t, err)
+	secondUptime, err := time.ParseDuration(second.Uptime)
+	require.NoError(t, err)
+	assert.Greater(t, secondUptime, firstUptime)
+}
+
+// TestHealthBuildInfo tests that build fields are reported only when set
+func TestHealthBuildInfo(t *testing.T) {
+	t.Run("Set", func(t *testing.T) {
+		server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(),
+			WithBuildInfo(BuildInfo{Version: "v1.2.3", Commit: "abc1234"}))
+
+		resp, _ := getHealth(t, server.Router())
+		assert.Equal(t, "v1.2.3", resp.Version)
+		assert.Equal(t, "abc1234", resp.Commit)
+	})
+
+	t.Run("Unset", func(t *testing.T) {
+		server := NewServer(new(database.MockUserRepository), calculator.NewCalculator())
+
+		_, raw := getHealth(t, server.Router())
+		assert.NotContains(t, raw, "version")
+		assert.NotContains(t, raw, "commit")
+	})
+}
+
+// TestHealthWithoutCredentials tests that health checks stay public when auth is enabled
+func TestHealthWithoutCredentials(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithJWTAuth(testJWTKey))
+
+	resp, _ := getHealth(t, server.Router())
+	assert.Equal(t, "ok", resp.Status)
+}
+
+// stubChecker is a HealthChecker returning a fixed error
+type stubChecker struct {
+	name string
+	err  error
+}
+
+func (c stubChecker) Name() string                    { return c.name }
+func (c stubChecker) Check(ctx context.Context) error { return c.err }
+
+// TestDetailedHealth tests aggregating registered health checks
+func TestDetailedHealth(t *testing.T) {
+	tests := []struct {
+		name           string
+		checkers       []HealthChecker
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"No checkers", nil, http.StatusOK, `{"status":"ok","checks":[]}`},
+		{"All passing", []HealthChecker{stubChecker{name: "cache"}, stubChecker{name: "queue"}},
+			http.StatusOK, `{"status":"ok","checks":[{"name":"cache","status":"ok"},{"name":"queue","status":"ok"}]}`},
+		{"One failing", []HealthChecker{stubChecker{name: "cache"}, stubChecker{name: "db", err: errors.New("connection refused")}},
+			http.StatusServiceUnavailable,
+			`{"status":"unavailable","checks":[{"name":"cache","status":"ok"},{"name":"db","status":"fail","error":"connection refused"}]}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithHealthCheckers(tc.checkers...))
+
+			req := httptest.NewRequest("GET", "/health/detailed", nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestDetailedHealthSelfRegistration tests that a repository able to check
+// itself is registered automatically, alongside checkers added later
+func TestDetailedHealthSelfRegistration(t *testing.T) {
+	server := NewServer(database.NewUserRepository(), calculator.NewCalculator())
+	server.RegisterHealthChecker(stubChecker{name: "late"})
+
+	req := httptest.NewRequest("GET", "/health/detailed", nil)
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok","checks":[{"name":"user-repository","status":"ok"},{"name":"late","status":"ok"}]}`, rec.Body.String())
+}
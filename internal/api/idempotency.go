@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long a response is replayed for its
+// Idempotency-Key when WithIdempotency is given no TTL
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyEntries bounds how many responses are remembered at once
+const maxIdempotencyEntries = 10000
+
+// idempotencyStore remembers the responses to unsafe requests by the
+// client, method, path and Idempotency-Key they were sent with
+type idempotencyStore struct {
+	mutex      sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*idempotentResponse
+}
+
+// idempotentResponse is a response recorded for replay. Until the original
+// request completes it is pending and has no status.
+type idempotentResponse struct {
+	bodyHash [sha256.Size]byte
+	expires  time.Time
+	pending  bool
+	status   int
+	header   http.Header
+	body     []byte
+}
+
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &idempotencyStore{ttl: ttl, maxEntries: maxIdempotencyEntries, entries: map[string]*idempotentResponse{}}
+}
+
+// errIdempotencyStoreFull is returned by begin when every remembered
+// response belongs to a request still in progress
+var errIdempotencyStoreFull = errors.New("too many requests with an Idempotency-Key are in progress")
+
+// begin claims key for a request whose body hashes to bodyHash. If the key
+// is already held it returns a copy of the existing entry and false;
+// otherwise it records a pending entry and returns true. When the store is
+// full the completed response closest to expiring is forgotten to make room.
+func (st *idempotencyStore) begin(key string, bodyHash [sha256.Size]byte, now time.Time) (idempotentResponse, bool, error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	for k, entry := range st.entries {
+		if now.After(entry.expires) {
+			delete(st.entries, k)
+		}
+	}
+
+	if entry, ok := st.entries[key]; ok {
+		return *entry, false, nil
+	}
+	if len(st.entries) >= st.maxEntries && !st.evictOldest() {
+		return idempotentResponse{}, false, errIdempotencyStoreFull
+	}
+	st.entries[key] = &idempotentResponse{bodyHash: bodyHash, expires: now.Add(st.ttl), pending: true}
+	return idempotentResponse{}, true, nil
+}
+
+// evictOldest forgets the completed response that expires first, reporting
+// whether there was one
+func (st *idempotencyStore) evictOldest() bool {
+	oldest := ""
+	for k, entry := range st.entries {
+		if !entry.pending && (oldest == "" || entry.expires.Before(st.entries[oldest].expires)) {
+			oldest = k
+		}
+	}
+	if oldest == "" {
+		return false
+	}
+	delete(st.entries, oldest)
+	return true
+}
+
+// release forgets key, so a request that did not complete can be retried
+func (st *idempotencyStore) release(key string) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	delete(st.entries, key)
+}
+
+// complete records the response to the request that claimed key. Server
+// errors release the key instead, so the client can retry.
+func (st *idempotencyStore) complete(key string, status int, header http.Header, body []byte) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if status >= http.StatusInternalServerError {
+		delete(st.entries, key)
+		return
+	}
+	entry, ok := st.entries[key]
+	if !ok {
+		return
+	}
+	entry.pending = false
+	entry.status = status
+	entry.header = header
+	entry.body = body
+}
+
+// idempotent makes unsafe requests carrying an Idempotency-Key header safe to
+// retry: the first response for a client, method, path and key is recorded
+// and replayed to later requests from that client with the same body, which
+// are not handled again. Reusing a key with a different body is rejected with 422.
+func (s *Server) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || !isUnsafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, ok := bufferBody(w, r)
+		if !ok {
+			return
+		}
+
+		// Keys are scoped to the client, so one client's response is never
+		// replayed to another that happens to pick the same key
+		storeKey := strings.Join([]string{requestIdentity(r.Context()), r.Method, r.URL.Path, key}, "\x00")
+		bodyHash := sha256.Sum256(body)
+		previous, claimed, err := s.idempotency.begin(storeKey, bodyHash, s.now())
+		switch {
+		case err != nil:
+			w.Header().Set("Retry-After", "1")
+			respondError(w, r, http.StatusServiceUnavailable, err.Error())
+			return
+		case claimed:
+		case previous.bodyHash != bodyHash:
+			respondError(w, r, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request body")
+			return
+		case previous.pending:
+			respondError(w, r, http.StatusConflict, "A request with this Idempotency-Key is still in progress")
+			return
+		default:
+			for name, values := range previous.header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(previous.status)
+			w.Write(previous.body)
+			return
+		}
+
+		rw := &recordingWriter{}
+		rw.ResponseWriter = w
+		rw.beforeHeader = func(h http.Header) {
+			rw.header = h.Clone()
+		}
+
+		// A handler that panics never completes, so its key is released
+		// rather than left pending until it expires
+		completed := false
+		defer func() {
+			if !completed {
+				s.idempotency.release(storeKey)
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+		rw.finish()
+
+		s.idempotency.complete(storeKey, rw.status, rw.header, rw.body.Bytes())
+		completed = true
+	})
+}
+
+// recordingWriter is a statusWriter that also keeps a copy of the headers and
+// body it sends
+type recordingWriter struct {
+	statusWriter
+	header http.Header
+	body   bytes.Buffer
+}
+
+// Write sends b and records the part that was sent
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	n, err := w.statusWriter.Write(b)
+	w.body.Write(b[:n])
+	return n, err
+}
+
+// isUnsafeMethod reports whether method may change server state
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sendWithKey serves a request carrying an Idempotency-Key header
+func sendWithKey(handler http.Handler, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestIdempotencyReplay tests that repeating an unsafe request with the same
+// key and body replays the first response without handling it again
+func TestIdempotencyReplay(t *testing.T) {
+	repo := database.NewUserRepository()
+	server := NewServer(repo, calculator.NewCalculator(), WithIdempotency(time.Hour))
+	handler := server.Router()
+
+	const alice = `{"username":"alice","email":"alice@example.com"}`
+	first := sendWithKey(handler, "POST", "/users", "create-1", alice)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	second := sendWithKey(handler, "POST", "/users", "create-1", alice)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), second.Header().Get("Content-Type"))
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	users, err := repo.ListUsers()
+	require.NoError(t, err)
+	assert.Len(t, users, 1, "the replayed request must not create a second user")
+
+	// The same key is independent on another method or path
+	const renamed = `{"username":"alicia","email":"alice@example.com"}`
+	update := sendWithKey(handler, "PUT", "/users/1", "create-1", renamed)
+	assert.Equal(t, http.StatusOK, update.Code)
+	assert.Empty(t, update.Header().Get("Idempotent-Replayed"))
+
+	deleted := sendWithKey(handler, "DELETE", "/users/1", "delete-1", "")
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+	replayed := sendWithKey(handler, "DELETE", "/users/1", "delete-1", "")
+	assert.Equal(t, http.StatusNoContent, replayed.Code, "a retried delete must not report 404")
+	assert.Equal(t, "true", replayed.Header().Get("Idempotent-Replayed"))
+}
+
+// TestIdempotencyRequests tests which requests take part in idempotency
+func TestIdempotencyRequests(t *testing.T) {
+	const alice = `{"username":"alice","email":"alice@example.com"}`
+	const bob = `{"username":"bob","email":"bob@example.com"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		key            string
+		secondBody     string
+		expectedStatus int
+		expectedUsers  int
+	}{
+		{"Different body is rejected", "POST", "k", bob, http.StatusUnprocessableEntity, 1},
+		{"Same body is replayed", "POST", "k", alice, http.StatusCreated, 1},
+		{"Without a key requests are handled again", "POST", "", alice, http.StatusCreated, 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := database.NewUserRepository()
+			handler := NewServer(repo, calculator.NewCalculator(), WithIdempotency(0)).Router()
+
+			require.Equal(t, http.StatusCreated, sendWithKey(handler, tc.method, "/users", tc.key, alice).Code)
+			rec := sendWithKey(handler, tc.method, "/users", tc.key, tc.secondBody)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			users, err := repo.ListUsers()
+			require.NoError(t, err)
+			assert.Len(t, users, tc.expectedUsers)
+		})
+	}
+}
+
+// TestIdempotencyServerErrorsAreNotReplayed tests that a key is released when
+// the request fails on the server, so the client can retry it
+func TestIdempotencyServerErrorsAreNotReplayed(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	handler := NewServer(mockRepo, calculator.NewCalculator(), WithIdempotency(time.Hour)).Router()
+
+	mockRepo.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(assert.AnError).Once()
+	mockRepo.On("CreateUser", &database.User{Username: "alice", Email: "alice@example.com"}).Return(nil).Once()
+
+	const alice = `{"username":"alice","email":"alice@example.com"}`
+	assert.Equal(t, http.StatusInternalServerError, sendWithKey(handler, "POST", "/users", "k", alice).Code)
+	assert.Equal(t, http.StatusCreated, sendWithKey(handler, "POST", "/users", "k", alice).Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestIdempotencyExpiry tests that keys can be reused once their TTL passes
+func TestIdempotencyExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := database.NewUserRepository()
+	handler := NewServer(repo, calculator.NewCalculator(),
+		WithIdempotency(time.Minute), WithClock(func() time.Time { return now })).Router()
+
+	require.Equal(t, http.StatusCreated,
+		sendWithKey(handler, "POST", "/users", "k", `{"username":"alice","email":"alice@example.com"}`).Code)
+
+	now = now.Add(2 * time.Minute)
+	rec := sendWithKey(handler, "POST", "/users", "k", `{"username":"bob","email":"bob@example.com"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+}
+
+// TestIdempotencyScopedToClient tests that a key used by one client is not
+// replayed to another client sending the same key
+func TestIdempotencyScopedToClient(t *testing.T) {
+	repo := database.NewUserRepository()
+	handler := NewServer(repo, calculator.NewCalculator(), WithIdempotency(time.Hour),
+		WithAPIKeys(map[string]string{"alice-key": "alice", "bob-key": "bob"})).Router()
+
+	send := func(apiKey, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "k")
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	const carol = `{"username":"carol","email":"carol@example.com"}`
+	require.Equal(t, http.StatusCreated, send("alice-key", carol).Code)
+	assert.Equal(t, "true", send("alice-key", carol).Header().Get("Idempotent-Replayed"))
+
+	rec := send("bob-key", `{"username":"dave","email":"dave@example.com"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code, "another client's key must not be reported as reused")
+	assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+	assert.Contains(t, rec.Body.String(), "dave")
+}
+
+// TestIdempotencyStoreLimit tests that the store forgets the oldest
+// completed response when full, and refuses new keys when every entry is
+// still in progress
+func TestIdempotencyStoreLimit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newIdempotencyStore(time.Hour)
+	store.maxEntries = 2
+
+	for i, key := range []string{"a", "b"} {
+		_, claimed, err := store.begin(key, [32]byte{}, now.Add(time.Duration(i)*time.Second))
+		require.NoError(t, err)
+		require.True(t, claimed)
+	}
+	_, _, err := store.begin("c", [32]byte{}, now)
+	assert.ErrorIs(t, err, errIdempotencyStoreFull)
+
+	store.complete("b", http.StatusCreated, nil, nil)
+	store.complete("a", http.StatusCreated, nil, nil)
+	_, claimed, err := store.begin("c", [32]byte{}, now)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// a expired first, so it was forgotten and b kept
+	_, claimed, _ = store.begin("b", [32]byte{}, now)
+	assert.False(t, claimed)
+	assert.Len(t, store.entries, 2)
+	assert.NotContains(t, store.entries, "a")
+}
+
+// TestIdempotencyPanicReleasesKey tests that a key is released when its
+// handler panics, so a retry is handled rather than reported as in progress
+func TestIdempotencyPanicReleasesKey(t *testing.T) {
+	server := NewServer(database.NewUserRepository(), calculator.NewCalculator(), WithIdempotency(time.Hour))
+	panics := true
+	handler := server.idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if panics {
+			panic(http.ErrAbortHandler)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	assert.Panics(t, func() { sendWithKey(handler, "POST", "/users", "k", `{}`) })
+
+	panics = false
+	assert.Equal(t, http.StatusCreated, sendWithKey(handler, "POST", "/users", "k", `{}`).Code)
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// middleware wraps the router with the middleware enabled through options.
+// Middleware wrapped last runs first.
+func (s *Server) middleware(h http.Handler) http.Handler {
+	if s.auditLog != nil {
+		h = s.auditMutations(h)
+	}
+	h = s.cacheRepoLookups(h)
+	if s.responseCache != nil {
+		h = s.cacheResponses(h)
+	}
+	// Idempotency runs after authentication, so only authenticated requests claim keys
+	if s.idempotency != nil {
+		h = s.idempotent(h)
+	}
+	if s.duplicates != nil {
+		h = s.rejectDuplicates(h)
+	}
+	// Writes are refused after authentication, so anonymous clients learn
+	// nothing about the server's state
+	h = s.rejectWritesWhenReadOnly(h)
+	if s.calculatorOps != nil {
+		h = s.restrictOperations(h)
+	}
+	if s.validateUTF8 {
+		h = rejectInvalidUTF8(h)
+	}
+	// Signatures are checked after authentication, so unauthenticated
+	// clients are refused before their body is read
+	if s.signingKey != nil {
+		h = s.verifySignature(h)
+	}
+	if s.jwtKey != nil {
+		h = s.jwtAuth(h)
+	}
+	if len(s.apiKeys) > 0 {
+		h = s.apiKeyAuth(h)
+	}
+	if s.canary {
+		h = s.tagCanary(h)
+	}
+	if s.strictAccept {
+		h = negotiateContent(h)
+	}
+	h = validatePretty(h)
+	if s.httpsRedirect {
+		h = redirectHTTPS(h)
+	}
+	// Host is checked before the redirect, which echoes it back in Location
+	if s.allowedHosts != nil {
+		h = s.validateHost(h)
+	}
+	h = serverTiming(h)
+	if s.requestTimeout > 0 {
+		h = s.enforceDeadline(h)
+	}
+	if s.slowThreshold > 0 {
+		h = s.logSlowRequests(h)
+	}
+	if s.accessLog != nil {
+		h = s.logAccess(h)
+	}
+	h = s.recordErrors(h)
+	h = s.trackInFlight(h)
+
+	return h
+}
+
+// trackInFlight counts requests while they are being handled, so shutdown can
+// report how many it is waiting for
+func (s *Server) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// redirectHTTPS sends a 308 to the HTTPS URL for requests the proxy received
+// over plain HTTP. Requests without X-Forwarded-Proto reached us directly and
+// are passed through, as are health checks so load balancers can probe over HTTP.
+func redirectHTTPS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "http") && !isHealthCheck(r.URL.Path) {
+			http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateHost rejects requests whose Host header is not in the allowlist
+// with 400, guarding against Host header injection. Health checks are exempt
+// because load balancers often probe by IP address.
+func (s *Server) validateHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isHealthCheck(r.URL.Path) && !s.hostAllowed(r.Host) {
+			respondError(w, r, http.StatusBadRequest, "Invalid host")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validatePretty rejects requests whose pretty parameter is not a boolean
+// with 400, rather than silently sending compact JSON. The error itself is
+// compact, since respondJSON ignores the malformed value.
+func validatePretty(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := parseBoolParam(r, "pretty", false); err != nil {
+			respondError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hostAllowed reports whether host matches an allowlist entry, either exactly
+// (including the port) or by hostname alone for entries without a port
+func (s *Server) hostAllowed(host string) bool {
+	host = strings.ToLower(host)
+	if _, ok := s.allowedHosts[host]; ok {
+		return true
+	}
+
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return false
+	}
+	_, ok := s.allowedHosts[hostname]
+	return ok
+}
+
+// serverTiming reports how long the handler ran before responding in a
+// Server-Timing header, which browsers show in their developer tools
+func serverTiming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		sw.beforeHeader = func(h http.Header) {
+			ms := float64(time.Since(start)) / float64(time.Millisecond)
+			h.Set("Server-Timing", "total;dur="+strconv.FormatFloat(ms, 'f', 3, 64))
+		}
+
+		next.ServeHTTP(sw, r)
+		sw.finish()
+	})
+}
+
+// statusWriter wraps a ResponseWriter to capture the response status and
+// size, and to let middleware set headers at the last moment before they are sent
+type statusWriter struct {
+	http.ResponseWriter
+	status       int
+	size         int64
+	beforeHeader func(http.Header)
+}
+
+// WriteHeader records the status, running beforeHeader first
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if w.beforeHeader != nil {
+		w.beforeHeader(w.Header())
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write sends an implicit 200 status before the first body bytes
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish sends the headers of a response whose handler wrote nothing
+func (w *statusWriter) finish() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// isHealthCheck reports whether path belongs to the health check endpoints
+func isHealthCheck(path string) bool {
+	return path == "/health" || strings.HasPrefix(path, "/health/")
+}
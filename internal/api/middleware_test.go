@@ -0,0 +1,213 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHTTPSRedirect tests the X-Forwarded-Proto based HTTPS redirect
+func TestHTTPSRedirect(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), WithHTTPSRedirect())
+	handler := server.Router()
+
+	tests := []struct {
+		name             string
+		path             string
+		forwardedProto   string
+		expectedLocation string
+	}{
+		{"Forwarded http is redirected", "/calculator/add?a=1&b=2", "http", "https://example.com/calculator/add?a=1&b=2"},
+		{"Forwarded https passes through", "/calculator/add?a=1&b=2", "https", ""},
+		{"Direct request passes through", "/calculator/add?a=1&b=2", "", ""},
+		{"Health check is exempt", "/health", "http", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://example.com"+tc.path, nil)
+			if tc.forwardedProto != "" {
+				req.Header.Set("X-Forwarded-Proto", tc.forwardedProto)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if tc.expectedLocation != "" {
+				assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+			} else {
+				assert.NotEqual(t, http.StatusPermanentRedirect, rec.Code)
+			}
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+}
+
+// TestAllowedHosts tests that requests for hosts outside the allowlist are rejected
+func TestAllowedHosts(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(),
+		WithAllowedHosts("api.example.com", "localhost:8080", " Admin.Example.com ", ""), WithHTTPSRedirect())
+	handler := server.Router()
+
+	tests := []struct {
+		name           string
+		host           string
+		path           string
+		forwardedProto string
+		expectedStatus int
+	}{
+		{"Allowed host", "api.example.com", "/calculator/add?a=1&b=2", "", http.StatusOK},
+		{"Allowed host on any port", "api.example.com:8443", "/calculator/add?a=1&b=2", "", http.StatusOK},
+		{"Allowed host is case-insensitive", "API.Example.com", "/calculator/add?a=1&b=2", "", http.StatusOK},
+		{"Allowed host and port", "localhost:8080", "/calculator/add?a=1&b=2", "", http.StatusOK},
+		{"Entries are trimmed and lowercased", "admin.example.com", "/calculator/add?a=1&b=2", "", http.StatusOK},
+		{"Empty entries allow nothing", "", "/calculator/add?a=1&b=2", "", http.StatusBadRequest},
+		{"Other port of host:port entry", "localhost:9090", "/calculator/add?a=1&b=2", "", http.StatusBadRequest},
+		{"Spoofed host", "evil.example.com", "/calculator/add?a=1&b=2", "", http.StatusBadRequest},
+		{"Spoofed host is not redirected", "evil.example.com", "/calculator/add?a=1&b=2", "http", http.StatusBadRequest},
+		{"Health check is exempt", "10.0.0.1", "/health", "", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			req.Host = tc.host
+			if tc.forwardedProto != "" {
+				req.Header.Set("X-Forwarded-Proto", tc.forwardedProto)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusBadRequest {
+				assert.JSONEq(t, `{"error":"Invalid host"}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestServerTiming tests that responses report the handler duration in Server-Timing
+func TestServerTiming(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	mockRepo.On("ListUsers").Return([]*database.User{}, nil).Run(func(mock.Arguments) {
+		time.Sleep(2 * time.Millisecond)
+	})
+	server := NewServer(mockRepo, calculator.NewCalculator())
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		minDuration float64
+	}{
+		{"Slow handler", "GET", "/users", 2},
+		{"Error response", "GET", "/calculator/add?a=1", 0},
+		{"Empty response", "OPTIONS", "/users", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			// Fast requests may round to 0.000 milliseconds
+			header := rec.Header().Get("Server-Timing")
+			require.Regexp(t, `^total;dur=\d+\.\d{3}$`, header)
+
+			dur, err := strconv.ParseFloat(strings.TrimPrefix(header, "total;dur="), 64)
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, dur, tc.minDuration)
+		})
+	}
+}
+
+// TestSlowRequestLog tests that requests over the threshold are logged as
+// slow and faster ones are not
+func TestSlowRequestLog(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	mockRepo.On("ListUsers").Return([]*database.User{}, nil).Run(func(mock.Arguments) {
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	tests := []struct {
+		name         string
+		path         string
+		expectLogged bool
+	}{
+		{"Slow handler", "/users", true},
+		{"Fast handler", "/calculator/add?a=1&b=2", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&logs, nil))
+			server := NewServer(mockRepo, calculator.NewCalculator(),
+				WithLogger(logger), WithSlowRequestThreshold(10*time.Millisecond))
+
+			server.Router().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tc.path, nil))
+
+			if !tc.expectLogged {
+				assert.Empty(t, logs.String())
+				return
+			}
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+			assert.Equal(t, "WARN", entry["level"])
+			assert.Equal(t, "slow request", entry["msg"])
+			assert.Equal(t, "GET", entry["method"])
+			assert.Equal(t, "/users", entry["path"])
+			assert.EqualValues(t, http.StatusOK, entry["status"])
+			assert.GreaterOrEqual(t, entry["duration"], float64(20*time.Millisecond))
+		})
+	}
+}
+
+// TestRequestDeadline tests that the X-Deadline header reflects the request
+// timeout and that handlers see the same deadline in their context
+func TestRequestDeadline(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(),
+		WithRequestTimeout(30*time.Second), WithClock(func() time.Time { return now }))
+
+	var ctxDeadline time.Time
+	handler := server.enforceDeadline(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxDeadline, _ = r.Context().Deadline()
+	}))
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users", nil))
+
+	// The context is cancelled at exactly the deadline the header reports,
+	// timed by the real clock rather than the server's fake one
+	header, err := time.Parse(time.RFC3339Nano, rec.Header().Get("X-Deadline"))
+	require.NoError(t, err)
+	assert.True(t, header.Equal(ctxDeadline), "header %v, context deadline %v", header, ctxDeadline)
+	assert.WithinDuration(t, start.Add(30*time.Second), ctxDeadline, time.Second)
+
+	// The header is set on every route, and only when a timeout is configured
+	rec = httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, httptest.NewRequest("GET", "/calculator/add?a=1&b=2", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("X-Deadline"))
+
+	rec = httptest.NewRecorder()
+	NewServer(new(database.MockUserRepository), calculator.NewCalculator()).Router().
+		ServeHTTP(rec, httptest.NewRequest("GET", "/calculator/add?a=1&b=2", nil))
+	assert.Empty(t, rec.Header().Get("X-Deadline"))
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// negotiateContent responds 406 to requests whose Accept header does not
+// allow JSON, the only representation the API produces. Requests without an
+// Accept header accept anything.
+func negotiateContent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Values("Accept")
+		if len(accept) > 0 && !strings.HasPrefix(r.URL.Path, "/swagger/") && !acceptsJSON(strings.Join(accept, ",")) {
+			respondError(w, r, http.StatusNotAcceptable, "only application/json responses are available")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acceptsJSON reports whether an Accept header value gives application/json
+// a non-zero quality. The most specific matching media range decides, so
+// "*/*, application/json;q=0" rules JSON out.
+func acceptsJSON(accept string) bool {
+	specificity, quality := 0, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		var s int
+		switch strings.ToLower(strings.TrimSpace(mediaRange)) {
+		case "application/json":
+			s = 3
+		case "application/*":
+			s = 2
+		case "*/*":
+			s = 1
+		default:
+			continue
+		}
+		if s > specificity {
+			specificity, quality = s, qualityOf(params)
+		}
+	}
+	return quality > 0
+}
+
+// qualityOf returns the q parameter among the ;-separated params of a media
+// range, which defaults to 1
+func qualityOf(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(param, "=")
+		if strings.EqualFold(strings.TrimSpace(name), "q") {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return 0
+			}
+			return q
+		}
+	}
+	return 1
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestContentNegotiation tests responses to Accept headers in strict and
+// lenient modes
+func TestContentNegotiation(t *testing.T) {
+	tests := []struct {
+		name           string
+		strict         bool
+		path           string
+		accept         string
+		expectedStatus int
+	}{
+		{"Lenient unsupported type gets JSON", false, "/calculator/add?a=1&b=2", "application/pdf", http.StatusOK},
+		{"Strict unsupported type", true, "/calculator/add?a=1&b=2", "application/pdf", http.StatusNotAcceptable},
+		{"Strict JSON", true, "/calculator/add?a=1&b=2", "application/json", http.StatusOK},
+		{"Strict without Accept", true, "/calculator/add?a=1&b=2", "", http.StatusOK},
+		{"Strict wildcard", true, "/calculator/add?a=1&b=2", "text/html, */*;q=0.1", http.StatusOK},
+		{"Strict application wildcard", true, "/calculator/add?a=1&b=2", "application/*", http.StatusOK},
+		{"Strict JSON refused", true, "/calculator/add?a=1&b=2", "*/*, application/json;q=0", http.StatusNotAcceptable},
+		{"Strict documentation is exempt", true, "/swagger/index.html", "application/pdf", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []Option
+			if tc.strict {
+				opts = append(opts, WithStrictAccept())
+			}
+			handler := NewServer(database.NewUserRepository(), calculator.NewCalculator(), opts...).Router()
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusNotAcceptable {
+				assert.JSONEq(t, `{"error":"only application/json responses are available"}`, rec.Body.String())
+			} else if tc.path != "/swagger/index.html" {
+				assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+// OperandSource identifies where calculator handlers read operands from
+type OperandSource string
+
+const (
+	// OperandsFromQuery reads operands from the URL query string
+	OperandsFromQuery OperandSource = "query"
+	// OperandsFromJSON reads operands from a JSON object request body
+	OperandsFromJSON OperandSource = "json"
+	// OperandsFromForm reads operands from an application/x-www-form-urlencoded body
+	OperandsFromForm OperandSource = "form"
+)
+
+// defaultOperandSources is the precedence used unless WithOperandSources is given
+var defaultOperandSources = []OperandSource{OperandsFromQuery, OperandsFromJSON, OperandsFromForm}
+
+// operandValues returns the raw values of the named operands from the first
+// source, in precedence order, that provides all of them. If none does, the
+// first source with any values is returned so callers can report exactly
+// which operands are missing.
+func (s *Server) operandValues(r *http.Request, names ...string) url.Values {
+	var partial url.Values
+	for _, source := range s.operandSources {
+		values := readOperandSource(r, source)
+		if hasAll(values, names) {
+			return values
+		}
+		if partial == nil && len(values) > 0 {
+			partial = values
+		}
+	}
+
+	if partial == nil {
+		return url.Values{}
+	}
+	return partial
+}
+
+// readOperandSource extracts values from a single source. Body sources only
+// apply when the request's Content-Type matches them.
+func readOperandSource(r *http.Request, source OperandSource) url.Values {
+	switch source {
+	case OperandsFromQuery:
+		return r.URL.Query()
+	case OperandsFromJSON:
+		if mediaType(r) != "application/json" || r.Body == nil {
+			return nil
+		}
+		return readJSONOperands(r)
+	case OperandsFromForm:
+		if mediaType(r) != "application/x-www-form-urlencoded" {
+			return nil
+		}
+		if err := r.ParseForm(); err != nil {
+			return nil
+		}
+		return r.PostForm
+	}
+
+	return nil
+}
+
+// readJSONOperands decodes the number and string members of a JSON object
+// body into values
+func readJSONOperands(r *http.Request) url.Values {
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBufferedBodyBytes)).Decode(&body); err != nil {
+		return nil
+	}
+
+	values := url.Values{}
+	for name, raw := range body {
+		var number json.Number
+		if err := json.Unmarshal(raw, &number); err == nil {
+			values.Set(name, number.String())
+			continue
+		}
+		var str string
+		if err := json.Unmarshal(raw, &str); err == nil {
+			values.Set(name, str)
+		}
+	}
+
+	return values
+}
+
+// mediaType returns the request's Content-Type without parameters
+func mediaType(r *http.Request) string {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mt
+}
+
+func hasAll(values url.Values, names []string) bool {
+	if values == nil {
+		return false
+	}
+	for _, name := range names {
+		if values.Get(name) == "" {
+			return false
+		}
+	}
+	return true
+}
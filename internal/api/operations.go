@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// restrictOperations refuses calculator requests for operations not enabled
+// with WithCalculatorOperations with 403, so a restricted deployment can keep
+// serving the rest of the calculator
+func (s *Server) restrictOperations(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if op, ok := calculatorOperation(r.URL.Path); ok {
+			if _, enabled := s.calculatorOps[op]; !enabled {
+				respondError(w, r, http.StatusForbidden, fmt.Sprintf("operation %q is disabled", op))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// calculatorOperation returns the operation a calculator request performs,
+// named by its path: "divide" for /calculator/divide, "complex" for
+// /calculator/complex/{op} and the applied operation for
+// /calculator/sessions/{id}/{op}. Endpoints that compute nothing, such as
+// /calculator/properties, are not operations.
+func calculatorOperation(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/calculator/")
+	if !ok {
+		return "", false
+	}
+	segments := strings.Split(rest, "/")
+	switch segments[0] {
+	case "", "properties", "stats":
+		return "", false
+	case "sessions":
+		if len(segments) != 3 {
+			return "", false
+		}
+		return segments[2], true
+	default:
+		return segments[0], true
+	}
+}
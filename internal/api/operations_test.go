@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCalculatorOperations tests that only enabled operations are served
+func TestCalculatorOperations(t *testing.T) {
+	handler := NewServer(database.NewUserRepository(), calculator.NewCalculator(),
+		WithCalculatorOperations("add", "subtract", "multiply")).Router()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Disabled GET", "GET", "/calculator/divide?a=6&b=3", "", http.StatusForbidden, `{"error":"operation \"divide\" is disabled"}`},
+		{"Disabled POST", "POST", "/calculator/divide?a=6&b=3", "", http.StatusForbidden, `{"error":"operation \"divide\" is disabled"}`},
+		{"Disabled complex", "POST", "/calculator/complex/add", `{"a":{"re":1,"im":0},"b":{"re":2,"im":0}}`, http.StatusForbidden, `{"error":"operation \"complex\" is disabled"}`},
+		{"Enabled", "GET", "/calculator/add?a=6&b=3", "", http.StatusOK, `{"result":9}`},
+		{"Properties", "GET", "/calculator/properties?op=divide", "", http.StatusOK, ""},
+		{"Usage stats", "GET", "/calculator/stats/usage", "", http.StatusOK, ""},
+		{"User route", "GET", "/users", "", http.StatusOK, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestCalculatorOperation tests naming the operation a request performs
+func TestCalculatorOperation(t *testing.T) {
+	tests := []struct {
+		path       string
+		expectedOp string
+		expectedOK bool
+	}{
+		{"/calculator/divide", "divide", true},
+		{"/calculator/round-to-multiple", "round-to-multiple", true},
+		{"/calculator/complex/multiply", "complex", true},
+		{"/calculator/sessions/abc/divide", "divide", true},
+		{"/calculator/sessions/abc", "", false},
+		{"/calculator/sessions", "", false},
+		{"/calculator/properties", "", false},
+		{"/calculator/stats/usage", "", false},
+		{"/users/1", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			op, ok := calculatorOperation(tc.path)
+
+			assert.Equal(t, tc.expectedOp, op)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"maps"
+	"strings"
+	"time"
+
+	"go-testing/internal/calculator"
+)
+
+// Option configures optional Server behaviour
+type Option func(*Server)
+
+// WithHTTPSRedirect redirects plain-HTTP requests to HTTPS when the server
+// runs behind a proxy that sets the X-Forwarded-Proto header
+func WithHTTPSRedirect() Option {
+	return func(s *Server) {
+		s.httpsRedirect = true
+	}
+}
+
+// WithAllowedHosts rejects requests whose Host header matches none of hosts
+// with 400. An entry without a port allows that hostname on any port.
+// Entries are trimmed and matched case-insensitively, and empty ones are
+// ignored. Health checks are exempt.
+func WithAllowedHosts(hosts ...string) Option {
+	return func(s *Server) {
+		s.allowedHosts = make(map[string]struct{}, len(hosts))
+		for _, host := range hosts {
+			host = strings.ToLower(strings.TrimSpace(host))
+			if host == "" {
+				continue
+			}
+			s.allowedHosts[host] = struct{}{}
+		}
+	}
+}
+
+// WithUserListEnvelope makes GET /users return a UsersResponse object
+// ({"users":[...],"total":n}) instead of a bare array
+func WithUserListEnvelope() Option {
+	return func(s *Server) {
+		s.userListEnvelope = true
+	}
+}
+
+// WithPageEnvelope makes GET /users return a PagedUsersResponse
+// ({"data":[...],"page":{"offset":0,"limit":50,"total":n,"has_more":true}})
+// instead of a bare array. It takes precedence over WithUserListEnvelope.
+func WithPageEnvelope() Option {
+	return func(s *Server) {
+		s.pageEnvelope = true
+	}
+}
+
+// minSessionSweepInterval bounds how often idle sessions are swept, however
+// short their TTL
+const minSessionSweepInterval = time.Second
+
+// WithCalculatorSessions enables stateful calculator sessions. Sessions idle
+// for longer than idleTTL are evicted by a background goroutine that runs
+// until Shutdown is called. A TTL below 1 leaves sessions disabled.
+func WithCalculatorSessions(idleTTL time.Duration) Option {
+	return func(s *Server) {
+		if idleTTL <= 0 {
+			return
+		}
+		s.sessions = calculator.NewSessionStore(idleTTL)
+		s.sessions.Start(max(idleTTL/2, minSessionSweepInterval))
+	}
+}
+
+// WithJWTAuth requires requests to carry a bearer JWT signed with key using
+// HMAC-SHA2. Health checks and the API documentation stay public.
+func WithJWTAuth(key []byte) Option {
+	return func(s *Server) {
+		s.jwtKey = key
+	}
+}
+
+// WithRequestSigning requires requests to carry an X-Signature header with
+// the hex HMAC-SHA256 of their body under secret, as webhook senders do.
+// Health checks and the API documentation are exempt.
+func WithRequestSigning(secret []byte) Option {
+	return func(s *Server) {
+		s.signingKey = secret
+	}
+}
+
+// WithUTF8Validation rejects requests whose body is not valid UTF-8 with
+// 400, rather than letting JSON decoding replace the invalid bytes
+func WithUTF8Validation() Option {
+	return func(s *Server) {
+		s.validateUTF8 = true
+	}
+}
+
+// WithOperandSources sets where calculator handlers read operands from and in
+// which order the sources are tried. The first source providing every operand
+// wins. The default is query, then JSON body, then form body.
+func WithOperandSources(sources ...OperandSource) Option {
+	return func(s *Server) {
+		s.operandSources = sources
+	}
+}
+
+// WithOperandNames renames the two operands calculator endpoints read, for
+// clients that prefer names such as x and y to the default a and b
+func WithOperandNames(first, second string) Option {
+	return func(s *Server) {
+		s.operandNames = [2]string{first, second}
+	}
+}
+
+// WithStrictQuery rejects calculator requests carrying query parameters the
+// endpoint does not accept, such as a mistyped operand, with 400 and the
+// unexpected names. By default unknown parameters are ignored.
+func WithStrictQuery() Option {
+	return func(s *Server) {
+		s.strictQuery = true
+	}
+}
+
+// WithDisabledRoutes leaves routes out of the router, so requests for them
+// get 404 and one binary can expose different surface areas. Each route is
+// either a pattern as registered, such as "POST /calculator/divide", or a
+// path such as "/calculator/divide" to disable every method on it. A disabled
+// method on a path that keeps others gets 405 instead.
+func WithDisabledRoutes(routes ...string) Option {
+	return func(s *Server) {
+		s.disabledRoutes = make(map[string]struct{}, len(routes))
+		for _, route := range routes {
+			s.disabledRoutes[strings.TrimSpace(route)] = struct{}{}
+		}
+	}
+}
+
+// WithConcurrencyLimits bounds how many requests each route handles at once,
+// answering requests beyond the limit with 503 and Retry-After. Routes are
+// named as for WithDisabledRoutes, such as "POST /users", and each has its
+// own limit. Limits below 1 are ignored.
+func WithConcurrencyLimits(limits map[string]int) Option {
+	return func(s *Server) {
+		s.concurrencyLimits = make(map[string]int, len(limits))
+		for route, limit := range limits {
+			if limit >= 1 {
+				s.concurrencyLimits[strings.TrimSpace(route)] = limit
+			}
+		}
+	}
+}
+
+// WithCalculatorOperations enables only the named calculator operations, such
+// as "add" or "divide"; requests for any other get 403. Operations are named
+// by their path, with "complex" covering /calculator/complex/{op}, and apply
+// to session operations too. By default every operation is enabled.
+func WithCalculatorOperations(ops ...string) Option {
+	return func(s *Server) {
+		s.calculatorOps = make(map[string]struct{}, len(ops))
+		for _, op := range ops {
+			s.calculatorOps[strings.TrimSpace(op)] = struct{}{}
+		}
+	}
+}
+
+// WithStrictAccept rejects requests whose Accept header rules out JSON, such
+// as Accept: application/pdf, with 406 Not Acceptable. By default such
+// requests get JSON anyway. The API documentation is exempt.
+func WithStrictAccept() Option {
+	return func(s *Server) {
+		s.strictAccept = true
+	}
+}
+
+// WithCanary tags about percent of clients, bucketed by IP, as canary
+// traffic; see IsCanary. Clients can opt in or out with an X-Canary header.
+// percent is clamped to [0, 100].
+func WithCanary(percent int) Option {
+	return func(s *Server) {
+		s.canary = true
+		s.canaryPercent = min(max(percent, 0), 100)
+	}
+}
+
+// WithPrecision sets how many decimal places the results of calculator
+// operations are rounded to by default, keyed by operation name such as
+// "divide" or "add". Operations not in places are not rounded. Requests can
+// override it with the precision parameter. Places are clamped to [0, 15].
+func WithPrecision(places map[string]int) Option {
+	return func(s *Server) {
+		s.precision = maps.Clone(places)
+		for op, p := range s.precision {
+			s.precision[op] = min(max(p, 0), maxPrecision)
+		}
+	}
+}
+
+// WithPprof mounts the net/http/pprof profiling handlers under /debug/pprof/
+func WithPprof() Option {
+	return func(s *Server) {
+		s.pprof = true
+	}
+}
+
+// WithPageSizes sets the page size GET /users uses when no limit is given and
+// the largest limit a client may request. Larger limits are clamped.
+func WithPageSizes(defaultSize, maxSize int) Option {
+	return func(s *Server) {
+		s.defaultPageSize = defaultSize
+		s.maxPageSize = maxSize
+	}
+}
+
+// WithBuildInfo sets the version and commit reported by GET /health
+func WithBuildInfo(info BuildInfo) Option {
+	return func(s *Server) {
+		s.buildInfo = info
+	}
+}
+
+// WithClock replaces the clock the server uses to measure uptime, for tests
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// WithDrainTimeout bounds how long Shutdown waits for in-flight requests
+// before closing their connections. Zero waits for as long as the context
+// passed to Shutdown allows.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.drainTimeout = d
+	}
+}
+
+// WithRequestTimeout cancels the context of every request once timeout has
+// passed and reports the deadline to clients in the X-Deadline header.
+// A timeout below 1 disables it.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.requestTimeout = timeout
+	}
+}
+
+// WithLogger logs server lifecycle events, such as how many requests were
+// in flight when shutdown began
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithSlowRequestThreshold sets how long a request may take before a warning
+// with its method, path and duration is written to the logger, by default
+// DefaultSlowRequestThreshold. A threshold below 1 disables the warnings.
+func WithSlowRequestThreshold(threshold time.Duration) Option {
+	return func(s *Server) {
+		s.slowThreshold = threshold
+	}
+}
+
+// WithAccessLog writes a Common Log Format line to w for every request
+func WithAccessLog(w io.Writer) Option {
+	return func(s *Server) {
+		s.accessLog = &accessLog{w: w}
+	}
+}
+
+// WithAuditLog appends a JSON line to w for every user created, updated or
+// deleted, recording when, by whom and in which request. Requests are given
+// an ID in the X-Request-ID header unless the client sent one.
+func WithAuditLog(w io.Writer) Option {
+	return func(s *Server) {
+		s.auditLog = newAuditLog(w)
+	}
+}
+
+// WithAPIKeyRoles grants roles to the identities of API keys configured with
+// WithAPIKeys, keyed by identity. Without a role, an API key cannot reach
+// routes restricted to one, such as deleting users.
+func WithAPIKeyRoles(roles map[string][]string) Option {
+	return func(s *Server) {
+		s.apiKeyRoles = roles
+	}
+}
+
+// WithBulkWorkers sets how many users a bulk request validates and creates
+// concurrently
+func WithBulkWorkers(n int) Option {
+	return func(s *Server) {
+		s.bulkWorkers = n
+	}
+}
+
+// WithHealthCheckers registers checkers reported by /health/detailed
+func WithHealthCheckers(checkers ...HealthChecker) Option {
+	return func(s *Server) {
+		for _, checker := range checkers {
+			s.RegisterHealthChecker(checker)
+		}
+	}
+}
+
+// WithAPIKeys requires every request except health checks, documentation and
+// OPTIONS to carry one of the keys of keys in its X-API-Key header. Each key
+// maps to the identity IdentityFromContext reports for its requests. Empty
+// keys are ignored.
+func WithAPIKeys(keys map[string]string) Option {
+	return func(s *Server) {
+		if s.apiKeys == nil {
+			s.apiKeys = map[string]string{}
+		}
+		for key, identity := range keys {
+			if key = strings.TrimSpace(key); key != "" {
+				s.apiKeys[key] = identity
+			}
+		}
+	}
+}
+
+// WithIdempotency lets clients retry POST, PUT, PATCH and DELETE requests
+// safely by sending an Idempotency-Key header. Responses are replayed for
+// ttl, or DefaultIdempotencyTTL if ttl is not positive.
+func WithIdempotency(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.idempotency = newIdempotencyStore(ttl)
+	}
+}
+
+// WithDuplicateDetection refuses a POST creating users with 409 when the same
+// client sent an identical body to the same URI within window, guarding
+// against double submits without an Idempotency-Key. A window below 1
+// disables detection.
+func WithDuplicateDetection(window time.Duration) Option {
+	return func(s *Server) {
+		if window <= 0 {
+			s.duplicates = nil
+			return
+		}
+		s.duplicates = newDuplicateStore(window)
+	}
+}
+
+// WithResponseCache caches up to size successful GET responses under /users
+// for ttl, keyed by path and query. Any POST, PUT, PATCH or DELETE under
+// /users empties the cache. A ttl or size below 1 disables caching.
+func WithResponseCache(ttl time.Duration, size int) Option {
+	return func(s *Server) {
+		if ttl <= 0 || size <= 0 {
+			s.responseCache = nil
+			return
+		}
+		s.responseCache = newResponseCache(ttl, size)
+	}
+}
+
+// WithRecentErrors sets how many server errors /admin/errors keeps, instead
+// of DefaultRecentErrors. Zero disables recording them.
+func WithRecentErrors(n int) Option {
+	return func(s *Server) {
+		s.recentErrors = newErrorRing(max(n, 0))
+	}
+}
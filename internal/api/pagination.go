@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/database"
+)
+
+const (
+	// DefaultPageSize is the number of users returned when no limit is given
+	DefaultPageSize = 50
+	// DefaultMaxPageSize is the largest limit honoured; larger ones are clamped
+	DefaultMaxPageSize = 200
+)
+
+// page is a window onto a list, as requested through limit and offset.
+// A limit of zero means the window is unbounded.
+type page struct {
+	limit   int
+	offset  int
+	clamped bool
+}
+
+// parsePage reads the limit and offset query parameters. Without a limit the
+// server's default page size applies, so listing never returns everything by
+// accident; callers that want every user pass limit=0.
+func (s *Server) parsePage(r *http.Request) (p page, err error) {
+	query := r.URL.Query()
+
+	p.limit = s.defaultPageSize
+	if query.Has("limit") {
+		if p.limit, err = parseNonNegativeInt(query.Get("limit"), "limit"); err != nil {
+			return page{}, err
+		}
+	}
+	if query.Has("offset") {
+		if p.offset, err = parseNonNegativeInt(query.Get("offset"), "offset"); err != nil {
+			return page{}, err
+		}
+	}
+
+	if p.limit > s.maxPageSize {
+		p.limit = s.maxPageSize
+		p.clamped = true
+	}
+
+	return p, nil
+}
+
+// apply returns the users that fall within the page
+func (p page) apply(users []*database.User) []*database.User {
+	if p.offset >= len(users) {
+		return []*database.User{}
+	}
+	if p.limit == 0 {
+		return users[p.offset:]
+	}
+	end := min(p.offset+p.limit, len(users))
+	return users[p.offset:end]
+}
+
+// info describes the page for a client rendering pagination controls, given
+// how many users it holds and how many there are in all
+func (p page) info(size, total int) definitions.PageInfo {
+	return definitions.PageInfo{
+		Offset:  p.offset,
+		Limit:   p.limit,
+		Total:   total,
+		HasMore: p.offset+size < total,
+	}
+}
+
+// errInvalidCursor is reported for cursors the server did not hand out
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor returns the cursor resuming a listing after the user with id.
+// Cursors are opaque to clients, so their encoding may change.
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+// decodeCursor returns the last user ID encoded in cursor. The empty cursor
+// starts from the first user.
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	id, err := strconv.Atoi(string(raw))
+	if err != nil || id < 0 {
+		return 0, errInvalidCursor
+	}
+	return id, nil
+}
+
+// listUsersByCursor serves GET /users?cursor=, returning the page of users
+// after the cursor along with the cursor for the next page. Unlike offsets,
+// cursors neither skip nor repeat users created or deleted between pages.
+func (s *Server) listUsersByCursor(w http.ResponseWriter, r *http.Request, pg page, view string) {
+	if pg.offset != 0 {
+		respondError(w, r, http.StatusBadRequest, "cursor and offset cannot be combined")
+		return
+	}
+	after, err := decodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// One extra user tells whether there is a next page
+	fetch := pg.limit
+	if fetch > 0 {
+		fetch++
+	}
+	users, err := s.users(r).ListUsersAfter(after, fetch)
+	if err != nil {
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		respondError(w, r, http.StatusInternalServerError, "Error retrieving users")
+		return
+	}
+	if pg.clamped {
+		w.Header().Set("X-Limit-Clamped", strconv.Itoa(pg.limit))
+	}
+
+	var next string
+	if pg.limit > 0 && len(users) > pg.limit {
+		users = users[:pg.limit]
+		next = encodeCursor(users[len(users)-1].ID)
+	}
+
+	fields := parseFields(r)
+	switch {
+	case view == "summary":
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{"users": summarizeUsers(users), "next_cursor": next})
+	case fields != nil:
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{"users": projectUsers(users, fields), "next_cursor": next})
+	default:
+		resp := definitions.UsersPage{NextCursor: next, Users: newUsersResponse(users, 0).Users}
+		respondJSON(w, r, http.StatusOK, resp)
+	}
+}
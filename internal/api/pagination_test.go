@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedUsers returns n users with IDs 1..n
+func seedUsers(n int) []*database.User {
+	users := make([]*database.User, 0, n)
+	for i := 1; i <= n; i++ {
+		users = append(users, &database.User{ID: i, Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i)})
+	}
+	return users
+}
+
+// TestListUsersPagination tests limit/offset paging, clamping and the clamp header
+func TestListUsersPagination(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		opts           []Option
+		expectedStatus int
+		expectedIDs    []int
+		expectedClamp  string
+	}{
+		{"No params returns the default page", "/users", []Option{WithPageSizes(4, 5)}, http.StatusOK, []int{1, 2, 3, 4}, ""},
+		{"Zero limit is unbounded", "/users?limit=0", []Option{WithPageSizes(4, 5)}, http.StatusOK, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ""},
+		{"Zero limit with offset", "/users?limit=0&offset=7", []Option{WithPageSizes(4, 5)}, http.StatusOK, []int{8, 9, 10}, ""},
+		{"Limit and offset", "/users?limit=3&offset=2", nil, http.StatusOK, []int{3, 4, 5}, ""},
+		{"Offset past the end", "/users?offset=20", nil, http.StatusOK, []int{}, ""},
+		{"Offset uses default size", "/users?offset=1", []Option{WithPageSizes(2, 5)}, http.StatusOK, []int{2, 3}, ""},
+		{"Limit above max is clamped", "/users?limit=8", []Option{WithPageSizes(2, 5)}, http.StatusOK, []int{1, 2, 3, 4, 5}, "5"},
+		{"Limit at max is not clamped", "/users?limit=5", []Option{WithPageSizes(2, 5)}, http.StatusOK, []int{1, 2, 3, 4, 5}, ""},
+		{"Invalid limit", "/users?limit=abc", nil, http.StatusBadRequest, nil, ""},
+		{"Negative limit", "/users?limit=-1", nil, http.StatusBadRequest, nil, ""},
+		{"Negative offset", "/users?offset=-1", nil, http.StatusBadRequest, nil, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			mockRepo.On("ListUsers").Return(seedUsers(10), nil).Maybe()
+			server := NewServer(mockRepo, calculator.NewCalculator(), tc.opts...)
+
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedClamp, rec.Header().Get("X-Limit-Clamped"))
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var users []*database.User
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&users))
+			ids := make([]int, 0, len(users))
+			for _, user := range users {
+				ids = append(ids, user.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+// TestListUsersPaginationEnvelope tests that the envelope total counts all users, not the page
+func TestListUsersPaginationEnvelope(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	mockRepo.On("ListUsers").Return(seedUsers(3), nil)
+	server := NewServer(mockRepo, calculator.NewCalculator(), WithUserListEnvelope())
+
+	req := httptest.NewRequest("GET", "/users?limit=1&offset=1", nil)
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"users":[{"id":2,"username":"user2","email":"user2@example.com"}],"total":3}`, rec.Body.String())
+}
+
+// TestListUsersCursor tests that traversing every page by cursor visits each
+// user exactly once, even when a user is created mid-traversal
+func TestListUsersCursor(t *testing.T) {
+	repo := database.NewUserRepository(seedUsers(5)...)
+	handler := NewServer(repo, calculator.NewCalculator()).Router()
+
+	var ids []int
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "traversal did not end")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users?limit=2&cursor="+cursor, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp definitions.UsersPage
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		for _, user := range resp.Users {
+			ids = append(ids, user.ID)
+		}
+		if pages == 0 {
+			// Offsets would repeat a user after this insert shifted the list
+			require.NoError(t, repo.CreateUser(&database.User{Username: "late", Email: "late@example.com"}))
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, ids)
+}
+
+// TestListUsersCursorErrors tests cursor requests that are rejected
+func TestListUsersCursorErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectedError string
+	}{
+		{"Not base64", "/users?cursor=!!", "invalid cursor"},
+		{"Not an ID", "/users?cursor=" + base64.RawURLEncoding.EncodeToString([]byte("abc")), "invalid cursor"},
+		{"Negative ID", "/users?cursor=" + base64.RawURLEncoding.EncodeToString([]byte("-1")), "invalid cursor"},
+		{"With offset", "/users?cursor=&offset=2", "cursor and offset cannot be combined"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewServer(new(database.MockUserRepository), calculator.NewCalculator()).Router()
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", tc.url, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, fmt.Sprintf(`{"error":%q}`, tc.expectedError), rec.Body.String())
+		})
+	}
+}
+
+// TestListUsersPageEnvelope tests the page metadata on the first, middle and
+// last pages and beyond
+func TestListUsersPageEnvelope(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		expectedIDs  []int
+		expectedPage definitions.PageInfo
+	}{
+		{"First page", "/users?limit=2", []int{1, 2}, definitions.PageInfo{Offset: 0, Limit: 2, Total: 5, HasMore: true}},
+		{"Middle page", "/users?limit=2&offset=2", []int{3, 4}, definitions.PageInfo{Offset: 2, Limit: 2, Total: 5, HasMore: true}},
+		{"Last page", "/users?limit=2&offset=4", []int{5}, definitions.PageInfo{Offset: 4, Limit: 2, Total: 5, HasMore: false}},
+		{"Exactly filled last page", "/users?limit=5", []int{1, 2, 3, 4, 5}, definitions.PageInfo{Offset: 0, Limit: 5, Total: 5, HasMore: false}},
+		{"Past the end", "/users?limit=2&offset=9", []int{}, definitions.PageInfo{Offset: 9, Limit: 2, Total: 5, HasMore: false}},
+		{"Unbounded", "/users?limit=0&offset=1", []int{2, 3, 4, 5}, definitions.PageInfo{Offset: 1, Limit: 0, Total: 5, HasMore: false}},
+		{"Default size", "/users", []int{1, 2, 3}, definitions.PageInfo{Offset: 0, Limit: 3, Total: 5, HasMore: true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			mockRepo.On("ListUsers").Return(seedUsers(5), nil)
+			server := NewServer(mockRepo, calculator.NewCalculator(), WithPageEnvelope(), WithPageSizes(3, 10))
+
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, httptest.NewRequest("GET", tc.url, nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var resp struct {
+				Data []*database.User     `json:"data"`
+				Page definitions.PageInfo `json:"page"`
+			}
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			ids := make([]int, 0, len(resp.Data))
+			for _, user := range resp.Data {
+				ids = append(ids, user.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+			assert.Equal(t, tc.expectedPage, resp.Page)
+		})
+	}
+}
+
+// TestListUsersPageEnvelopeSummary tests that projections are wrapped too
+func TestListUsersPageEnvelopeSummary(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	mockRepo.On("ListUsers").Return(seedUsers(2), nil)
+	server := NewServer(mockRepo, calculator.NewCalculator(), WithPageEnvelope(), WithUserListEnvelope())
+
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, httptest.NewRequest("GET", "/users?view=summary&limit=1", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":[{"id":1,"username":"user1"}],"page":{"offset":0,"limit":1,"total":2,"has_more":true}}`, rec.Body.String())
+}
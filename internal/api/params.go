@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// paramType is the type a request parameter is parsed as
+type paramType int
+
+const (
+	// numberParam is a finite float64
+	numberParam paramType = iota
+	// intParam is an int
+	intParam
+	// stringParam is any non-empty string
+	stringParam
+	// boolParam is a bool written in any form parseBool accepts
+	boolParam
+)
+
+// paramSpec declares a parameter an endpoint accepts
+type paramSpec struct {
+	Name     string
+	Type     paramType
+	Required bool
+}
+
+// params holds the typed values of parsed parameters
+type params struct {
+	numbers map[string]float64
+	ints    map[string]int
+	strings map[string]string
+	bools   map[string]bool
+}
+
+// Float returns the value of a number parameter, or 0 if it was omitted
+func (p params) Float(name string) float64 {
+	return p.numbers[name]
+}
+
+// Int returns the value of an int parameter, or 0 if it was omitted
+func (p params) Int(name string) int {
+	return p.ints[name]
+}
+
+// String returns the value of a string parameter, or "" if it was omitted
+func (p params) String(name string) string {
+	return p.strings[name]
+}
+
+// Bool returns the value of a bool parameter, or false if it was omitted
+func (p params) Bool(name string) bool {
+	return p.bools[name]
+}
+
+// Has reports whether an optional parameter was provided
+func (p params) Has(name string) bool {
+	_, isNumber := p.numbers[name]
+	_, isInt := p.ints[name]
+	_, isString := p.strings[name]
+	_, isBool := p.bools[name]
+	return isNumber || isInt || isString || isBool
+}
+
+// parseParams reads the parameters declared by specs from the request's
+// operand sources, returning an error with a consistent message for the
+// first one that is missing or malformed
+func (s *Server) parseParams(r *http.Request, specs ...paramSpec) (params, error) {
+	var names, required []string
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+		if spec.Required {
+			required = append(required, spec.Name)
+		}
+	}
+	if err := s.checkQueryParams(r, names...); err != nil {
+		return params{}, err
+	}
+	values := s.operandValues(r, required...)
+
+	p := params{numbers: map[string]float64{}, ints: map[string]int{}, strings: map[string]string{}, bools: map[string]bool{}}
+	for _, spec := range specs {
+		raw := values.Get(spec.Name)
+		if raw == "" {
+			if spec.Required {
+				return params{}, fmt.Errorf("%s is required", spec.Name)
+			}
+			continue
+		}
+
+		switch spec.Type {
+		case numberParam:
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return params{}, fmt.Errorf("%s must be a number", spec.Name)
+			}
+			p.numbers[spec.Name] = v
+		case intParam:
+			v, err := strconv.Atoi(raw)
+			if err != nil {
+				return params{}, fmt.Errorf("%s must be an integer", spec.Name)
+			}
+			p.ints[spec.Name] = v
+		case stringParam:
+			p.strings[spec.Name] = raw
+		case boolParam:
+			v, err := parseBool(raw, spec.Name)
+			if err != nil {
+				return params{}, err
+			}
+			p.bools[spec.Name] = v
+		}
+	}
+
+	return p, nil
+}
+
+// globalQueryParams are accepted by every endpoint
+var globalQueryParams = []string{"pretty", "precision"}
+
+// checkQueryParams returns an error listing the query parameters other than
+// names and globalQueryParams when the server is in strict mode
+func (s *Server) checkQueryParams(r *http.Request, names ...string) error {
+	if !s.strictQuery {
+		return nil
+	}
+
+	var unexpected []string
+	for name := range r.URL.Query() {
+		if !slices.Contains(names, name) && !slices.Contains(globalQueryParams, name) {
+			unexpected = append(unexpected, name)
+		}
+	}
+	if len(unexpected) == 0 {
+		return nil
+	}
+
+	slices.Sort(unexpected)
+	return fmt.Errorf("unexpected query parameters: %s", strings.Join(unexpected, ", "))
+}
+
+// parseBoolParam returns the value of the boolean query parameter name, or
+// def if it is absent or empty
+func parseBoolParam(r *http.Request, name string, def bool) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	return parseBool(raw, name)
+}
+
+// parseBool parses s as true, false, 1, 0, yes or no in any case, returning
+// an error naming the value if it is anything else
+func parseBool(s, name string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "1", "yes":
+		return true, nil
+	case "false", "0", "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s must be true, false, 1, 0, yes or no", name)
+	}
+}
+
+// parsePositiveInt parses s as an integer greater than zero, such as an ID,
+// returning an error naming the value if it is anything else
+func parsePositiveInt(s, name string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return v, nil
+}
+
+// parseNonNegativeInt is like parsePositiveInt but also accepts zero, for
+// values such as offsets where zero is meaningful
+func parseNonNegativeInt(s, name string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return v, nil
+}
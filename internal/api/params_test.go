@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseParams tests the shared parameter parser
+func TestParseParams(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	specs := []paramSpec{
+		{Name: "a", Type: numberParam, Required: true},
+		{Name: "n", Type: intParam, Required: true},
+		{Name: "epsilon", Type: numberParam},
+		{Name: "mode", Type: stringParam},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedError string
+	}{
+		{"Valid", "a=1.5&n=3", ""},
+		{"Valid with optional", "a=1.5&n=3&epsilon=0.1", ""},
+		{"Valid with string", "a=1.5&n=3&mode=floor", ""},
+		{"Missing required", "n=3", "a is required"},
+		{"Malformed number", "a=abc&n=3", "a must be a number"},
+		{"Non-finite number", "a=NaN&n=3", "a must be a number"},
+		{"Malformed integer", "a=1&n=3.5", "n must be an integer"},
+		{"Malformed optional", "a=1&n=3&epsilon=tiny", "epsilon must be a number"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/?"+tc.query, nil)
+
+			p, err := server.parseParams(req, specs...)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1.5, p.Float("a"))
+			assert.Equal(t, 3, p.Int("n"))
+			assert.Equal(t, tc.query == "a=1.5&n=3&epsilon=0.1", p.Has("epsilon"))
+			assert.Equal(t, tc.query == "a=1.5&n=3&mode=floor", p.Has("mode"))
+			if p.Has("mode") {
+				assert.Equal(t, "floor", p.String("mode"))
+			}
+		})
+	}
+}
+
+// TestParamErrorsAcrossEndpoints tests that endpoints report parameter errors consistently
+func TestParamErrorsAcrossEndpoints(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		url           string
+		expectedError string
+	}{
+		{"/calculator/add?b=1", "a is required"},
+		{"/calculator/divide?a=1&b=x", "b must be a number"},
+		{"/calculator/almost-equal?a=1", "b is required"},
+		{"/calculator/fibonacci?n=ten", "n must be an integer"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			var response map[string]string
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, tc.expectedError, response["error"])
+		})
+	}
+}
+
+// TestStrictQuery tests that unknown query parameters are rejected only in
+// strict mode
+func TestStrictQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		strict         bool
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Lenient by default", false, "/calculator/add?a=2&b=3&cc=9", http.StatusOK, `{"result":5}`},
+		{"Unexpected parameters", true, "/calculator/add?a=2&b=3&cc=9&aa=1", http.StatusBadRequest, `{"error":"unexpected query parameters: aa, cc"}`},
+		{"Known parameters", true, "/calculator/round?a=2.5&places=0&mode=floor", http.StatusOK, `{"result":2}`},
+		{"Pretty is always accepted", true, "/calculator/add?a=2&b=3&pretty=false", http.StatusOK, `{"result":5}`},
+		{"Endpoint specific parameter", true, "/calculator/divide?a=1&b=4&as=decimal", http.StatusOK, `{"result":0.25}`},
+		{"Fraction division", true, "/calculator/divide?a=1&b=3&as=fraction&x=1", http.StatusBadRequest, `{"error":"unexpected query parameters: x"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []Option
+			if tc.strict {
+				opts = append(opts, WithStrictQuery())
+			}
+			server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), opts...)
+
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, httptest.NewRequest("GET", tc.url, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestParsePositiveInt tests the shared integer parsers
+func TestParsePositiveInt(t *testing.T) {
+	tests := []struct {
+		name                string
+		input               string
+		expectedPositive    int
+		expectedPositiveErr string
+		expectedNonNeg      int
+		expectedNonNegErr   string
+	}{
+		{"Empty", "", 0, "id must be a positive integer", 0, "id must be a non-negative integer"},
+		{"Negative", "-3", 0, "id must be a positive integer", 0, "id must be a non-negative integer"},
+		{"Zero", "0", 0, "id must be a positive integer", 0, ""},
+		{"Non-numeric", "abc", 0, "id must be a positive integer", 0, "id must be a non-negative integer"},
+		{"Fractional", "1.5", 0, "id must be a positive integer", 0, "id must be a non-negative integer"},
+		{"Valid", "42", 42, "", 42, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := parsePositiveInt(tc.input, "id")
+			if tc.expectedPositiveErr != "" {
+				assert.EqualError(t, err, tc.expectedPositiveErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedPositive, v)
+
+			v, err = parseNonNegativeInt(tc.input, "id")
+			if tc.expectedNonNegErr != "" {
+				assert.EqualError(t, err, tc.expectedNonNegErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedNonNeg, v)
+		})
+	}
+}
+
+// TestParseBoolParam tests each accepted form of a boolean query parameter
+func TestParseBoolParam(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		def           bool
+		expected      bool
+		expectedError string
+	}{
+		{"true", "flag=true", false, true, ""},
+		{"TRUE", "flag=TRUE", false, true, ""},
+		{"1", "flag=1", false, true, ""},
+		{"yes", "flag=yes", false, true, ""},
+		{"Yes", "flag=Yes", false, true, ""},
+		{"false", "flag=false", true, false, ""},
+		{"False", "flag=False", true, false, ""},
+		{"0", "flag=0", true, false, ""},
+		{"no", "flag=no", true, false, ""},
+		{"NO", "flag=NO", true, false, ""},
+		{"Absent uses default", "", true, true, ""},
+		{"Empty uses default", "flag=", true, true, ""},
+		{"Invalid", "flag=maybe", false, false, "flag must be true, false, 1, 0, yes or no"},
+		{"Invalid number", "flag=2", false, false, "flag must be true, false, 1, 0, yes or no"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/?"+tc.query, nil)
+
+			v, err := parseBoolParam(req, "flag", tc.def)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, v)
+		})
+	}
+}
+
+// TestBoolParamsAcrossEndpoints tests that boolean parameters accept the
+// same forms everywhere and reject anything else with 400
+func TestBoolParamsAcrossEndpoints(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Pretty yes", "/calculator/add?a=2&b=3&pretty=yes", http.StatusOK, "{\n  \"result\": 5\n}\n"},
+		{"Pretty 0", "/calculator/add?a=2&b=3&pretty=0", http.StatusOK, "{\"result\":5}\n"},
+		{"Invalid pretty", "/calculator/add?a=2&b=3&pretty=loud", http.StatusBadRequest,
+			"{\"error\":\"pretty must be true, false, 1, 0, yes or no\"}\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, httptest.NewRequest("GET", tc.url, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestInvalidUserID tests that malformed user IDs are rejected uniformly
+func TestInvalidUserID(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	for _, path := range []string{"/users/0", "/users/-1", "/users/abc"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", path, nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, `{"error":"id must be a positive integer"}`, rec.Body.String())
+		})
+	}
+}
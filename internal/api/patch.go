@@ -0,0 +1,146 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-testing/internal/database"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// jsonPatchMediaType is the Content-Type of an RFC 6902 JSON Patch document
+const jsonPatchMediaType = "application/json-patch+json"
+
+// patchUser godoc
+// @Summary Patch a user
+// @Description Apply an RFC 6902 JSON Patch to a user, such as
+// @Description [{"op":"replace","path":"/email","value":"new@example.com"}].
+// @Description Only the username and email fields may be patched; the result must be a valid user.
+// @Tags users
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param patch body []object true "JSON Patch operations"
+// @Success 200 {object} database.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /users/{id} [patch]
+func (s *Server) patchUser(w http.ResponseWriter, r *http.Request) {
+	id, err := extractIDFromPath(r.URL.Path)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if mediaType(r) != jsonPatchMediaType {
+		respondError(w, r, http.StatusUnsupportedMediaType, "Content-Type must be "+jsonPatchMediaType)
+		return
+	}
+
+	body, ok := bufferBody(w, r)
+	if !ok {
+		return
+	}
+	patch, err := jsonpatch.DecodePatch(body)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, "Invalid JSON Patch document")
+		return
+	}
+	if err := checkPatchPaths(patch); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := s.users(r).GetUser(id)
+	if err != nil {
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		respondError(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	patched, err := applyUserPatch(user, patch)
+	if err != nil {
+		respondError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	patched.ID = id
+	patched.Normalize()
+
+	if err := patched.Validate(); err != nil {
+		respondError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := s.users(r).UpdateUser(patched); err != nil {
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		if errors.Is(err, database.ErrEmailTaken) {
+			respondError(w, r, http.StatusConflict, "Email already in use")
+			return
+		}
+		respondError(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, patched)
+}
+
+// patchableFields are the JSON names of the user fields a patch may touch.
+// The ID is fixed by the path and counters change only by incrementing.
+var patchableFields = map[string]struct{}{"username": {}, "email": {}}
+
+// checkPatchPaths rejects operations that touch anything but a patchable
+// top-level user field
+func checkPatchPaths(patch jsonpatch.Patch) error {
+	for _, op := range patch {
+		path, err := op.Path()
+		if err != nil {
+			return errors.New("operation is missing a path")
+		}
+		paths := []string{path}
+		if op.Kind() == "move" || op.Kind() == "copy" {
+			from, err := op.From()
+			if err != nil {
+				return errors.New("operation is missing a from path")
+			}
+			paths = append(paths, from)
+		}
+
+		for _, path := range paths {
+			field, ok := strings.CutPrefix(path, "/")
+			if _, patchable := patchableFields[field]; !ok || !patchable {
+				return errors.New("cannot patch path " + path)
+			}
+		}
+	}
+	return nil
+}
+
+// applyUserPatch returns a copy of user with patch applied
+func applyUserPatch(user *database.User, patch jsonpatch.Patch) (*database.User, error) {
+	doc, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err = patch.Apply(doc)
+	if err != nil {
+		return nil, errors.New("patch could not be applied")
+	}
+
+	var patched database.User
+	if err := json.Unmarshal(doc, &patched); err != nil {
+		return nil, errors.New("patched user is malformed")
+	}
+	return &patched, nil
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestPatchUser tests applying JSON Patch documents to a user
+func TestPatchUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		patch          string
+		expectUpdate   bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Replace email", jsonPatchMediaType, `[{"op":"replace","path":"/email","value":"new@example.com"}]`, true,
+			http.StatusOK, `{"id":1,"username":"user1","email":"new@example.com"}`},
+		{"Test then replace", jsonPatchMediaType, `[{"op":"test","path":"/username","value":"user1"},{"op":"replace","path":"/username","value":"renamed"}]`, true,
+			http.StatusOK, `{"id":1,"username":"renamed","email":"user1@example.com"}`},
+		{"Replace id is rejected", jsonPatchMediaType, `[{"op":"replace","path":"/id","value":2}]`, false,
+			http.StatusBadRequest, `{"error":"cannot patch path /id"}`},
+		{"Copy from id is rejected", jsonPatchMediaType, `[{"op":"copy","from":"/id","path":"/username"}]`, false,
+			http.StatusBadRequest, `{"error":"cannot patch path /id"}`},
+		{"Counter is rejected", jsonPatchMediaType, `[{"op":"replace","path":"/login_count","value":100}]`, false,
+			http.StatusBadRequest, `{"error":"cannot patch path /login_count"}`},
+		{"Unknown path is rejected", jsonPatchMediaType, `[{"op":"add","path":"/admin","value":true}]`, false,
+			http.StatusBadRequest, `{"error":"cannot patch path /admin"}`},
+		{"Nested path is rejected", jsonPatchMediaType, `[{"op":"add","path":"/email/0","value":"x"}]`, false,
+			http.StatusBadRequest, `{"error":"cannot patch path /email/0"}`},
+		{"Failed test op", jsonPatchMediaType, `[{"op":"test","path":"/username","value":"someone"}]`, false,
+			http.StatusUnprocessableEntity, `{"error":"patch could not be applied"}`},
+		{"Result fails validation", jsonPatchMediaType, `[{"op":"replace","path":"/email","value":"not-an-email"}]`, false,
+			http.StatusUnprocessableEntity, `{"error":"invalid user: email is not a valid address"}`},
+		{"Malformed patch", jsonPatchMediaType, `{"op":"replace"}`, false,
+			http.StatusBadRequest, `{"error":"Invalid JSON Patch document"}`},
+		{"Wrong content type", "application/json", `[{"op":"replace","path":"/email","value":"new@example.com"}]`, false,
+			http.StatusUnsupportedMediaType, `{"error":"Content-Type must be application/json-patch+json"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server, mockRepo, _ := setupTestServer()
+			mockRepo.On("GetUser", 1).Return(&database.User{ID: 1, Username: "user1", Email: "user1@example.com"}, nil).Maybe()
+			if tc.expectUpdate {
+				mockRepo.On("UpdateUser", mock.AnythingOfType("*database.User")).Return(nil)
+			}
+
+			req := httptest.NewRequest("PATCH", "/users/1", strings.NewReader(tc.patch))
+			req.Header.Set("Content-Type", tc.contentType)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			if !tc.expectUpdate {
+				mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestPatchUserNotFound tests patching a user that does not exist
+func TestPatchUserNotFound(t *testing.T) {
+	server, mockRepo, _ := setupTestServer()
+	mockRepo.On("GetUser", 99).Return(nil, errors.New("user not found"))
+
+	req := httptest.NewRequest("PATCH", "/users/99", strings.NewReader(`[{"op":"replace","path":"/email","value":"new@example.com"}]`))
+	req.Header.Set("Content-Type", jsonPatchMediaType)
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	pkgcalculator "go-testing/pkg/calculator"
+)
+
+// maxPrecision is the most decimal places results can be rounded to
+const maxPrecision = 15
+
+// respondResult responds with the result of the calculator operation op,
+// rounded as roundResults describes
+func (s *Server) respondResult(w http.ResponseWriter, r *http.Request, op string, result float64) {
+	if !s.roundResults(w, r, op, &result) {
+		return
+	}
+	respondJSON(w, r, http.StatusOK, map[string]float64{"result": result})
+}
+
+// roundResults rounds the results of the calculator operation op in place,
+// half-up to the decimal places given by the request's precision parameter
+// or else the server's default precision for op. Without either, results are
+// left unrounded. A result that is infinite or NaN is reported as
+// errNonFiniteResult instead. Returns false if an error response was sent.
+func (s *Server) roundResults(w http.ResponseWriter, r *http.Request, op string, results ...*float64) bool {
+	for _, result := range results {
+		if math.IsInf(*result, 0) || math.IsNaN(*result) {
+			respondCalculationError(w, r, errNonFiniteResult)
+			return false
+		}
+	}
+	places, round := s.precision[op]
+	if raw := r.URL.Query().Get("precision"); raw != "" {
+		p, err := strconv.Atoi(raw)
+		if err != nil || p < 0 || p > maxPrecision {
+			respondError(w, r, http.StatusBadRequest, fmt.Sprintf("precision must be an integer between 0 and %d", maxPrecision))
+			return false
+		}
+		places, round = p, true
+	}
+	if !round {
+		return true
+	}
+
+	for _, result := range results {
+		rounded, err := s.pubCalc.RoundMode(*result, places, pkgcalculator.RoundHalfUp)
+		if err != nil {
+			respondCalculationError(w, r, err)
+			return false
+		}
+		*result = rounded
+	}
+	return true
+}
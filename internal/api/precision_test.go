@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPrecision tests default per-operation precision and the precision
+// parameter overriding it
+func TestPrecision(t *testing.T) {
+	server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(),
+		WithPrecision(map[string]int{"divide": 6, "multiply": 99}))
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Divide rounds by default", "/calculator/divide?a=2&b=3", http.StatusOK, `{"result":0.666667}`},
+		{"Add does not round", "/calculator/add?a=0.1&b=0.2", http.StatusOK, `{"result":0.30000000000000004}`},
+		{"Parameter overrides default", "/calculator/divide?a=2&b=3&precision=2", http.StatusOK, `{"result":0.67}`},
+		{"Parameter rounds other operations", "/calculator/add?a=0.1&b=0.2&precision=1", http.StatusOK, `{"result":0.3}`},
+		{"Zero places", "/calculator/divide?a=2&b=3&precision=0", http.StatusOK, `{"result":1}`},
+		{"Configured places are clamped", "/calculator/multiply?a=1.5&b=1", http.StatusOK, `{"result":1.5}`},
+		{"Round", "/calculator/round?a=2.345&places=3&precision=1", http.StatusOK, `{"result":2.3}`},
+		{"Round to multiple", "/calculator/round-to-multiple?a=1&multiple=0.3&precision=2", http.StatusOK, `{"result":0.9}`},
+		{"Invalid precision", "/calculator/add?a=1&b=2&precision=x", http.StatusBadRequest,
+			`{"error":"precision must be an integer between 0 and 15"}`},
+		{"Precision out of range", "/calculator/add?a=1&b=2&precision=16", http.StatusBadRequest,
+			`{"error":"precision must be an integer between 0 and 15"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, httptest.NewRequest("GET", tc.url, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+
+	t.Run("Variance rounds both fields", func(t *testing.T) {
+		for _, url := range []string{"/calculator/variance?precision=2", "/calculator/variance"} {
+			server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(),
+				WithPrecision(map[string]int{"variance": 2}))
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, httptest.NewRequest("POST", url, strings.NewReader(`{"values":[1,2,4]}`)))
+			assert.Equal(t, http.StatusOK, rec.Code, url)
+			assert.JSONEq(t, `{"variance":1.56,"stddev":1.25}`, rec.Body.String(), url)
+		}
+	})
+
+	t.Run("No rounding without configuration", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewServer(new(database.MockUserRepository), calculator.NewCalculator()).Router().
+			ServeHTTP(rec, httptest.NewRequest("GET", "/calculator/divide?a=2&b=3", nil))
+		assert.JSONEq(t, `{"result":0.6666666666666666}`, rec.Body.String())
+	})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+
+	"go-testing/api/definitions"
+)
+
+// readOnlyPath is the admin endpoint that toggles read-only mode, which
+// stays writable so read-only mode can be turned off again
+const readOnlyPath = "/admin/read-only"
+
+// SetReadOnly turns read-only mode on or off. While it is on, every POST,
+// PUT, PATCH and DELETE request is refused with 503 and reads keep working.
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.readOnly.Store(readOnly)
+}
+
+// ReadOnly reports whether the server is in read-only mode
+func (s *Server) ReadOnly() bool {
+	return s.readOnly.Load()
+}
+
+// rejectWritesWhenReadOnly refuses unsafe requests while the server is read-only
+func (s *Server) rejectWritesWhenReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.ReadOnly() && isUnsafeMethod(r.Method) && r.URL.Path != readOnlyPath {
+			respondError(w, r, http.StatusServiceUnavailable, "service is read-only")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getReadOnly godoc
+// @Summary Report read-only mode
+// @Description Report whether writes are currently refused. Requires the admin role, and is
+// @Description refused with 403 when the server has no authentication configured.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} definitions.ReadOnlyState
+// @Failure 403 {object} map[string]string
+// @Router /admin/read-only [get]
+func (s *Server) getReadOnly(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, http.StatusOK, definitions.ReadOnlyState{ReadOnly: s.ReadOnly()})
+}
+
+// setReadOnly godoc
+// @Summary Turn read-only mode on or off
+// @Description While read-only, every POST, PUT, PATCH and DELETE request except this one gets 503.
+// @Description Requires the admin role, and is refused with 403 when the server has no authentication configured.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param state body definitions.ReadOnlyState true "Whether to refuse writes"
+// @Success 200 {object} definitions.ReadOnlyState
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /admin/read-only [put]
+func (s *Server) setReadOnly(w http.ResponseWriter, r *http.Request) {
+	var state definitions.ReadOnlyState
+	if err := decodeJSON(w, r, &state); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+
+	s.SetReadOnly(state.ReadOnly)
+	s.logger.Info("read-only mode changed", "read_only", state.ReadOnly)
+	respondJSON(w, r, http.StatusOK, state)
+}
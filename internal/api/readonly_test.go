@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadOnly tests that writes are refused and reads served while the
+// server is read-only
+func TestReadOnly(t *testing.T) {
+	repo := database.NewUserRepository(&database.User{Username: "alice", Email: "alice@example.com"})
+	server := NewServer(repo, calculator.NewCalculator())
+	server.SetReadOnly(true)
+	handler := server.Router()
+
+	tests := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"GET", "/users/1", "", http.StatusOK},
+		{"GET", "/users", "", http.StatusOK},
+		{"GET", "/calculator/add?a=1&b=2", "", http.StatusOK},
+		{"POST", "/users", `{"username":"bob","email":"bob@example.com"}`, http.StatusServiceUnavailable},
+		{"PUT", "/users/1", `{"username":"alicia","email":"alice@example.com"}`, http.StatusServiceUnavailable},
+		{"PATCH", "/users/1", `{"username":"alicia"}`, http.StatusServiceUnavailable},
+		{"DELETE", "/users/1", "", http.StatusServiceUnavailable},
+		{"POST", "/calculator/dot", `{"a":[1],"b":[2]}`, http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusServiceUnavailable {
+				assert.JSONEq(t, `{"error":"service is read-only"}`, rec.Body.String())
+			}
+		})
+	}
+
+	users, err := repo.ListUsers()
+	require.NoError(t, err)
+	assert.Equal(t, []*database.User{{ID: 1, Username: "alice", Email: "alice@example.com"}}, users)
+}
+
+// TestReadOnlyToggle tests turning read-only mode on and off through the
+// admin endpoint, which only admins may use
+func TestReadOnlyToggle(t *testing.T) {
+	server := NewServer(database.NewUserRepository(), calculator.NewCalculator(), WithJWTAuth(testJWTKey))
+	handler := server.Router()
+
+	send := func(method, path, role, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		claims := jwt.MapClaims{"sub": "alice", "role": role, "exp": time.Now().Add(time.Hour).Unix()}
+		req.Header.Set("Authorization", "Bearer "+signToken(t, claims))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	const bob = `{"username":"bob","email":"bob@example.com"}`
+
+	assert.Equal(t, http.StatusForbidden, send("PUT", "/admin/read-only", "user", `{"read_only":true}`).Code)
+	assert.False(t, server.ReadOnly())
+
+	rec := send("PUT", "/admin/read-only", "admin", `{"read_only":true}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"read_only":true}`, rec.Body.String())
+	assert.True(t, server.ReadOnly())
+	assert.JSONEq(t, `{"read_only":true}`, send("GET", "/admin/read-only", "admin", "").Body.String())
+	assert.Equal(t, http.StatusServiceUnavailable, send("POST", "/users", "user", bob).Code)
+
+	// The toggle itself stays writable
+	assert.Equal(t, http.StatusOK, send("PUT", "/admin/read-only", "admin", `{"read_only":false}`).Code)
+	assert.Equal(t, http.StatusCreated, send("POST", "/users", "user", bob).Code)
+}
+
+// TestReadOnlyToggleWithoutAuth tests that the toggle is refused to everyone
+// when no authentication is configured
+func TestReadOnlyToggleWithoutAuth(t *testing.T) {
+	server := NewServer(database.NewUserRepository(), calculator.NewCalculator())
+	handler := server.Router()
+
+	for _, method := range []string{"GET", "PUT"} {
+		req := httptest.NewRequest(method, "/admin/read-only", strings.NewReader(`{"read_only":true}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+	assert.False(t, server.ReadOnly())
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go-testing/api/definitions"
+)
+
+// DefaultRecentErrors is how many server errors /admin/errors keeps unless
+// configured with WithRecentErrors
+const DefaultRecentErrors = 50
+
+// maxErrorMessage bounds how much of an error response body is kept
+const maxErrorMessage = 1024
+
+// errorRing is a fixed-size ring buffer of the most recent server errors
+type errorRing struct {
+	mutex   sync.Mutex
+	records []definitions.ErrorRecord
+	next    int // index the next record is written to
+	full    bool
+}
+
+func newErrorRing(size int) *errorRing {
+	return &errorRing{records: make([]definitions.ErrorRecord, size)}
+}
+
+// add records an error, overwriting the oldest once the ring is full
+func (e *errorRing) add(record definitions.ErrorRecord) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.records) == 0 {
+		return
+	}
+	e.records[e.next] = record
+	e.next = (e.next + 1) % len(e.records)
+	if e.next == 0 {
+		e.full = true
+	}
+}
+
+// list returns the recorded errors, newest first
+func (e *errorRing) list() []definitions.ErrorRecord {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	n := e.next
+	if e.full {
+		n = len(e.records)
+	}
+	records := make([]definitions.ErrorRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		records = append(records, e.records[(e.next-i+len(e.records))%len(e.records)])
+	}
+	return records
+}
+
+// recordErrors keeps the method, path and message of every 5xx response in
+// the server's ring of recent errors
+func (s *Server) recordErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ew := &errorCaptureWriter{}
+		ew.ResponseWriter = w
+
+		next.ServeHTTP(ew, r)
+		ew.finish()
+
+		if ew.status >= http.StatusInternalServerError {
+			s.recentErrors.add(definitions.ErrorRecord{
+				Time:    s.now(),
+				Method:  r.Method,
+				Path:    r.URL.Path,
+				Status:  ew.status,
+				Message: errorMessage(ew.body.Bytes()),
+			})
+		}
+	})
+}
+
+// errorCaptureWriter is a statusWriter that keeps the start of the body of
+// server error responses
+type errorCaptureWriter struct {
+	statusWriter
+	body bytes.Buffer
+}
+
+// Write sends b, keeping up to maxErrorMessage bytes of it for server errors
+func (w *errorCaptureWriter) Write(b []byte) (int, error) {
+	n, err := w.statusWriter.Write(b)
+	if w.status >= http.StatusInternalServerError && w.body.Len() < maxErrorMessage {
+		w.body.Write(b[:min(n, maxErrorMessage-w.body.Len())])
+	}
+	return n, err
+}
+
+// errorMessage extracts the message of an error response body, which is
+// the "error" member of respondError's JSON or else the body itself
+func errorMessage(body []byte) string {
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Error != "" {
+		return resp.Error
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// recentErrorsList godoc
+// @Summary List recent server errors
+// @Description List the most recent 5xx responses, newest first. Requires the admin role, and
+// @Description is refused with 403 when the server has no authentication configured.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} definitions.ErrorRecord
+// @Failure 403 {object} map[string]string
+// @Router /admin/errors [get]
+func (s *Server) recentErrorsList(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, http.StatusOK, s.recentErrors.list())
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getRecentErrors requests GET /admin/errors and decodes the response
+func getRecentErrors(t *testing.T, handler http.Handler) []definitions.ErrorRecord {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, asAdmin(httptest.NewRequest("GET", "/admin/errors", nil)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var records []definitions.ErrorRecord
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &records))
+	return records
+}
+
+// TestRecentErrors tests that server errors are recorded, newest first, and
+// that other responses are not
+func TestRecentErrors(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo := new(database.MockUserRepository)
+	handler := NewServer(mockRepo, calculator.NewCalculator(), WithClock(func() time.Time { return now }), withTestAdmin()).Router()
+
+	assert.Empty(t, getRecentErrors(t, handler))
+
+	mockRepo.On("ListUsers").Return(nil, assert.AnError).Once()
+	mockRepo.On("GetUser", 1).Return(nil, assert.AnError).Once()
+	mockRepo.On("GetUser", 2).Return(nil, database.ErrUserNotFound).Once()
+	for _, path := range []string{"/users", "/users/1", "/users/2"} {
+		handler.ServeHTTP(httptest.NewRecorder(), asAdmin(httptest.NewRequest("GET", path, nil)))
+		now = now.Add(time.Second)
+	}
+	mockRepo.AssertExpectations(t)
+
+	records := getRecentErrors(t, handler)
+	require.Len(t, records, 1, "only the ListUsers failure is a server error")
+	assert.Equal(t, definitions.ErrorRecord{
+		Time:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Method:  "GET",
+		Path:    "/users",
+		Status:  http.StatusInternalServerError,
+		Message: "Error retrieving users",
+	}, records[0])
+}
+
+// TestRecentErrorsBounded tests that only the most recent errors are kept
+// and that recording is safe under concurrent requests
+func TestRecentErrorsBounded(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	handler := NewServer(mockRepo, calculator.NewCalculator(), WithRecentErrors(2), withTestAdmin()).Router()
+	mockRepo.On("ListUsers").Return(nil, assert.AnError)
+
+	for i := range 3 {
+		handler.ServeHTTP(httptest.NewRecorder(), asAdmin(httptest.NewRequest("GET", fmt.Sprintf("/users?n=%d", i), nil)))
+	}
+	records := getRecentErrors(t, handler)
+	require.Len(t, records, 2)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), asAdmin(httptest.NewRequest("GET", "/users", nil)))
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, getRecentErrors(t, handler), 2)
+}
+
+// TestRecentErrorsWithoutAuth tests that recent errors, which may reveal
+// internal details, are refused to everyone when no authentication is configured
+func TestRecentErrorsWithoutAuth(t *testing.T) {
+	handler := NewServer(new(database.MockUserRepository), calculator.NewCalculator()).Router()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/errors", nil))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+// TestErrorRingOrder tests that the ring lists records newest first after
+// wrapping around
+func TestErrorRingOrder(t *testing.T) {
+	ring := newErrorRing(3)
+	for i := range 5 {
+		ring.add(definitions.ErrorRecord{Status: 500 + i})
+	}
+
+	var statuses []int
+	for _, record := range ring.list() {
+		statuses = append(statuses, record.Status)
+	}
+	assert.Equal(t, []int{504, 503, 502}, statuses)
+
+	assert.Empty(t, newErrorRing(0).list())
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"go-testing/internal/database"
+)
+
+// repoContextKey holds the request's caching view of the user repository
+const repoContextKey contextKey = "repo"
+
+// requestCachedRepository memoizes GetUser for the lifetime of one request,
+// so handlers that read the same user several times hit the repository once.
+// Writes pass straight through and forget the cached lookups they affect.
+type requestCachedRepository struct {
+	database.UserRepository
+
+	mutex sync.Mutex
+	users map[int]cachedLookup
+}
+
+// cachedLookup is the outcome of one GetUser call
+type cachedLookup struct {
+	user *database.User
+	err  error
+}
+
+// repoWithRequestCache returns a copy of ctx carrying a request-scoped
+// caching wrapper around repo, for handlers to fetch with Server.users
+func repoWithRequestCache(ctx context.Context, repo database.UserRepository) context.Context {
+	cached := &requestCachedRepository{UserRepository: repo, users: map[int]cachedLookup{}}
+	return context.WithValue(ctx, repoContextKey, database.UserRepository(cached))
+}
+
+// cacheRepoLookups gives every request its own cache of repository lookups
+func (s *Server) cacheRepoLookups(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(repoWithRequestCache(r.Context(), s.userRepo)))
+	})
+}
+
+// users returns the user repository handlers should use for r: the request's
+// caching wrapper when the middleware installed one, the repository otherwise
+func (s *Server) users(r *http.Request) database.UserRepository {
+	if repo, ok := r.Context().Value(repoContextKey).(database.UserRepository); ok {
+		return repo
+	}
+	return s.userRepo
+}
+
+// GetUser returns the user with the given ID, asking the repository only the
+// first time. Lookups that fail for reasons other than ErrUserNotFound are
+// not cached, so they are retried.
+func (c *requestCachedRepository) GetUser(id int) (*database.User, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if lookup, ok := c.users[id]; ok {
+		return lookup.user, lookup.err
+	}
+	user, err := c.UserRepository.GetUser(id)
+	if err == nil || errors.Is(err, database.ErrUserNotFound) {
+		c.users[id] = cachedLookup{user: user, err: err}
+	}
+	return user, err
+}
+
+// CreateUser creates the user and forgets any lookup of its new ID
+func (c *requestCachedRepository) CreateUser(user *database.User) error {
+	err := c.UserRepository.CreateUser(user)
+	c.forget(user.ID)
+	return err
+}
+
+// UpdateUser updates the user and forgets the cached lookup of it
+func (c *requestCachedRepository) UpdateUser(user *database.User) error {
+	err := c.UserRepository.UpdateUser(user)
+	c.forget(user.ID)
+	return err
+}
+
+// DeleteUser deletes the user and forgets the cached lookup of it
+func (c *requestCachedRepository) DeleteUser(id int) error {
+	err := c.UserRepository.DeleteUser(id)
+	c.forget(id)
+	return err
+}
+
+// UpdateUsers updates users in one call when the wrapped repository supports
+// it, and one at a time otherwise
+func (c *requestCachedRepository) UpdateUsers(users []*database.User) []error {
+	errs := updateUsers(c.UserRepository, users)
+	for _, user := range users {
+		c.forget(user.ID)
+	}
+	return errs
+}
+
+func (c *requestCachedRepository) forget(id int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.users, id)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestCachedRepository tests that duplicate lookups within a request
+// reach the repository once, and that writes are not hidden by the cache
+func TestRequestCachedRepository(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	server := NewServer(mockRepo, calculator.NewCalculator())
+
+	alice := &database.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	renamed := &database.User{ID: 1, Username: "alicia", Email: "alice@example.com"}
+	mockRepo.On("GetUser", 1).Return(alice, nil).Once()
+	mockRepo.On("GetUser", 2).Return(nil, database.ErrUserNotFound).Once()
+	mockRepo.On("UpdateUser", renamed).Return(nil).Once()
+	mockRepo.On("GetUser", 1).Return(renamed, nil).Once()
+
+	handler := server.cacheRepoLookups(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo := server.users(r)
+		for range 3 {
+			user, err := repo.GetUser(1)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", user.Username)
+
+			_, err = repo.GetUser(2)
+			assert.ErrorIs(t, err, database.ErrUserNotFound)
+		}
+
+		require.NoError(t, repo.UpdateUser(renamed))
+		user, err := repo.GetUser(1)
+		require.NoError(t, err)
+		assert.Equal(t, "alicia", user.Username)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	mockRepo.AssertExpectations(t)
+
+	// Each request starts with an empty cache
+	mockRepo.On("GetUser", 1).Return(renamed, nil).Once()
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, httptest.NewRequest("GET", "/users/1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockRepo.AssertExpectations(t)
+}
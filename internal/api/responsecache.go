@@ -0,0 +1,148 @@
+package api
+
+import (
+	"container/list"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// responseCache is an LRU cache of successful GET responses under /users,
+// keyed by path and query. Any write under /users empties it.
+type responseCache struct {
+	ttl   time.Duration
+	size  int
+	mutex sync.Mutex
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+	// generation counts invalidations, so a response read before a write
+	// finished is never stored after it
+	generation uint64
+}
+
+// cachedResponse is the value stored in each element of responseCache.order
+type cachedResponse struct {
+	key     string
+	expires time.Time
+	header  http.Header
+	body    []byte
+}
+
+func newResponseCache(ttl time.Duration, size int) *responseCache {
+	return &responseCache{
+		ttl:   ttl,
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the unexpired response cached under key, along with the
+// generation to pass to put when caching a fresh response on a miss
+func (c *responseCache) get(key string, now time.Time) (*cachedResponse, uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, c.generation
+	}
+	entry := elem.Value.(*cachedResponse)
+	if now.After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return nil, c.generation
+	}
+	c.order.MoveToFront(elem)
+	return entry, c.generation
+}
+
+// put caches a response unless the cache was invalidated since generation
+func (c *responseCache) put(entry *cachedResponse, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation || c.size <= 0 {
+		return
+	}
+	if elem, ok := c.items[entry.key]; ok {
+		c.order.Remove(elem)
+	}
+
+	c.items[entry.key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+// invalidate empties the cache
+func (c *responseCache) invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	c.order.Init()
+	clear(c.items)
+}
+
+// cacheResponses serves repeated GET requests for users from the response
+// cache, marking responses with X-Cache: HIT or MISS. Only 200 responses are
+// cached. Writes under /users invalidate the cache once they have been handled.
+func (s *Server) cacheResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isUsersPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			if isUnsafeMethod(r.Method) {
+				defer s.responseCache.invalidate()
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := r.URL.RequestURI()
+		cached, generation := s.responseCache.get(key, s.now())
+		if cached != nil {
+			// Headers set by earlier middleware describe this request,
+			// not the one the response was cached for
+			for name, values := range cached.header {
+				if _, set := w.Header()[name]; !set {
+					w.Header()[name] = values
+				}
+			}
+			w.Header().Set("X-Cache", "HIT")
+			w.WriteHeader(http.StatusOK)
+			w.Write(cached.body)
+			return
+		}
+
+		rw := &recordingWriter{}
+		rw.ResponseWriter = w
+		rw.beforeHeader = func(h http.Header) {
+			rw.header = h.Clone()
+			h.Set("X-Cache", "MISS")
+		}
+
+		next.ServeHTTP(rw, r)
+		rw.finish()
+
+		if rw.status == http.StatusOK {
+			s.responseCache.put(&cachedResponse{
+				key:     key,
+				expires: s.now().Add(s.responseCache.ttl),
+				header:  rw.header,
+				body:    rw.body.Bytes(),
+			}, generation)
+		}
+	})
+}
+
+// isUsersPath reports whether path belongs to the user endpoints
+func isUsersPath(path string) bool {
+	return path == "/users" || strings.HasPrefix(path, "/users/")
+}
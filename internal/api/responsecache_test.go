@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestResponseCache tests that repeated GETs are served from the cache until
+// a write invalidates it
+func TestResponseCache(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	handler := NewServer(mockRepo, calculator.NewCalculator(), WithResponseCache(time.Minute, 10)).Router()
+
+	alice := &database.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	mockRepo.On("GetUser", 1).Return(alice, nil).Once()
+	first := get("/users/1")
+	second := get("/users/1")
+	mockRepo.AssertExpectations(t)
+
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+
+	// The query is part of the key
+	mockRepo.On("GetUser", 1).Return(alice, nil).Once()
+	assert.Equal(t, "MISS", get("/users/1?fields=id").Header().Get("X-Cache"))
+	mockRepo.AssertExpectations(t)
+
+	// Any write under /users invalidates every cached response
+	mockRepo.On("CreateUser", mock.Anything).Return(nil).Once()
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"username":"bob","email":"bob@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	mockRepo.On("GetUser", 1).Return(alice, nil).Once()
+	assert.Equal(t, "MISS", get("/users/1").Header().Get("X-Cache"))
+	mockRepo.AssertExpectations(t)
+}
+
+// TestResponseCacheSkips tests responses that are never cached
+func TestResponseCacheSkips(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		setup func(*database.MockUserRepository)
+	}{
+		{"Error response", "/users/2", func(m *database.MockUserRepository) {
+			m.On("GetUser", 2).Return(nil, database.ErrUserNotFound).Twice()
+		}},
+		{"Outside /users", "/calculator/stats/usage", func(*database.MockUserRepository) {}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			tc.setup(mockRepo)
+			handler := NewServer(mockRepo, calculator.NewCalculator(), WithResponseCache(time.Minute, 10)).Router()
+
+			for range 2 {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+				assert.NotEqual(t, "HIT", rec.Header().Get("X-Cache"))
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestResponseCacheExpiryAndSize tests that entries expire after the TTL and
+// that the least recently used entry is evicted when the cache is full
+func TestResponseCacheExpiryAndSize(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := database.NewUserRepository(
+		&database.User{Username: "alice", Email: "alice@example.com"},
+		&database.User{Username: "bob", Email: "bob@example.com"},
+	)
+	handler := NewServer(repo, calculator.NewCalculator(),
+		WithResponseCache(time.Minute, 1), WithClock(func() time.Time { return now })).Router()
+
+	cacheStatus := func(path string) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec.Header().Get("X-Cache")
+	}
+
+	assert.Equal(t, "MISS", cacheStatus("/users/1"))
+	assert.Equal(t, "HIT", cacheStatus("/users/1"))
+
+	// A size of one evicts /users/1 to make room
+	assert.Equal(t, "MISS", cacheStatus("/users/2"))
+	assert.Equal(t, "MISS", cacheStatus("/users/1"))
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, "MISS", cacheStatus("/users/1"))
+}
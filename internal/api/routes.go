@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// routeMux is the ServeMux the router registers its routes on. Routes
+// disabled with WithDisabledRoutes are skipped, so requests for them get 404
+// as if they did not exist, and routes given a limit with
+// WithConcurrencyLimits are wrapped in limitConcurrency.
+type routeMux struct {
+	*http.ServeMux
+	disabled map[string]struct{}
+	limits   map[string]int
+}
+
+// Handle registers handler for pattern unless the route is disabled
+func (m routeMux) Handle(pattern string, handler http.Handler) {
+	if m.isDisabled(pattern) {
+		return
+	}
+	if limit, ok := m.limitOf(pattern); ok {
+		handler = limitConcurrency(handler, limit)
+	}
+	m.ServeMux.Handle(pattern, handler)
+}
+
+// HandleFunc registers handler for pattern unless the route is disabled
+func (m routeMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// isDisabled reports whether pattern, such as "POST /calculator/divide", was
+// disabled either as a whole or by its path alone
+func (m routeMux) isDisabled(pattern string) bool {
+	if _, ok := m.disabled[pattern]; ok {
+		return true
+	}
+	_, path, hasMethod := strings.Cut(pattern, " ")
+	if !hasMethod {
+		return false
+	}
+	_, ok := m.disabled[path]
+	return ok
+}
+
+// limitOf returns the concurrency limit of pattern, given either for the
+// pattern as a whole or for its path alone
+func (m routeMux) limitOf(pattern string) (int, bool) {
+	if limit, ok := m.limits[pattern]; ok {
+		return limit, true
+	}
+	_, path, hasMethod := strings.Cut(pattern, " ")
+	if !hasMethod {
+		return 0, false
+	}
+	limit, ok := m.limits[path]
+	return limit, ok
+}
+
+// limitConcurrency lets at most limit requests run next at once, answering
+// any more with 503 rather than queueing them
+func limitConcurrency(next http.Handler, limit int) http.Handler {
+	slots := make(chan struct{}, limit)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		default:
+			w.Header().Set("Retry-After", "1")
+			respondError(w, r, http.StatusServiceUnavailable, "Too many concurrent requests")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestDisabledRoutes tests that disabled routes are gone while others remain
+func TestDisabledRoutes(t *testing.T) {
+	handler := NewServer(database.NewUserRepository(), calculator.NewCalculator(),
+		WithDisabledRoutes("/calculator/divide", "POST /calculator/multiply")).Router()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"Disabled path with GET", "GET", "/calculator/divide?a=6&b=3", http.StatusNotFound},
+		{"Disabled path with POST", "POST", "/calculator/divide?a=6&b=3", http.StatusNotFound},
+		// The path still exists for GET, so POST is a method not allowed
+		{"Disabled pattern", "POST", "/calculator/multiply?a=6&b=3", http.StatusMethodNotAllowed},
+		{"Other method of a disabled pattern", "GET", "/calculator/multiply?a=6&b=3", http.StatusOK},
+		{"Other calculator route", "GET", "/calculator/add?a=6&b=3", http.StatusOK},
+		{"User route", "GET", "/users", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
+
+// TestConcurrencyLimits tests that requests beyond a route's limit get 503
+// while they are in flight, without affecting other routes
+func TestConcurrencyLimits(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	handler := NewServer(mockRepo, calculator.NewCalculator(),
+		WithConcurrencyLimits(map[string]int{"POST /users": 2})).Router()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockRepo.On("CreateUser", mock.Anything).Run(func(mock.Arguments) {
+		started <- struct{}{}
+		<-release
+	}).Return(nil)
+	mockRepo.On("ListUsers").Return([]*database.User{}, nil)
+
+	create := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"username":"alice","email":"alice@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Saturate the limit with two slow creates
+	var wg sync.WaitGroup
+	codes := make(chan int, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- create().Code
+		}()
+	}
+	<-started
+	<-started
+
+	overflow := create()
+	assert.Equal(t, http.StatusServiceUnavailable, overflow.Code)
+	assert.Equal(t, "1", overflow.Header().Get("Retry-After"))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "other routes are not limited")
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		assert.Equal(t, http.StatusCreated, code)
+	}
+
+	// Once the slow creates finish there is room again
+	go func() { <-started }()
+	assert.Equal(t, http.StatusCreated, create().Code)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Timeouts guarding the server against clients that hold connections open
+// without completing requests
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = time.Minute
+	idleTimeout       = 2 * time.Minute
+)
+
+// errIncompleteTLSConfig is returned by RunTLS when only one of the
+// certificate and key files is given
+var errIncompleteTLSConfig = errors.New("TLS needs both a certificate and a key file")
+
+// Run serves the API over plain HTTP on addr until Shutdown is called
+func (s *Server) Run(addr string) error {
+	return s.RunTLS(addr, "", "")
+}
+
+// RunTLS serves the API over HTTPS with HTTP/2 enabled on addr until Shutdown
+// is called. It falls back to plain HTTP when neither a certificate nor a key
+// is provided, and fails when only one of them is.
+func (s *Server) RunTLS(addr, certFile, keyFile string) error {
+	if (certFile == "") != (keyFile == "") {
+		return errIncompleteTLSConfig
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.serve(ln, certFile, keyFile)
+}
+
+// serve accepts connections on ln, using TLS when both certFile and keyFile are set
+func (s *Server) serve(ln net.Listener, certFile, keyFile string) error {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+
+	httpServer := &http.Server{
+		Handler:           s.Router(),
+		Protocols:         protocols,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	s.mutex.Lock()
+	s.httpServer = httpServer
+	s.mutex.Unlock()
+
+	var err error
+	if certFile != "" && keyFile != "" {
+		err = httpServer.ServeTLS(ln, certFile, keyFile)
+	} else {
+		err = httpServer.Serve(ln)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
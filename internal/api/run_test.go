@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRouterOverTLS tests that the router serves requests over TLS with HTTP/2
+func TestRouterOverTLS(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	ts := httptest.NewUnstartedServer(server.Router())
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL + "/calculator/add?a=2&b=3")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotNil(t, resp.TLS)
+	assert.Equal(t, 2, resp.ProtoMajor, "Should negotiate HTTP/2")
+}
+
+// TestRunTLSIncompleteConfig tests that RunTLS refuses a certificate without
+// a key and vice versa instead of silently serving plain HTTP
+func TestRunTLSIncompleteConfig(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	assert.ErrorIs(t, server.RunTLS("127.0.0.1:0", "cert.pem", ""), errIncompleteTLSConfig)
+	assert.ErrorIs(t, server.RunTLS("127.0.0.1:0", "", "key.pem"), errIncompleteTLSConfig)
+}
+
+// TestRunAndShutdown tests serving plain HTTP until Shutdown is called
+func TestRunAndShutdown(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error)
+	go func() {
+		served <- server.serve(ln, "", "")
+	}()
+
+	url := "http://" + ln.Addr().String() + "/calculator/add?a=2&b=3"
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	// Slow clients cannot hold connections open indefinitely
+	server.mutex.Lock()
+	httpServer := server.httpServer
+	server.mutex.Unlock()
+	assert.Equal(t, readHeaderTimeout, httpServer.ReadHeaderTimeout)
+	assert.Equal(t, readTimeout, httpServer.ReadTimeout)
+	assert.Equal(t, idleTimeout, httpServer.IdleTimeout)
+
+	require.NoError(t, server.Shutdown(context.Background()))
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err, "Run should return cleanly after Shutdown")
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after Shutdown")
+	}
+}
+
+// startSlowServer serves a server whose user listing blocks until release is
+// closed, and returns the listing URL and the channel serve reports on
+func startSlowServer(t *testing.T, release chan struct{}, opts ...Option) (*Server, string, chan error) {
+	t.Helper()
+
+	mockRepo := new(database.MockUserRepository)
+	mockRepo.On("ListUsers").Return([]*database.User{}, nil).Run(func(mock.Arguments) {
+		<-release
+	})
+	server := NewServer(mockRepo, calculator.NewCalculator(), opts...)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.serve(ln, "", "")
+	}()
+
+	return server, "http://" + ln.Addr().String() + "/users", served
+}
+
+// TestShutdownDrainsInFlightRequests tests that Shutdown waits for a slow request to finish
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	release := make(chan struct{})
+	server, url, served := startSlowServer(t, release, WithDrainTimeout(5*time.Second), WithLogger(logger))
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	require.Eventually(t, func() bool { return server.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdown:
+		t.Fatal("Shutdown returned while a request was still in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-status, "The in-flight request should complete")
+	assert.NoError(t, <-shutdown)
+	assert.NoError(t, <-served)
+	assert.Equal(t, int64(0), server.InFlight())
+	assert.Contains(t, logs.String(), `"msg":"shutting down","in_flight":1`)
+}
+
+// TestShutdownDrainTimeout tests that requests outlasting the drain timeout are cut off
+func TestShutdownDrainTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server, url, served := startSlowServer(t, release, WithDrainTimeout(50*time.Millisecond))
+
+	requestErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+		requestErr <- err
+	}()
+	require.Eventually(t, func() bool { return server.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	start := time.Now()
+	err := server.Shutdown(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "Shutdown should give up after the drain timeout")
+	assert.Error(t, <-requestErr, "The connection should have been closed")
+	assert.NoError(t, <-served)
+}
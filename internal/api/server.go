@@ -12,11 +12,19 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/http/pprof"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"go-testing/api/definitions"
 	"go-testing/internal/calculator"
 	"go-testing/internal/database"
 	pkgcalculator "go-testing/pkg/calculator"
@@ -28,33 +36,200 @@ type Server struct {
 	userRepo   database.UserRepository
 	calculator *calculator.Calculator
 	pubCalc    *pkgcalculator.Calculator
+	sessions   *calculator.SessionStore
+
+	mutex          sync.Mutex
+	httpServer     *http.Server
+	inFlight       atomic.Int64
+	readOnly       atomic.Bool
+	drainTimeout   time.Duration
+	requestTimeout time.Duration
+	slowThreshold  time.Duration
+	logger         *slog.Logger
+	accessLog      *accessLog
+	auditLog       *auditLog
+	idempotency    *idempotencyStore
+	duplicates     *duplicateStore
+	responseCache  *responseCache
+	recentErrors   *errorRing
+
+	healthCheckers []HealthChecker
+
+	httpsRedirect     bool
+	allowedHosts      map[string]struct{}
+	userListEnvelope  bool
+	pageEnvelope      bool
+	jwtKey            []byte
+	apiKeys           map[string]string
+	apiKeyRoles       map[string][]string
+	signingKey        []byte
+	validateUTF8      bool
+	operandSources    []OperandSource
+	operandNames      [2]string
+	strictQuery       bool
+	strictAccept      bool
+	disabledRoutes    map[string]struct{}
+	calculatorOps     map[string]struct{}
+	concurrencyLimits map[string]int
+	canary            bool
+	canaryPercent     int
+	precision         map[string]int
+	pprof             bool
+	defaultPageSize   int
+	maxPageSize       int
+	bulkWorkers       int
+	buildInfo         BuildInfo
+
+	now       func() time.Time
+	startedAt time.Time
 }
 
-// NewServer creates a new Server with the given dependencies
-func NewServer(userRepo database.UserRepository, calc *calculator.Calculator) *Server {
-	return &Server{
+// NewServer creates a new Server with the given dependencies and options
+func NewServer(userRepo database.UserRepository, calc *calculator.Calculator, opts ...Option) *Server {
+	s := &Server{
 		userRepo:   userRepo,
 		calculator: calc,
 		pubCalc:    pkgcalculator.NewCalculator(),
+		
+		operandSources:  defaultOperandSources,
+		operandNames:    [2]string{"a", "b"},
+		defaultPageSize: DefaultPageSize,
+		maxPageSize:     DefaultMaxPageSize,
+		bulkWorkers:     DefaultBulkWorkers,
+		recentErrors:    newErrorRing(DefaultRecentErrors),
+		slowThreshold:   DefaultSlowRequestThreshold,
+		now:             time.Now,
+		logger:          slog.New(slog.DiscardHandler),
 	}
+
+	// Dependencies that can report their own health register themselves
+	if checker, ok := userRepo.(HealthChecker); ok {
+		s.RegisterHealthChecker(checker)
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.startedAt = s.now()
+
+	return s
+}
+
+// Shutdown gracefully stops a server started with Run or RunTLS, waiting for
+// in-flight requests until ctx is done or the drain timeout passes, and stops
+// background workers. Connections still active after that are closed forcibly.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	httpServer := s.httpServer
+	s.mutex.Unlock()
+
+	var err error
+	if httpServer != nil {
+		err = s.drain(ctx, httpServer)
+	}
+
+	if s.sessions != nil {
+		s.sessions.Close()
+	}
+
+	return err
+}
+
+// drain stops httpServer accepting requests and waits for in-flight ones,
+// forcing connections closed if they outlast ctx or the drain timeout
+func (s *Server) drain(ctx context.Context, httpServer *http.Server) error {
+	s.logger.Info("shutting down", "in_flight", s.InFlight())
+
+	if s.drainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.drainTimeout)
+		defer cancel()
+	}
+
+	err := httpServer.Shutdown(ctx)
+	if err != nil {
+		s.logger.Warn("drain incomplete, closing connections", "in_flight", s.InFlight(), "error", err)
+		httpServer.Close()
+		return err
+	}
+
+	s.logger.Info("drained")
+	return nil
+}
+
+// InFlight returns the number of requests currently being handled
+func (s *Server) InFlight() int64 {
+	return s.inFlight.Load()
 }
 
 // Router returns the HTTP router for the server
 func (s *Server) Router() http.Handler {
-	mux := http.NewServeMux()
+	mux := routeMux{ServeMux: http.NewServeMux(), disabled: s.disabledRoutes, limits: s.concurrencyLimits}
+	
+	// Health endpoint
+	mux.HandleFunc("GET /health", s.health)
+	mux.HandleFunc("GET /health/detailed", s.detailedHealth)
+	mux.Handle("GET /admin/errors", s.requireAdmin(http.HandlerFunc(s.recentErrorsList)))
+	mux.Handle("GET /admin/stats", s.requireAdmin(http.HandlerFunc(s.adminStats)))
+	mux.Handle("GET "+readOnlyPath, s.requireAdmin(http.HandlerFunc(s.getReadOnly)))
+	mux.Handle("PUT "+readOnlyPath, s.requireAdmin(http.HandlerFunc(s.setReadOnly)))
 	
 	// User endpoints
 	mux.HandleFunc("GET /users", s.listUsers)
+	mux.HandleFunc("HEAD /users", s.countUsers)
 	mux.HandleFunc("GET /users/", s.getUser)
+	mux.HandleFunc("GET /users/by-email", s.findUserByEmail)
 	mux.HandleFunc("POST /users", s.createUser)
+	mux.HandleFunc("POST /users/bulk", s.createUsersBulk)
+	mux.HandleFunc("POST /users/bulk-update", s.updateUsersBulk)
 	mux.HandleFunc("PUT /users/", s.updateUser)
-	mux.HandleFunc("DELETE /users/", s.deleteUser)
+	mux.HandleFunc("PATCH /users/", s.patchUser)
+	mux.Handle("DELETE /users/", s.requireRole("admin", http.HandlerFunc(s.deleteUser)))
+	mux.HandleFunc("OPTIONS /users", allowMethods("GET", "POST"))
+	mux.HandleFunc("OPTIONS /users/", allowMethods("GET", "PUT", "PATCH", "DELETE"))
 	
 	// Calculator endpoints
 	mux.HandleFunc("GET /calculator/add", s.add)
 	mux.HandleFunc("GET /calculator/subtract", s.subtract)
 	mux.HandleFunc("GET /calculator/multiply", s.multiply)
 	mux.HandleFunc("GET /calculator/divide", s.divide)
+	mux.HandleFunc("GET /calculator/almost-equal", s.almostEqual)
+	mux.HandleFunc("GET /calculator/fibonacci", s.fibonacci)
+	mux.HandleFunc("GET /calculator/min", s.minOf)
+	mux.HandleFunc("GET /calculator/max", s.maxOf)
+	mux.HandleFunc("GET /calculator/abs", s.abs)
+	mux.HandleFunc("GET /calculator/sign", s.sign)
+	mux.HandleFunc("GET /calculator/hypot", s.hypot)
+	mux.HandleFunc("GET /calculator/log", s.logarithm)
+	mux.HandleFunc("GET /calculator/round", s.round)
+	mux.HandleFunc("GET /calculator/round-to-multiple", s.roundToMultiple)
+	mux.HandleFunc("GET /calculator/properties", s.operationProperties)
+	mux.HandleFunc("GET /calculator/stats/usage", s.usageStats)
+	
+	// Operands may also be posted as a JSON or form body
+	mux.HandleFunc("POST /calculator/add", s.add)
+	mux.HandleFunc("POST /calculator/subtract", s.subtract)
+	mux.HandleFunc("POST /calculator/multiply", s.multiply)
+	mux.HandleFunc("POST /calculator/divide", s.divide)
+	mux.HandleFunc("POST /calculator/dot", s.dotProduct)
+	mux.HandleFunc("POST /calculator/matrix-multiply", s.matrixMultiply)
+	mux.HandleFunc("POST /calculator/variance", s.variance)
+	mux.HandleFunc("POST /calculator/moving-average", s.movingAverage)
+	mux.HandleFunc("POST /calculator/percentile", s.percentile)
+	mux.HandleFunc("POST /calculator/median", s.median)
+	mux.HandleFunc("POST /calculator/mode", s.mode)
+	mux.HandleFunc("POST /calculator/map", s.mapOp)
+	mux.HandleFunc("POST /calculator/complex/{op}", s.complexArithmetic)
+	mux.HandleFunc("POST /calculator/evaluate", s.evaluate)
+	mux.HandleFunc("POST /calculator/batch-eval", s.batchEvaluate)
+	mux.HandleFunc("POST /calculator/money", s.money)
+	
+	// Calculator session endpoints
+	if s.sessions != nil {
+		mux.HandleFunc("POST /calculator/sessions", s.createSession)
+		mux.HandleFunc("GET /calculator/sessions/{id}", s.getSession)
+		mux.HandleFunc("POST /calculator/sessions/{id}/{op}", s.applySession)
+	}
 	
 	// Swagger endpoints
 	handler := httpSwagger.Handler(
@@ -67,6 +242,7 @@ func (s *Server) Router() http.Handler {
 	// Handle specific Swagger endpoints
 	mux.HandleFunc("GET /swagger/index.html", handler.ServeHTTP)
 	mux.HandleFunc("GET /swagger/doc.json", handler.ServeHTTP)
+	mux.HandleFunc("GET /swagger/doc.yaml", serveSwaggerYAML)
 	mux.HandleFunc("GET /swagger/swagger-ui.css", handler.ServeHTTP)
 	mux.HandleFunc("GET /swagger/swagger-ui-bundle.js", handler.ServeHTTP)
 	mux.HandleFunc("GET /swagger/swagger-ui-standalone-preset.js", handler.ServeHTTP)
@@ -75,40 +251,168 @@ func (s *Server) Router() http.Handler {
 	// Also keep a wildcard handler for other Swagger resources
 	mux.HandleFunc("GET /swagger/", handler.ServeHTTP)
 	
-	return mux
+	// Profiling endpoints, only when debugging is enabled
+	if s.pprof {
+		mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+		mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+	}
+	
+	// Batches dispatch their sub-requests through the complete router
+	router := s.middleware(mux)
+	mux.HandleFunc("POST "+batchPath, s.batch(router))
+	
+	return router
 }
 
 // Helper function to respond with JSON
-func respondJSON(w http.ResponseWriter, status int, data interface{}) {
+// Pass ?pretty=true to get indented output for debugging
+func respondJSON(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+	
+	encoder := json.NewEncoder(w)
+	if pretty, _ := parseBoolParam(r, "pretty", false); pretty {
+		encoder.SetIndent("", "  ")
+	}
+	encoder.Encode(data)
 }
 
 // Helper function to respond with an error
-func respondError(w http.ResponseWriter, status int, message string) {
-	respondJSON(w, status, map[string]string{"error": message})
+func respondError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	respondJSON(w, r, status, map[string]string{"error": message})
+}
+
+// respondUnavailable responds 503 if err shows the user repository's circuit
+// breaker is open, reporting whether it did
+func respondUnavailable(w http.ResponseWriter, r *http.Request, err error) bool {
+	if !errors.Is(err, database.ErrCircuitOpen) {
+		return false
+	}
+	respondError(w, r, http.StatusServiceUnavailable, "User repository unavailable")
+	return true
+}
+
+// allowMethods returns a handler answering OPTIONS requests with the methods
+// a route supports in the Allow header
+func allowMethods(methods ...string) http.HandlerFunc {
+	allow := strings.Join(append(methods, http.MethodOptions), ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		w.WriteHeader(http.StatusNoContent)
+	}
 }
 
 // User handlers
 
 // listUsers godoc
 // @Summary List all users
-// @Description Get all users
+// @Description Get all users. Servers configured with the user list envelope
+// @Description return a definitions.UsersResponse object instead of a bare array.
+// @Description Users are paged by limit and offset, ordered by ID. Without a limit the
+// @Description first page of the default size is returned; limit=0 returns every user.
+// @Description Limits above the server maximum are clamped and reported in X-Limit-Clamped.
+// @Description Servers configured with the page envelope return a definitions.PagedUsersResponse
+// @Description holding the users in data and the offset, limit, total and has_more in page.
+// @Description With a cursor, even an empty one, users are paged by ID instead of offset and
+// @Description a definitions.UsersPage is returned whose next_cursor fetches the following page.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Param limit query int false "Maximum number of users to return, 0 for all"
+// @Param offset query int false "Number of users to skip"
+// @Param cursor query string false "next_cursor of the previous page, or empty for the first page; cannot be combined with offset"
+// @Param fields query string false "Comma-separated fields to return, such as id,email; unknown names are ignored"
+// @Param view query string false "summary returns definitions.UserSummary objects without emails, ignoring fields" Enums(full, summary)
 // @Success 200 {array} database.User
+// @Header 200 {integer} X-Limit-Clamped "Limit actually applied, when the requested one exceeded the maximum"
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /users [get]
 func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := s.userRepo.ListUsers()
+	pg, err := s.parsePage(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	view := r.URL.Query().Get("view")
+	if view != "" && view != "full" && view != "summary" {
+		respondError(w, r, http.StatusBadRequest, "view must be full or summary")
+		return
+	}
+	if r.URL.Query().Has("cursor") {
+		s.listUsersByCursor(w, r, pg, view)
+		return
+	}
+	
+	users, err := s.users(r).ListUsers()
+	if err != nil {
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		respondError(w, r, http.StatusInternalServerError, "Error retrieving users")
+		return
+	}
+	
+	total := len(users)
+	users = pg.apply(users)
+	if pg.clamped {
+		w.Header().Set("X-Limit-Clamped", strconv.Itoa(pg.limit))
+	}
+	
+	fields := parseFields(r)
+	if s.pageEnvelope {
+		var data interface{} = users
+		switch {
+		case view == "summary":
+			data = summarizeUsers(users)
+		case fields != nil:
+			data = projectUsers(users, fields)
+		}
+		respondJSON(w, r, http.StatusOK, definitions.PagedUsersResponse{Data: data, Page: pg.info(len(users), total)})
+		return
+	}
+	switch {
+	case view == "summary" && s.userListEnvelope:
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{"users": summarizeUsers(users), "total": total})
+	case view == "summary":
+		respondJSON(w, r, http.StatusOK, summarizeUsers(users))
+	case fields != nil && s.userListEnvelope:
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{"users": projectUsers(users, fields), "total": total})
+	case fields != nil:
+		respondJSON(w, r, http.StatusOK, projectUsers(users, fields))
+	case s.userListEnvelope:
+		respondJSON(w, r, http.StatusOK, newUsersResponse(users, total))
+	default:
+		respondJSON(w, r, http.StatusOK, users)
+	}
+}
+
+// countUsers godoc
+// @Summary Count users
+// @Description Report how many users there are in the X-Total-Count header, without a body
+// @Tags users
+// @Success 200
+// @Header 200 {integer} X-Total-Count "Number of users"
+// @Failure 500
+// @Failure 503
+// @Router /users [head]
+func (s *Server) countUsers(w http.ResponseWriter, r *http.Request) {
+	count, err := s.users(r).CountUsers()
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Error retrieving users")
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		respondError(w, r, http.StatusInternalServerError, "Error counting users")
 		return
 	}
 	
-	respondJSON(w, http.StatusOK, users)
+	w.Header().Set("X-Total-Count", strconv.Itoa(count))
+	w.WriteHeader(http.StatusOK)
 }
 
 // getUser godoc
@@ -118,25 +422,61 @@ func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param fields query string false "Comma-separated fields to return, such as id,email; unknown names are ignored"
 // @Success 200 {object} database.User
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /users/{id} [get]
 func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
 	// Extract ID from path
 	id, err := extractIDFromPath(r.URL.Path)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	
-	user, err := s.userRepo.GetUser(id)
+	user, err := s.users(r).GetUser(id)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "User not found")
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		respondError(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 	
-	respondJSON(w, http.StatusOK, user)
+	respondJSON(w, r, http.StatusOK, projectUser(user, parseFields(r)))
+}
+
+// findUserByEmail godoc
+// @Summary Find a user by email
+// @Description Get the user with the given email address, compared case-insensitively
+// @Tags users
+// @Produce json
+// @Param email query string true "Email address"
+// @Param fields query string false "Comma-separated fields to return, such as id,email; unknown names are ignored"
+// @Success 200 {object} database.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /users/by-email [get]
+func (s *Server) findUserByEmail(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		respondError(w, r, http.StatusBadRequest, "email is required")
+		return
+	}
+	
+	user, err := s.users(r).FindByEmail(email)
+	if err != nil {
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		respondError(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+	
+	respondJSON(w, r, http.StatusOK, projectUser(user, parseFields(r)))
 }
 
 // createUser godoc
@@ -148,27 +488,44 @@ func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
 // @Param user body database.User true "User information"
 // @Success 201 {object} database.User
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Failure 507 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /users [post]
 func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
 	var user database.User
 	
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+	if err := decodeJSON(w, r, &user); err != nil {
+		respondDecodeError(w, r, err)
+		return
+	}
+	user.Normalize()
+	if err := user.Validate(); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	
-	if err := s.userRepo.CreateUser(&user); err != nil {
-		respondError(w, http.StatusInternalServerError, "Error creating user")
+	if err := s.users(r).CreateUser(&user); err != nil {
+		switch {
+		case errors.Is(err, database.ErrEmailTaken):
+			respondError(w, r, http.StatusConflict, "Email already in use")
+		case errors.Is(err, database.ErrCapacityExceeded):
+			respondError(w, r, http.StatusInsufficientStorage, "User capacity exceeded")
+		case errors.Is(err, database.ErrCircuitOpen):
+			respondUnavailable(w, r, err)
+		default:
+			respondError(w, r, http.StatusInternalServerError, "Error creating user")
+		}
 		return
 	}
 	
-	respondJSON(w, http.StatusCreated, user)
+	respondJSON(w, r, http.StatusCreated, user)
 }
 
 // updateUser godoc
 // @Summary Update a user
-// @Description Update an existing user's information
+// @Description Update an existing user's information. An id in the body is optional but must match the path.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -177,53 +534,76 @@ func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} database.User
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /users/{id} [put]
 func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) {
 	// Extract ID from path
 	id, err := extractIDFromPath(r.URL.Path)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	
 	var user database.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+	if err := decodeJSON(w, r, &user); err != nil {
+		respondDecodeError(w, r, err)
 		return
 	}
 	
-	// Ensure ID in path matches ID in body
+	// A body ID is optional, but when present it must match the path
+	if user.ID != 0 && user.ID != id {
+		respondError(w, r, http.StatusBadRequest, "id in body does not match path")
+		return
+	}
 	user.ID = id
+	user.Normalize()
+	if err := user.Validate(); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 	
-	if err := s.userRepo.UpdateUser(&user); err != nil {
-		respondError(w, http.StatusNotFound, "User not found")
+	if err := s.users(r).UpdateUser(&user); err != nil {
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		if errors.Is(err, database.ErrEmailTaken) {
+			respondError(w, r, http.StatusConflict, "Email already in use")
+			return
+		}
+		respondError(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 	
-	respondJSON(w, http.StatusOK, user)
+	respondJSON(w, r, http.StatusOK, user)
 }
 
 // deleteUser godoc
 // @Summary Delete a user
-// @Description Delete a user by ID
+// @Description Delete a user by ID. Requires the admin role when authentication is enabled.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /users/{id} [delete]
 func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
 	// Extract ID from path
 	id, err := extractIDFromPath(r.URL.Path)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	
-	if err := s.userRepo.DeleteUser(id); err != nil {
-		respondError(w, http.StatusNotFound, "User not found")
+	if err := s.users(r).DeleteUser(id); err != nil {
+		if respondUnavailable(w, r, err) {
+			return
+		}
+		respondError(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 	
@@ -237,21 +617,24 @@ func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
 // @Description Add two numbers and return the result
 // @Tags calculator
 // @Accept json
+// @Accept x-www-form-urlencoded
 // @Produce json
 // @Param a query number true "First number"
 // @Param b query number true "Second number"
 // @Success 200 {object} map[string]float64
 // @Failure 400 {object} map[string]string
 // @Router /calculator/add [get]
+// @Router /calculator/add [post]
 func (s *Server) add(w http.ResponseWriter, r *http.Request) {
-	a, b, err := getOperands(r)
+	a, b, err := s.getOperands(r)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	
+	s.calculator.RecordOperation("add")
 	result := s.pubCalc.Add(a, b)
-	respondJSON(w, http.StatusOK, map[string]float64{"result": result})
+	s.respondResult(w, r, "add", result)
 }
 
 // subtract godoc
@@ -259,21 +642,24 @@ func (s *Server) add(w http.ResponseWriter, r *http.Request) {
 // @Description Subtract the second number from the first and return the result
 // @Tags calculator
 // @Accept json
+// @Accept x-www-form-urlencoded
 // @Produce json
 // @Param a query number true "First number"
 // @Param b query number true "Second number"
 // @Success 200 {object} map[string]float64
 // @Failure 400 {object} map[string]string
 // @Router /calculator/subtract [get]
+// @Router /calculator/subtract [post]
 func (s *Server) subtract(w http.ResponseWriter, r *http.Request) {
-	a, b, err := getOperands(r)
+	a, b, err := s.getOperands(r)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	
+	s.calculator.RecordOperation("subtract")
 	result := s.pubCalc.Subtract(a, b)
-	respondJSON(w, http.StatusOK, map[string]float64{"result": result})
+	s.respondResult(w, r, "subtract", result)
 }
 
 // multiply godoc
@@ -281,21 +667,24 @@ func (s *Server) subtract(w http.ResponseWriter, r *http.Request) {
 // @Description Multiply two numbers and return the result
 // @Tags calculator
 // @Accept json
+// @Accept x-www-form-urlencoded
 // @Produce json
 // @Param a query number true "First number"
 // @Param b query number true "Second number"
 // @Success 200 {object} map[string]float64
 // @Failure 400 {object} map[string]string
 // @Router /calculator/multiply [get]
+// @Router /calculator/multiply [post]
 func (s *Server) multiply(w http.ResponseWriter, r *http.Request) {
-	a, b, err := getOperands(r)
+	a, b, err := s.getOperands(r)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	
+	s.calculator.RecordOperation("multiply")
 	result := s.pubCalc.Multiply(a, b)
-	respondJSON(w, http.StatusOK, map[string]float64{"result": result})
+	s.respondResult(w, r, "multiply", result)
 }
 
 // divide godoc
@@ -303,59 +692,93 @@ func (s *Server) multiply(w http.ResponseWriter, r *http.Request) {
 // @Description Divide the first number by the second and return the result
 // @Tags calculator
 // @Accept json
+// @Accept x-www-form-urlencoded
 // @Produce json
 // @Param a query number true "First number (dividend)"
 // @Param b query number true "Second number (divisor)"
+// @Param as query string false "Set to fraction for an exact result such as 1/3" Enums(fraction)
 // @Success 200 {object} map[string]float64
 // @Failure 400 {object} map[string]string
 // @Router /calculator/divide [get]
+// @Router /calculator/divide [post]
 func (s *Server) divide(w http.ResponseWriter, r *http.Request) {
-	a, b, err := getOperands(r)
+	if r.URL.Query().Get("as") == "fraction" {
+		s.divideFraction(w, r)
+		return
+	}
+	
+	a, b, err := s.getOperands(r, paramSpec{Name: "as", Type: stringParam})
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	
+	s.calculator.RecordOperation("divide")
 	result, err := s.pubCalc.Divide(a, b)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Division by zero")
+		respondCalculationError(w, r, err)
 		return
 	}
 	
-	respondJSON(w, http.StatusOK, map[string]float64{"result": result})
+	s.respondResult(w, r, "divide", result)
 }
 
 // Helper functions
 
+// newUsersResponse wraps users in the API list envelope. total counts every
+// user, not only those in users when the list is paged.
+func newUsersResponse(users []*database.User, total int) definitions.UsersResponse {
+	resp := definitions.UsersResponse{
+		Users: make([]definitions.UserResponse, 0, len(users)),
+		Total: total,
+	}
+	
+	for _, user := range users {
+		resp.Users = append(resp.Users, definitions.UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+		})
+	}
+	
+	return resp
+}
+
 func extractIDFromPath(path string) (int, error) {
 	// Extract ID from path like "/users/123"
 	parts := strings.Split(path, "/")
 	if len(parts) < 3 {
-		return 0, strconv.ErrSyntax
+		return parsePositiveInt("", "id")
 	}
 	
-	return strconv.Atoi(parts[2])
+	return parsePositiveInt(parts[2], "id")
 }
 
-func getOperands(r *http.Request) (float64, float64, error) {
-	query := r.URL.Query()
-	
-	aStr := query.Get("a")
-	bStr := query.Get("b")
-	
-	if aStr == "" || bStr == "" {
-		return 0, 0, strconv.ErrSyntax
-	}
-	
-	a, err := strconv.ParseFloat(aStr, 64)
+// getOperand reads the single operand of a unary calculator operation, which
+// takes the name of the first of the two operands
+func (s *Server) getOperand(r *http.Request) (float64, error) {
+	first := s.operandNames[0]
+	p, err := s.parseParams(r, paramSpec{Name: first, Type: numberParam, Required: true})
 	if err != nil {
-		return 0, 0, err
+		return 0, err
 	}
 	
-	b, err := strconv.ParseFloat(bStr, 64)
+	return p.Float(first), nil
+}
+
+// getOperands reads the two operands of a calculator operation, named a and b
+// unless the server was configured with other names. extra declares any
+// other parameters the endpoint accepts, so strict mode does not reject them.
+func (s *Server) getOperands(r *http.Request, extra ...paramSpec) (float64, float64, error) {
+	first, second := s.operandNames[0], s.operandNames[1]
+	specs := append([]paramSpec{
+		{Name: first, Type: numberParam, Required: true},
+		{Name: second, Type: numberParam, Required: true},
+	}, extra...)
+	p, err := s.parseParams(r, specs...)
 	if err != nil {
 		return 0, 0, err
 	}
 	
-	return a, b, nil
+	return p.Float(first), p.Float(second), nil
 }
\ No newline at end of file
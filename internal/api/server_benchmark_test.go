@@ -73,6 +73,42 @@ func BenchmarkGetUser(b *testing.B) {
 	}
 }
 
+// BenchmarkConcurrentAPIReads benchmarks concurrent get user requests to
+// surface lock contention in the repository under load
+func BenchmarkConcurrentAPIReads(b *testing.B) {
+	// Create a repository and add test users
+	repo := database.NewUserRepository()
+	urls := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		user := &database.User{
+			Username: "concurrent" + strconv.Itoa(i),
+			Email:    "concurrent" + strconv.Itoa(i) + "@example.com",
+		}
+		repo.CreateUser(user)
+		urls = append(urls, fmt.Sprintf("/users/%d", user.ID))
+	}
+	
+	// Create a server with the populated repository
+	calc := calculator.NewCalculator()
+	server := NewServer(repo, calc)
+	handler := server.Router()
+	
+	// Reset the timer to exclude setup time
+	b.ResetTimer()
+	b.ReportAllocs()
+	
+	b.RunParallel(func(pb *testing.PB) {
+		// Each goroutine cycles through the users
+		i := 0
+		for pb.Next() {
+			req := httptest.NewRequest("GET", urls[i%len(urls)], nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			i++
+		}
+	})
+}
+
 // BenchmarkCreateUser benchmarks the create user endpoint
 func BenchmarkCreateUser(b *testing.B) {
 	server := setupBenchServer()
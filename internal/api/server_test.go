@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"go-testing/internal/calculator"
 	"go-testing/internal/database"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // setupTestServer creates a test server with mocked dependencies
@@ -57,6 +60,48 @@ func TestListUsers(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestHeadUsers tests that HEAD /users reports the user count without a body
+func TestHeadUsers(t *testing.T) {
+	tests := []struct {
+		name          string
+		repo          *database.InMemoryUserRepository
+		expectedCount string
+	}{
+		{"Populated", database.NewUserRepository(seedUsers(3)...), "3"},
+		{"Empty", database.NewUserRepository(), "0"},
+	}
+	
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(tc.repo, calculator.NewCalculator())
+			
+			req := httptest.NewRequest("HEAD", "/users", nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+			
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.expectedCount, rec.Header().Get("X-Total-Count"))
+			assert.Empty(t, rec.Body.String())
+		})
+	}
+}
+
+// TestHeadUsersCountsWithoutListing tests that the count comes from the
+// repository's CountUsers rather than from listing every user
+func TestHeadUsersCountsWithoutListing(t *testing.T) {
+	server, mockRepo, _ := setupTestServer()
+	mockRepo.On("CountUsers").Return(120, nil)
+	
+	req := httptest.NewRequest("HEAD", "/users", nil)
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, req)
+	
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "120", rec.Header().Get("X-Total-Count"))
+	mockRepo.AssertNotCalled(t, "ListUsers")
+	mockRepo.AssertExpectations(t)
+}
+
 // TestGetUser tests the get user endpoint
 func TestGetUser(t *testing.T) {
 	server, mockRepo, _ := setupTestServer()
@@ -212,4 +257,403 @@ func TestCalculatorEndpoints(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// TestListUsersEnvelope tests the bare and wrapped list responses
+func TestListUsersEnvelope(t *testing.T) {
+	populated := []*database.User{
+		{ID: 1, Username: "user1", Email: "user1@example.com"},
+		{ID: 2, Username: "user2", Email: "user2@example.com"},
+	}
+
+	tests := []struct {
+		name         string
+		users        []*database.User
+		opts         []Option
+		expectedBody string
+	}{
+		{"Bare empty", []*database.User{}, nil, `[]`},
+		{"Bare populated", populated, nil,
+			`[{"id":1,"username":"user1","email":"user1@example.com"},{"id":2,"username":"user2","email":"user2@example.com"}]`},
+		{"Wrapped empty", []*database.User{}, []Option{WithUserListEnvelope()}, `{"users":[],"total":0}`},
+		{"Wrapped populated", populated, []Option{WithUserListEnvelope()},
+			`{"users":[{"id":1,"username":"user1","email":"user1@example.com"},{"id":2,"username":"user2","email":"user2@example.com"}],"total":2}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(database.MockUserRepository)
+			mockRepo.On("ListUsers").Return(tc.users, nil)
+			server := NewServer(mockRepo, calculator.NewCalculator(), tc.opts...)
+
+			req := httptest.NewRequest("GET", "/users", nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestPprofEndpoints tests that profiling handlers are only mounted when enabled
+func TestPprofEndpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		expectedStatus int
+	}{
+		{"Enabled", []Option{WithPprof()}, http.StatusOK},
+		{"Disabled", nil, http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), tc.opts...)
+
+			req := httptest.NewRequest("GET", "/debug/pprof/", nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
+
+// TestCreateUserValidation tests that users with missing or malformed fields
+// are rejected before reaching the repository
+func TestCreateUserValidation(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{"Missing username", `{"email":"a@example.com"}`, "invalid user: username is required"},
+		{"Blank username", `{"username":"  ","email":"a@example.com"}`, "invalid user: username is required"},
+		{"Missing email", `{"username":"a"}`, "invalid user: email is not a valid address"},
+		{"Malformed email", `{"username":"a","email":"a@"}`, "invalid user: email is not a valid address"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server, mockRepo, _ := setupTestServer()
+
+			req := httptest.NewRequest("POST", "/users", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, fmt.Sprintf(`{"error":%q}`, tc.expectedError), rec.Body.String())
+			mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+		})
+	}
+}
+
+// TestUpdateUserKeepsCounter tests that a PUT, which replaces the user,
+// neither resets nor sets the login counter
+func TestUpdateUserKeepsCounter(t *testing.T) {
+	repo := database.NewUserRepository(&database.User{Username: "alice", Email: "alice@example.com"})
+	_, err := repo.IncrementField(1, "login_count", 3)
+	require.NoError(t, err)
+	handler := NewServer(repo, calculator.NewCalculator()).Router()
+
+	for _, body := range []string{
+		`{"username":"renamed","email":"alice@example.com"}`,
+		`{"username":"renamed","email":"alice@example.com","login_count":0}`,
+		`{"username":"renamed","email":"alice@example.com","login_count":99}`,
+	} {
+		req := httptest.NewRequest("PUT", "/users/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, body)
+		assert.JSONEq(t, `{"id":1,"username":"renamed","email":"alice@example.com","login_count":3}`, rec.Body.String(), body)
+	}
+}
+
+// TestUpdateUserBodyID tests that a body ID must be omitted or match the path
+func TestUpdateUserBodyID(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectUpdate   bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Matching", `{"id":1,"username":"renamed","email":"renamed@example.com"}`, true, http.StatusOK,
+			`{"id":1,"username":"renamed","email":"renamed@example.com"}`},
+		{"Omitted", `{"username":"renamed","email":"renamed@example.com"}`, true, http.StatusOK,
+			`{"id":1,"username":"renamed","email":"renamed@example.com"}`},
+		{"Mismatching", `{"id":2,"username":"renamed","email":"renamed@example.com"}`, false, http.StatusBadRequest,
+			`{"error":"id in body does not match path"}`},
+		{"Invalid email", `{"username":"renamed","email":"renamed"}`, false, http.StatusBadRequest,
+			`{"error":"invalid user: email is not a valid address"}`},
+		{"Missing username", `{"email":"renamed@example.com"}`, false, http.StatusBadRequest,
+			`{"error":"invalid user: username is required"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server, mockRepo, _ := setupTestServer()
+			if tc.expectUpdate {
+				mockRepo.On("UpdateUser", mock.MatchedBy(func(u *database.User) bool {
+					return u.ID == 1
+				})).Return(nil)
+			}
+
+			req := httptest.NewRequest("PUT", "/users/1", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			mockRepo.AssertExpectations(t)
+			if !tc.expectUpdate {
+				mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+			}
+		})
+	}
+}
+
+// TestOptionsAllow tests that OPTIONS reports the methods each user route supports
+func TestOptionsAllow(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		opts          []Option
+		expectedAllow string
+	}{
+		{"User collection", "/users", nil, "GET, POST, OPTIONS"},
+		{"Single user", "/users/1", nil, "GET, PUT, PATCH, DELETE, OPTIONS"},
+		{"Without credentials when auth is enabled", "/users", []Option{WithJWTAuth(testJWTKey)}, "GET, POST, OPTIONS"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(new(database.MockUserRepository), calculator.NewCalculator(), tc.opts...)
+
+			req := httptest.NewRequest("OPTIONS", tc.path, nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, tc.expectedAllow, rec.Header().Get("Allow"))
+			assert.Empty(t, rec.Body.String())
+		})
+	}
+}
+
+// TestPrettyJSON tests that ?pretty=true indents responses and the default stays compact
+func TestPrettyJSON(t *testing.T) {
+	server, mockRepo, _ := setupTestServer()
+	mockRepo.On("GetUser", 1).Return(&database.User{ID: 1, Username: "user1", Email: "user1@example.com"}, nil)
+
+	tests := []struct {
+		name         string
+		url          string
+		expectedBody string
+	}{
+		{"Compact by default", "/users/1", `{"id":1,"username":"user1","email":"user1@example.com"}` + "\n"},
+		{"Pretty", "/users/1?pretty=true", "{\n  \"id\": 1,\n  \"username\": \"user1\",\n  \"email\": \"user1@example.com\"\n}\n"},
+		{"Pretty disabled", "/users/1?pretty=false", `{"id":1,"username":"user1","email":"user1@example.com"}` + "\n"},
+		{"Pretty error", "/calculator/add?a=1&pretty=true", "{\n  \"error\": \"b is required\"\n}\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestFindUserByEmail tests looking up a user by email address
+func TestFindUserByEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		mockEmail      string
+		mockUser       *database.User
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Found", "/users/by-email?email=Alice@Example.com", "Alice@Example.com",
+			&database.User{ID: 1, Username: "alice", Email: "alice@example.com"}, nil,
+			http.StatusOK, `{"id":1,"username":"alice","email":"alice@example.com"}`},
+		{"Found with fields", "/users/by-email?email=alice@example.com&fields=id", "alice@example.com",
+			&database.User{ID: 1, Username: "alice", Email: "alice@example.com"}, nil,
+			http.StatusOK, `{"id":1}`},
+		{"Not found", "/users/by-email?email=carol@example.com", "carol@example.com",
+			nil, database.ErrUserNotFound,
+			http.StatusNotFound, `{"error":"User not found"}`},
+		{"Missing email", "/users/by-email", "", nil, nil,
+			http.StatusBadRequest, `{"error":"email is required"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server, mockRepo, _ := setupTestServer()
+			if tc.mockEmail != "" {
+				mockRepo.On("FindByEmail", tc.mockEmail).Return(tc.mockUser, tc.mockError).Once()
+			}
+
+			req := httptest.NewRequest("GET", tc.url, nil)
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestUserEmailNormalized tests that emails are lowercased and trimmed on
+// write, so differently cased addresses collide
+func TestUserEmailNormalized(t *testing.T) {
+	repo := database.NewUserRepositoryWithOptions(database.WithUniqueEmails())
+	handler := NewServer(repo, calculator.NewCalculator()).Router()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send("POST", "/users", `{"username":"test","email":" Test@Example.com "}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"id":1,"username":"test","email":"test@example.com"}`, rec.Body.String())
+	stored, err := repo.GetUser(1)
+	require.NoError(t, err)
+	assert.Equal(t, "test@example.com", stored.Email)
+
+	rec = send("POST", "/users", `{"username":"dup","email":"test@example.com"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{"error":"Email already in use"}`, rec.Body.String())
+
+	require.Equal(t, http.StatusCreated, send("POST", "/users", `{"username":"other","email":"other@example.com"}`).Code)
+	rec = send("PUT", "/users/2", `{"username":"other","email":"TEST@example.com"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = send("PUT", "/users/2", `{"username":"other","email":"Other@Example.com"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":2,"username":"other","email":"other@example.com"}`, rec.Body.String())
+}
+
+// TestCreateUserCapacityExceeded tests that a full store is reported as 507
+func TestCreateUserCapacityExceeded(t *testing.T) {
+	handler := NewServer(database.NewUserRepositoryWithLimit(1), calculator.NewCalculator()).Router()
+
+	create := func(username string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"username":%q,"email":"%s@example.com"}`, username, username)
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusCreated, create("alice").Code)
+
+	rec := create("bob")
+	assert.Equal(t, http.StatusInsufficientStorage, rec.Code)
+	assert.JSONEq(t, `{"error":"User capacity exceeded"}`, rec.Body.String())
+}
+
+// TestCreateUserDecodeErrors tests that malformed bodies are reported with
+// where they went wrong
+func TestCreateUserDecodeErrors(t *testing.T) {
+	server, _, _ := setupTestServer()
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{"Syntax error", `{"username":"a",,"email":"a@example.com"}`, "invalid JSON at byte 17"},
+		{"Type error", `{"username":"a","email":42}`, "field email must be a string"},
+		{"Wrong top-level type", `["a"]`, "request body must be an object"},
+		{"Truncated", `{"username":"a"`, "invalid JSON: unexpected end of input"},
+		{"Empty", ``, "request body is empty"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, fmt.Sprintf(`{"error":%q}`, tc.expectedError), rec.Body.String())
+		})
+	}
+}
+
+// TestDecodeBodyTooLarge tests that JSON endpoints stop reading bodies over
+// maxBufferedBodyBytes and respond 413
+func TestDecodeBodyTooLarge(t *testing.T) {
+	server, _, _ := setupTestServer()
+	huge := `{"expr":"` + strings.Repeat("1+", maxBufferedBodyBytes/2) + `1"}`
+
+	for _, path := range []string{"/users", "/calculator/evaluate"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest("POST", path, strings.NewReader(huge))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			server.Router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+			assert.JSONEq(t, `{"error":"request body is too large"}`, rec.Body.String())
+		})
+	}
+}
+
+// TestCircuitOpen tests that user endpoints respond 503 while the
+// repository's circuit breaker is open
+func TestCircuitOpen(t *testing.T) {
+	mockRepo := new(database.MockUserRepository)
+	mockRepo.On("ListUsers").Return(nil, assert.AnError).Once()
+	breaker := database.NewCircuitBreakerRepository(mockRepo, 1, time.Hour)
+	handler := NewServer(breaker, calculator.NewCalculator()).Router()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users", nil))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"GET", "/users", ""},
+		{"GET", "/users/1", ""},
+		{"GET", "/users/by-email?email=alice@example.com", ""},
+		{"POST", "/users", `{"username":"alice","email":"alice@example.com"}`},
+		{"PUT", "/users/1", `{"username":"alice","email":"alice@example.com"}`},
+		{"DELETE", "/users/1", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+			assert.JSONEq(t, `{"error":"User repository unavailable"}`, rec.Body.String())
+		})
+	}
+	mockRepo.AssertExpectations(t)
+}
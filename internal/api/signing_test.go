@@ -0,0 +1,101 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sign returns the hex HMAC-SHA256 of body under secret
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TestRequestSigning tests X-Signature verification
+func TestRequestSigning(t *testing.T) {
+	const body = `{"username":"alice","email":"alice@example.com"}`
+	const tampered = `{"username":"mallory","email":"alice@example.com"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		signature      string
+		expectedStatus int
+	}{
+		{"Correct signature", "POST", "/users", body, sign("secret", body), http.StatusCreated},
+		{"Prefixed signature", "POST", "/users", body, "sha256=" + sign("secret", body), http.StatusCreated},
+		{"Tampered body", "POST", "/users", tampered, sign("secret", body), http.StatusUnauthorized},
+		{"Wrong secret", "POST", "/users", body, sign("other", body), http.StatusUnauthorized},
+		{"Missing signature", "POST", "/users", body, "", http.StatusUnauthorized},
+		{"Malformed signature", "POST", "/users", body, "not-hex", http.StatusUnauthorized},
+		{"Signed empty body", "GET", "/users", "", sign("secret", ""), http.StatusOK},
+		{"Health is public", "GET", "/health", "", "", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := database.NewUserRepository()
+			handler := NewServer(repo, calculator.NewCalculator(), WithRequestSigning([]byte("secret"))).Router()
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.signature != "" {
+				req.Header.Set("X-Signature", tc.signature)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"error":"Invalid or missing signature"}`, rec.Body.String())
+				users, err := repo.ListUsers()
+				require.NoError(t, err)
+				assert.Empty(t, users, "a rejected request must not reach the handler")
+			}
+		})
+	}
+}
+
+// TestRequestSigningBodyReachesHandler tests that the handler reads the same
+// body the signature was checked against
+func TestRequestSigningBodyReachesHandler(t *testing.T) {
+	repo := database.NewUserRepository()
+	handler := NewServer(repo, calculator.NewCalculator(), WithRequestSigning([]byte("secret"))).Router()
+
+	const body = `{"username":"alice","email":"alice@example.com"}`
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", sign("secret", body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created database.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "alice", created.Username)
+
+	// The sub-requests of a signed batch are covered by its signature
+	const batch = `[{"method":"GET","path":"/users/1"}]`
+	req = httptest.NewRequest("POST", "/batch", strings.NewReader(batch))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", sign("secret", batch))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":200`)
+}
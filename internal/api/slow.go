@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultSlowRequestThreshold is how long a request may take before it is
+// logged as slow, unless changed with WithSlowRequestThreshold
+const DefaultSlowRequestThreshold = time.Second
+
+// logSlowRequests logs a warning for every request that takes longer than
+// the slow request threshold, so latency outliers show up without tracing
+func (s *Server) logSlowRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+
+		next.ServeHTTP(sw, r)
+		sw.finish()
+
+		if elapsed := time.Since(start); elapsed > s.slowThreshold {
+			s.logger.Warn("slow request", "method", r.Method, "path", r.URL.Path,
+				"status", sw.status, "duration", elapsed)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/database"
+)
+
+// adminStats godoc
+// @Summary Report repository statistics
+// @Description Report the number of users and soft-deleted users, the next ID, an estimate
+// @Description of the memory users take, and the server's uptime. Requires the admin role,
+// @Description and is refused with 403 when the server has no authentication configured.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} definitions.AdminStats
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/stats [get]
+func (s *Server) adminStats(w http.ResponseWriter, r *http.Request) {
+	stats := definitions.AdminStats{
+		Uptime: s.now().Sub(s.startedAt).Truncate(time.Second).String(),
+	}
+
+	if reporter := statsReporter(s.userRepo); reporter != nil {
+		repoStats := reporter.Stats()
+		stats.Users = repoStats.Users
+		stats.SoftDeleted = repoStats.SoftDeleted
+		stats.NextID = repoStats.NextID
+		stats.MemoryBytes = repoStats.ApproxBytes
+	} else {
+		users, err := s.userRepo.ListUsers()
+		if err != nil {
+			respondError(w, r, http.StatusInternalServerError, "Error retrieving users")
+			return
+		}
+		stats.Users = len(users)
+	}
+
+	respondJSON(w, r, http.StatusOK, stats)
+}
+
+// statsReporter returns the first repository in the chain of wrappers
+// starting at repo that reports statistics, or nil if none does
+func statsReporter(repo database.UserRepository) database.StatsReporter {
+	for repo != nil {
+		if reporter, ok := repo.(database.StatsReporter); ok {
+			return reporter
+		}
+		wrapper, ok := repo.(interface {
+			Unwrap() database.UserRepository
+		})
+		if !ok {
+			return nil
+		}
+		repo = wrapper.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-testing/api/definitions"
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getAdminStats requests GET /admin/stats and decodes the response
+func getAdminStats(t *testing.T, handler http.Handler) definitions.AdminStats {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, asAdmin(httptest.NewRequest("GET", "/admin/stats", nil)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var stats definitions.AdminStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	return stats
+}
+
+// TestAdminStats tests the statistics reported after seeding some users
+func TestAdminStats(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	repo := database.NewUserRepositoryWithOptions(database.WithSoftDelete(time.Hour), database.WithClock(clock))
+	for _, name := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, repo.CreateUser(&database.User{Username: name, Email: name + "@example.com"}))
+	}
+	require.NoError(t, repo.DeleteUser(2))
+
+	handler := NewServer(repo, calculator.NewCalculator(), WithClock(clock), withTestAdmin()).Router()
+	now = now.Add(90 * time.Minute)
+
+	stats := getAdminStats(t, handler)
+	assert.Equal(t, 2, stats.Users)
+	assert.Equal(t, 1, stats.SoftDeleted)
+	assert.Equal(t, 4, stats.NextID)
+	assert.Positive(t, stats.MemoryBytes)
+	assert.Equal(t, "1h30m0s", stats.Uptime)
+
+	// Adding a user grows the estimate
+	require.NoError(t, repo.CreateUser(&database.User{Username: "dave", Email: "dave@example.com"}))
+	assert.Greater(t, getAdminStats(t, handler).MemoryBytes, stats.MemoryBytes)
+}
+
+// TestAdminStatsRepositories tests statistics for repositories that are
+// wrapped, or that do not report statistics themselves
+func TestAdminStatsRepositories(t *testing.T) {
+	alice := &database.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+
+	t.Run("Wrapped in a circuit breaker", func(t *testing.T) {
+		repo := database.NewUserRepository(alice)
+		breaker := database.NewCircuitBreakerRepository(repo, 1, time.Minute)
+		stats := getAdminStats(t, NewServer(breaker, calculator.NewCalculator(), withTestAdmin()).Router())
+
+		assert.Equal(t, 1, stats.Users)
+		assert.Equal(t, 2, stats.NextID)
+	})
+
+	t.Run("Without statistics", func(t *testing.T) {
+		mockRepo := new(database.MockUserRepository)
+		mockRepo.On("ListUsers").Return([]*database.User{alice}, nil).Once()
+		stats := getAdminStats(t, NewServer(mockRepo, calculator.NewCalculator(), withTestAdmin()).Router())
+
+		assert.Equal(t, 1, stats.Users)
+		assert.Zero(t, stats.NextID)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestAdminStatsWithoutAuth tests that statistics are refused to everyone
+// when no authentication is configured
+func TestAdminStatsWithoutAuth(t *testing.T) {
+	handler := NewServer(new(database.MockUserRepository), calculator.NewCalculator()).Router()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/stats", nil))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
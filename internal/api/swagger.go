@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/swaggo/swag"
+	"sigs.k8s.io/yaml"
+)
+
+// serveSwaggerYAML serves the same spec as /swagger/doc.json converted to
+// YAML, for tooling that prefers it
+func serveSwaggerYAML(w http.ResponseWriter, r *http.Request) {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		respondError(w, r, http.StatusInternalServerError, "Error reading API spec")
+		return
+	}
+
+	data, err := yaml.JSONToYAML([]byte(doc))
+	if err != nil {
+		respondError(w, r, http.StatusInternalServerError, "Error converting API spec")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(data)
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"unicode/utf8"
+)
+
+// rejectInvalidUTF8 responds 400 to requests whose body is not valid UTF-8.
+// encoding/json would otherwise quietly replace the invalid bytes with U+FFFD.
+func rejectInvalidUTF8(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, ok := bufferBody(w, r)
+		if !ok {
+			return
+		}
+		if !utf8.Valid(body) {
+			respondError(w, r, http.StatusBadRequest, "request body is not valid UTF-8")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
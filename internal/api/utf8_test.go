@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go-testing/internal/calculator"
+	"go-testing/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUTF8Validation tests that request bodies with invalid UTF-8 are
+// rejected before reaching the handler
+func TestUTF8Validation(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"Valid", `{"username":"zoë","email":"zoe@example.com"}`, http.StatusCreated},
+		{"Invalid byte", "{\"username\":\"zo\xff\",\"email\":\"zoe@example.com\"}", http.StatusBadRequest},
+		{"Truncated sequence", "{\"username\":\"zo\xc3\",\"email\":\"zoe@example.com\"}", http.StatusBadRequest},
+		{"Overlong encoding", "{\"username\":\"\xc0\xaf\",\"email\":\"zoe@example.com\"}", http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := database.NewUserRepository()
+			handler := NewServer(repo, calculator.NewCalculator(), WithUTF8Validation()).Router()
+
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			users, err := repo.ListUsers()
+			require.NoError(t, err)
+			if tc.expectedStatus == http.StatusBadRequest {
+				assert.JSONEq(t, `{"error":"request body is not valid UTF-8"}`, rec.Body.String())
+				assert.Empty(t, users)
+			} else {
+				require.Len(t, users, 1)
+				assert.Equal(t, "zoë", users[0].Username)
+			}
+		})
+	}
+}
+
+// TestUTF8ValidationDisabled tests that without validation the request is
+// accepted, but invalid bytes still never reach the repository
+func TestUTF8ValidationDisabled(t *testing.T) {
+	repo := database.NewUserRepository()
+	handler := NewServer(repo, calculator.NewCalculator()).Router()
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader("{\"username\":\"zo\xff\",\"email\":\"zoe@example.com\"}"))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	user, err := repo.GetUser(1)
+	require.NoError(t, err)
+	assert.True(t, utf8.ValidString(user.Username))
+	assert.NotContains(t, user.Username, "\xff")
+}
@@ -2,12 +2,18 @@
 package calculator
 
 import (
+	"sync"
+	"sync/atomic"
+
 	"go-testing/pkg/calculator"
 )
 
 // Calculator wraps the public calculator with any internal functionality
 type Calculator struct {
 	*calculator.Calculator
+
+	// counts maps an operation name to its *atomic.Int64 invocation count
+	counts sync.Map
 }
 
 // NewCalculator creates a new Calculator instance
@@ -15,4 +21,21 @@ func NewCalculator() *Calculator {
 	return &Calculator{
 		Calculator: calculator.NewCalculator(),
 	}
-}
\ No newline at end of file
+}
+
+// RecordOperation counts one invocation of the named operation.
+// It is safe for concurrent use.
+func (c *Calculator) RecordOperation(op string) {
+	counter, _ := c.counts.LoadOrStore(op, new(atomic.Int64))
+	counter.(*atomic.Int64).Add(1)
+}
+
+// OperationCounts returns a snapshot of how often each operation has been invoked
+func (c *Calculator) OperationCounts() map[string]int64 {
+	counts := make(map[string]int64)
+	c.counts.Range(func(key, value any) bool {
+		counts[key.(string)] = value.(*atomic.Int64).Load()
+		return true
+	})
+	return counts
+}
@@ -0,0 +1,266 @@
+package calculator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-testing/pkg/calculator"
+)
+
+var (
+	// ErrSessionNotFound is returned for unknown or evicted sessions
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrUnknownOperation is returned when a session is asked to apply an unsupported operation
+	ErrUnknownOperation = errors.New("unknown operation")
+)
+
+// HistoryEntry records one operation applied to a session
+type HistoryEntry struct {
+	Operation string  `json:"operation"`
+	Operand   float64 `json:"operand"`
+	Result    float64 `json:"result"`
+}
+
+// SessionState is a copy of a session's state safe to hand to callers
+type SessionState struct {
+	ID      string         `json:"id"`
+	Value   float64        `json:"value"`
+	History []HistoryEntry `json:"history"`
+}
+
+// session is a stateful calculation whose running value operations apply to.
+// lock is a one-slot channel rather than a mutex so waiting for it can be
+// abandoned when the request context is cancelled.
+type session struct {
+	id       string
+	lock     chan struct{}
+	value    float64
+	history  []HistoryEntry
+	lastUsed time.Time
+}
+
+// SessionStore keeps calculator sessions in memory and evicts idle ones
+type SessionStore struct {
+	calc     *calculator.Calculator
+	idleTTL  time.Duration
+	now      func() time.Time
+	mutex    sync.Mutex
+	sessions map[string]*session
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewSessionStore creates a SessionStore that evicts sessions idle for longer than idleTTL
+func NewSessionStore(idleTTL time.Duration) *SessionStore {
+	return &SessionStore{
+		calc:     calculator.NewCalculator(),
+		idleTTL:  idleTTL,
+		now:      time.Now,
+		sessions: make(map[string]*session),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start launches the background goroutine that evicts idle sessions every interval.
+// It runs until Close is called.
+func (s *SessionStore) Start(interval time.Duration) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.EvictIdle()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the eviction goroutine and waits for it to exit
+func (s *SessionStore) Close() {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+// EvictIdle removes sessions that have been idle for longer than the TTL
+// and returns how many were removed
+func (s *SessionStore) EvictIdle() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cutoff := s.now().Add(-s.idleTTL)
+	evicted := 0
+	for id, sess := range s.sessions {
+		// Sessions in use are never evicted mid-operation
+		select {
+		case sess.lock <- struct{}{}:
+		default:
+			continue
+		}
+
+		if sess.lastUsed.Before(cutoff) {
+			delete(s.sessions, id)
+			evicted++
+		}
+		<-sess.lock
+	}
+
+	return evicted
+}
+
+// Create starts a new session with a running value of zero
+func (s *SessionStore) Create(ctx context.Context) (SessionState, error) {
+	if err := ctx.Err(); err != nil {
+		return SessionState{}, err
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		return SessionState{}, err
+	}
+
+	sess := &session{
+		id:       id,
+		lock:     make(chan struct{}, 1),
+		lastUsed: s.now(),
+	}
+
+	s.mutex.Lock()
+	s.sessions[id] = sess
+	s.mutex.Unlock()
+
+	return sess.state(), nil
+}
+
+// Get returns the current state of a session
+func (s *SessionStore) Get(ctx context.Context, id string) (SessionState, error) {
+	sess, err := s.acquire(ctx, id)
+	if err != nil {
+		return SessionState{}, err
+	}
+	defer s.release(sess)
+
+	return sess.state(), nil
+}
+
+// Apply performs op with operand on the session's running value, records it
+// in the history and returns the new state
+func (s *SessionStore) Apply(ctx context.Context, id, op string, operand float64) (SessionState, error) {
+	sess, err := s.acquire(ctx, id)
+	if err != nil {
+		return SessionState{}, err
+	}
+	defer s.release(sess)
+
+	result, err := s.apply(op, sess.value, operand)
+	if err != nil {
+		return SessionState{}, err
+	}
+
+	sess.value = result
+	sess.history = append(sess.history, HistoryEntry{Operation: op, Operand: operand, Result: result})
+
+	return sess.state(), nil
+}
+
+// Preview performs op with operand on the session's running value like
+// Apply, but leaves the session unchanged. The returned state holds the
+// result as its value and the session's history as it was.
+func (s *SessionStore) Preview(ctx context.Context, id, op string, operand float64) (SessionState, error) {
+	sess, err := s.acquire(ctx, id)
+	if err != nil {
+		return SessionState{}, err
+	}
+	defer s.release(sess)
+
+	result, err := s.apply(op, sess.value, operand)
+	if err != nil {
+		return SessionState{}, err
+	}
+
+	state := sess.state()
+	state.Value = result
+	return state, nil
+}
+
+func (s *SessionStore) apply(op string, a, b float64) (float64, error) {
+	switch op {
+	case "add":
+		return s.calc.Add(a, b), nil
+	case "subtract":
+		return s.calc.Subtract(a, b), nil
+	case "multiply":
+		return s.calc.Multiply(a, b), nil
+	case "divide":
+		return s.calc.Divide(a, b)
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownOperation, op)
+	}
+}
+
+// acquire looks up a session and waits for exclusive use of it,
+// giving up if ctx is cancelled first
+func (s *SessionStore) acquire(ctx context.Context, id string) (*session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	sess, exists := s.sessions[id]
+	s.mutex.Unlock()
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+
+	select {
+	case sess.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// The session may have been evicted while we waited for it
+	s.mutex.Lock()
+	current := s.sessions[id]
+	s.mutex.Unlock()
+	if current != sess {
+		<-sess.lock
+		return nil, ErrSessionNotFound
+	}
+
+	return sess, nil
+}
+
+func (s *SessionStore) release(sess *session) {
+	sess.lastUsed = s.now()
+	<-sess.lock
+}
+
+func (sess *session) state() SessionState {
+	history := make([]HistoryEntry, len(sess.history))
+	copy(history, sess.history)
+
+	return SessionState{ID: sess.id, Value: sess.value, History: history}
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,141 @@
+package calculator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-testing/pkg/calculator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSessionApply tests applying operations to a session's running value
+func TestSessionApply(t *testing.T) {
+	store := NewSessionStore(time.Minute)
+	ctx := context.Background()
+
+	sess, err := store.Create(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, sess.Value)
+
+	_, err = store.Apply(ctx, sess.ID, "add", 10)
+	assert.NoError(t, err)
+	state, err := store.Apply(ctx, sess.ID, "divide", 4)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2.5, state.Value)
+	assert.Equal(t, []HistoryEntry{
+		{Operation: "add", Operand: 10, Result: 10},
+		{Operation: "divide", Operand: 4, Result: 2.5},
+	}, state.History)
+
+	_, err = store.Apply(ctx, sess.ID, "divide", 0)
+	assert.ErrorIs(t, err, calculator.ErrDivideByZero)
+	_, err = store.Apply(ctx, sess.ID, "modulo", 2)
+	assert.ErrorIs(t, err, ErrUnknownOperation)
+	_, err = store.Apply(ctx, "missing", "add", 1)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+// TestSessionPreview tests that previewing an operation leaves the session unchanged
+func TestSessionPreview(t *testing.T) {
+	store := NewSessionStore(time.Minute)
+	ctx := context.Background()
+
+	sess, err := store.Create(ctx)
+	require.NoError(t, err)
+	_, err = store.Apply(ctx, sess.ID, "add", 10)
+	require.NoError(t, err)
+
+	preview, err := store.Preview(ctx, sess.ID, "multiply", 3)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, preview.Value)
+	assert.Equal(t, []HistoryEntry{{Operation: "add", Operand: 10, Result: 10}}, preview.History)
+
+	state, err := store.Get(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, state.Value)
+	assert.Len(t, state.History, 1)
+
+	_, err = store.Preview(ctx, sess.ID, "divide", 0)
+	assert.ErrorIs(t, err, calculator.ErrDivideByZero)
+	_, err = store.Preview(ctx, "missing", "add", 1)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+// TestSessionContextCancellation tests that session operations give up on cancelled contexts
+func TestSessionContextCancellation(t *testing.T) {
+	store := NewSessionStore(time.Minute)
+
+	sess, err := store.Create(context.Background())
+	require.NoError(t, err)
+
+	t.Run("Already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := store.Apply(ctx, sess.ID, "add", 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Cancelled while waiting for a busy session", func(t *testing.T) {
+		// Hold the session as if another request were using it
+		held, err := store.acquire(context.Background(), sess.ID)
+		require.NoError(t, err)
+		defer store.release(held)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err = store.Apply(ctx, sess.ID, "add", 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+// TestSessionEviction tests that idle sessions are evicted and active ones kept
+func TestSessionEviction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewSessionStore(time.Minute)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	idle, err := store.Create(ctx)
+	require.NoError(t, err)
+	active, err := store.Create(ctx)
+	require.NoError(t, err)
+
+	now = now.Add(45 * time.Second)
+	_, err = store.Apply(ctx, active.ID, "add", 1)
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, 1, store.EvictIdle())
+
+	_, err = store.Get(ctx, idle.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = store.Get(ctx, active.ID)
+	assert.NoError(t, err)
+}
+
+// TestSessionStoreClose tests that Close stops the eviction goroutine
+func TestSessionStoreClose(t *testing.T) {
+	store := NewSessionStore(time.Minute)
+	store.Start(time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		store.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("eviction goroutine did not exit after Close")
+	}
+
+	// Closing twice is safe
+	store.Close()
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a CircuitBreakerRepository while it is
+// refusing calls after repeated failures
+var ErrCircuitOpen = errors.New("user repository unavailable: circuit open")
+
+// Circuit breaker states reported by CircuitBreakerRepository.State
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// CircuitBreakerRepository wraps a UserRepository so that once threshold
+// calls in a row have failed, further calls fail fast with ErrCircuitOpen
+// instead of piling up on a struggling backend. After resetTimeout one call
+// is let through to probe it: success closes the circuit, failure opens it
+// for another resetTimeout. Errors describing the request, such as
+// ErrUserNotFound, mean the backend is working and never count as failures.
+type CircuitBreakerRepository struct {
+	repo         UserRepository
+	threshold    int
+	resetTimeout time.Duration
+	now          func() time.Time
+
+	mutex    sync.Mutex
+	failures int       // consecutive failures while closed
+	openedAt time.Time // zero while closed
+	probing  bool      // a half-open probe is in flight
+}
+
+// NewCircuitBreakerRepository wraps repo in a circuit breaker that opens
+// after threshold consecutive failures, with a threshold below 1 meaning 1,
+// and probes the backend again after resetTimeout
+func NewCircuitBreakerRepository(repo UserRepository, threshold int, resetTimeout time.Duration) *CircuitBreakerRepository {
+	return &CircuitBreakerRepository{
+		repo:         repo,
+		threshold:    max(threshold, 1),
+		resetTimeout: resetTimeout,
+		now:          time.Now,
+	}
+}
+
+// State returns CircuitClosed, CircuitOpen or CircuitHalfOpen, the last
+// meaning the reset timeout has passed and the next call will probe
+func (b *CircuitBreakerRepository) State() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch {
+	case b.openedAt.IsZero():
+		return CircuitClosed
+	case b.now().Sub(b.openedAt) < b.resetTimeout:
+		return CircuitOpen
+	default:
+		return CircuitHalfOpen
+	}
+}
+
+// call runs fn unless the circuit is open, and records its outcome
+func (b *CircuitBreakerRepository) call(fn func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// allow reports whether a call may go ahead, claiming the probe if the
+// circuit is half-open
+func (b *CircuitBreakerRepository) allow() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.resetTimeout {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record updates the breaker with the outcome of a call
+func (b *CircuitBreakerRepository) record(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	wasProbe := b.probing
+	b.probing = false
+	if !isBackendFailure(err) {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return
+	}
+
+	b.failures++
+	if wasProbe || b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// isBackendFailure reports whether err means the backend itself failed,
+// rather than rejecting the request
+func isBackendFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrUserNotFound) &&
+		!errors.Is(err, ErrInvalidUser) &&
+		!errors.Is(err, ErrEmailTaken) &&
+		!errors.Is(err, ErrCapacityExceeded)
+}
+
+// GetUser retrieves a user by ID through the breaker
+func (b *CircuitBreakerRepository) GetUser(id int) (user *User, err error) {
+	err = b.call(func() error {
+		user, err = b.repo.GetUser(id)
+		return err
+	})
+	return user, err
+}
+
+// CreateUser adds a new user through the breaker
+func (b *CircuitBreakerRepository) CreateUser(user *User) error {
+	return b.call(func() error { return b.repo.CreateUser(user) })
+}
+
+// UpdateUser updates an existing user through the breaker
+func (b *CircuitBreakerRepository) UpdateUser(user *User) error {
+	return b.call(func() error { return b.repo.UpdateUser(user) })
+}
+
+// DeleteUser removes a user through the breaker
+func (b *CircuitBreakerRepository) DeleteUser(id int) error {
+	return b.call(func() error { return b.repo.DeleteUser(id) })
+}
+
+// ListUsers returns all users through the breaker
+func (b *CircuitBreakerRepository) ListUsers() (users []*User, err error) {
+	err = b.call(func() error {
+		users, err = b.repo.ListUsers()
+		return err
+	})
+	return users, err
+}
+
+// ListUsersAfter returns a page of users through the breaker
+func (b *CircuitBreakerRepository) ListUsersAfter(id, limit int) (users []*User, err error) {
+	err = b.call(func() error {
+		users, err = b.repo.ListUsersAfter(id, limit)
+		return err
+	})
+	return users, err
+}
+
+// CountUsers counts users through the breaker
+func (b *CircuitBreakerRepository) CountUsers() (count int, err error) {
+	err = b.call(func() error {
+		count, err = b.repo.CountUsers()
+		return err
+	})
+	return count, err
+}
+
+// FindByEmail looks a user up by email address through the breaker
+func (b *CircuitBreakerRepository) FindByEmail(email string) (user *User, err error) {
+	err = b.call(func() error {
+		user, err = b.repo.FindByEmail(email)
+		return err
+	})
+	return user, err
+}
+
+// Migrate migrates the wrapped repository, bypassing the breaker since it
+// only runs at startup
+func (b *CircuitBreakerRepository) Migrate() error {
+	return b.repo.Migrate()
+}
+
+// Unwrap returns the repository the breaker wraps
+func (b *CircuitBreakerRepository) Unwrap() UserRepository {
+	return b.repo
+}
+
+// Name identifies the breaker in health reports
+func (b *CircuitBreakerRepository) Name() string {
+	return "user-repository"
+}
+
+// Check reports the repository unhealthy while the circuit is open, and
+// otherwise defers to the wrapped repository's own check if it has one
+func (b *CircuitBreakerRepository) Check(ctx context.Context) error {
+	if state := b.State(); state != CircuitClosed {
+		return fmt.Errorf("circuit %s", state)
+	}
+	if checker, ok := b.repo.(interface{ Check(context.Context) error }); ok {
+		return checker.Check(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCircuitBreaker tests that the breaker opens after consecutive
+// failures, half-opens after the reset timeout, and closes on a successful probe
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo := new(MockUserRepository)
+	breaker := NewCircuitBreakerRepository(mockRepo, 3, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	mockRepo.On("GetUser", 1).Return(nil, assert.AnError).Times(3)
+	for range 3 {
+		_, err := breaker.GetUser(1)
+		assert.ErrorIs(t, err, assert.AnError)
+	}
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, CircuitOpen, breaker.State())
+	assert.Error(t, breaker.Check(t.Context()))
+
+	// While open, calls fail fast without reaching the repository
+	_, err := breaker.GetUser(1)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.ErrorIs(t, breaker.DeleteUser(1), ErrCircuitOpen)
+	mockRepo.AssertNumberOfCalls(t, "GetUser", 3)
+
+	// A failed probe opens the circuit again for another timeout
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	mockRepo.On("ListUsers").Return(nil, assert.AnError).Once()
+	_, err = breaker.ListUsers()
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	// A successful probe closes it
+	now = now.Add(time.Minute)
+	alice := &User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	mockRepo.On("GetUser", 1).Return(alice, nil).Once()
+	user, err := breaker.GetUser(1)
+	require.NoError(t, err)
+	assert.Equal(t, alice, user)
+	assert.Equal(t, CircuitClosed, breaker.State())
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCircuitBreakerFailures tests which outcomes count towards the threshold
+func TestCircuitBreakerFailures(t *testing.T) {
+	tests := []struct {
+		name          string
+		errs          []error
+		expectedState string
+	}{
+		{"Below the threshold", []error{assert.AnError, assert.AnError}, CircuitClosed},
+		{"At the threshold", []error{assert.AnError, assert.AnError, assert.AnError}, CircuitOpen},
+		{"Success resets the count", []error{assert.AnError, assert.AnError, nil, assert.AnError}, CircuitClosed},
+		{"Domain errors are not failures", []error{ErrUserNotFound, ErrEmailTaken, ErrInvalidUser, ErrCapacityExceeded}, CircuitClosed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			breaker := NewCircuitBreakerRepository(mockRepo, 3, time.Minute)
+			for _, err := range tc.errs {
+				mockRepo.On("DeleteUser", 1).Return(err).Once()
+				breaker.DeleteUser(1)
+			}
+
+			assert.Equal(t, tc.expectedState, breaker.State())
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownField is returned by IncrementField for fields that are not
+// numeric counters
+var ErrUnknownField = errors.New("unknown field")
+
+// counterField returns the counter of user with the given JSON name
+func counterField(user *User, field string) (*int, bool) {
+	switch field {
+	case "login_count":
+		return &user.LoginCount, true
+	default:
+		return nil, false
+	}
+}
+
+// IncrementField atomically adds delta to the numeric field of the user with
+// id, named as in JSON such as "login_count", and returns the new value.
+// Concurrent increments are never lost, unlike a GetUser and UpdateUser pair.
+// Returns ErrUnknownField for fields that are not counters, or ErrUserNotFound.
+func (r *InMemoryUserRepository) IncrementField(id int, field string, delta int) (int, error) {
+	if _, ok := counterField(&User{}, field); !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownField, field)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		r.logger.Info("user not found", "op", "increment", "id", id)
+		return 0, ErrUserNotFound
+	}
+
+	// Stored users are handed out to callers, so the change is made to a
+	// copy that replaces the original
+	updated := *user
+	counter, _ := counterField(&updated, field)
+	*counter += delta
+	r.users[id] = &updated
+	r.logger.Debug("user field incremented", "id", id, "field", field, "value", *counter)
+
+	return *counter, nil
+}
+
+// IncrementField atomically adds delta to a numeric field of a user and
+// saves the repository
+func (r *FileUserRepository) IncrementField(id int, field string, delta int) (int, error) {
+	value, err := r.InMemoryUserRepository.IncrementField(id, field, delta)
+	if err != nil {
+		return 0, err
+	}
+	return value, r.persist()
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIncrementField tests incrementing counters and the errors for
+// unknown users and fields
+func TestIncrementField(t *testing.T) {
+	repo := NewUserRepository(&User{Username: "alice", Email: "alice@example.com"})
+
+	tests := []struct {
+		name          string
+		id            int
+		field         string
+		delta         int
+		expectedValue int
+		expectedErr   error
+	}{
+		{"Increment", 1, "login_count", 1, 1, nil},
+		{"Increment by more", 1, "login_count", 5, 6, nil},
+		{"Decrement", 1, "login_count", -2, 4, nil},
+		{"Unknown field", 1, "logins", 1, 0, ErrUnknownField},
+		{"Non-numeric field", 1, "username", 1, 0, ErrUnknownField},
+		{"ID is not a counter", 1, "id", 1, 0, ErrUnknownField},
+		{"Unknown user", 2, "login_count", 1, 0, ErrUserNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := repo.IncrementField(tc.id, tc.field, tc.delta)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+
+	user, err := repo.GetUser(1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, user.LoginCount)
+}
+
+// TestCounterIgnoredByCreateAndUpdate tests that counters change only through
+// IncrementField, so a replaced user keeps its count
+func TestCounterIgnoredByCreateAndUpdate(t *testing.T) {
+	repo := NewUserRepository()
+
+	user := &User{Username: "alice", Email: "alice@example.com", LoginCount: 100}
+	require.NoError(t, repo.CreateUser(user))
+	assert.Equal(t, 0, user.LoginCount)
+
+	_, err := repo.IncrementField(user.ID, "login_count", 2)
+	require.NoError(t, err)
+
+	for _, count := range []int{0, 50} {
+		update := &User{ID: user.ID, Username: "alice", Email: "alice@example.com", LoginCount: count}
+		require.NoError(t, repo.UpdateUser(update))
+		assert.Equal(t, 2, update.LoginCount)
+	}
+
+	errs := repo.UpdateUsers([]*User{{ID: user.ID, Username: "alice", Email: "alice@example.com"}})
+	require.NoError(t, errs[0])
+	stored, err := repo.GetUser(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.LoginCount)
+}
+
+// TestIncrementFieldConcurrent tests that concurrent increments are not lost.
+// Run with -race to check that readers never see a partial update.
+func TestIncrementFieldConcurrent(t *testing.T) {
+	repo := NewUserRepository(&User{Username: "alice", Email: "alice@example.com"})
+
+	const workers, increments = 8, 100
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range increments {
+				_, err := repo.IncrementField(1, "login_count", 1)
+				assert.NoError(t, err)
+				user, err := repo.GetUser(1)
+				assert.NoError(t, err)
+				assert.Positive(t, user.LoginCount)
+			}
+		}()
+	}
+	wg.Wait()
+
+	user, err := repo.GetUser(1)
+	require.NoError(t, err)
+	assert.Equal(t, workers*increments, user.LoginCount)
+}
+
+// TestIncrementFieldPersisted tests that file repositories save increments
+func TestIncrementFieldPersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	repo, err := NewFileUserRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateUser(&User{Username: "alice", Email: "alice@example.com"}))
+
+	value, err := repo.IncrementField(1, "login_count", 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+
+	reopened, err := NewFileUserRepository(path)
+	require.NoError(t, err)
+	user, err := reopened.GetUser(1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, user.LoginCount)
+}
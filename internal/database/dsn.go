@@ -0,0 +1,35 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedDSN is returned by NewRepositoryFromDSN for DSNs it cannot open
+var ErrUnsupportedDSN = errors.New("unsupported repository DSN")
+
+// NewRepositoryFromDSN opens the repository backend named by dsn:
+//
+//	memory://              an empty InMemoryUserRepository
+//	file:///var/users.json a FileUserRepository stored at the given path
+//
+// Returns ErrUnsupportedDSN for any other scheme.
+func NewRepositoryFromDSN(dsn string, opts ...RepositoryOption) (UserRepository, error) {
+	scheme, rest, ok := strings.Cut(dsn, "://")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q has no scheme", ErrUnsupportedDSN, dsn)
+	}
+
+	switch scheme {
+	case "memory":
+		return NewUserRepositoryWithOptions(opts...), nil
+	case "file":
+		if rest == "" {
+			return nil, fmt.Errorf("%w: %q has no path", ErrUnsupportedDSN, dsn)
+		}
+		return NewFileUserRepository(rest, opts...)
+	default:
+		return nil, fmt.Errorf("%w: unknown scheme %q", ErrUnsupportedDSN, scheme)
+	}
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewRepositoryFromDSN tests selecting a backend by DSN
+func TestNewRepositoryFromDSN(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name         string
+		dsn          string
+		expectedType UserRepository
+		expectedErr  error
+	}{
+		{"Memory", "memory://", &InMemoryUserRepository{}, nil},
+		{"File", "file://" + filepath.Join(dir, "users.json"), &FileUserRepository{}, nil},
+		{"File without path", "file://", nil, ErrUnsupportedDSN},
+		{"Unknown scheme", "postgres://localhost/users", nil, ErrUnsupportedDSN},
+		{"No scheme", "users.json", nil, ErrUnsupportedDSN},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, err := NewRepositoryFromDSN(tc.dsn)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, repo)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tc.expectedType, repo)
+		})
+	}
+}
+
+// TestFileUserRepository tests that users persist across reopening the file
+func TestFileUserRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	repo, err := NewRepositoryFromDSN("file://" + path)
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "the file should not be written before the first change")
+
+	alice := &User{Username: "alice", Email: "alice@example.com"}
+	bob := &User{Username: "bob", Email: "bob@example.com"}
+	require.NoError(t, repo.CreateUser(alice))
+	require.NoError(t, repo.CreateUser(bob))
+	alice.Username = "alicia"
+	require.NoError(t, repo.UpdateUser(alice))
+	require.NoError(t, repo.DeleteUser(bob.ID))
+
+	reopened, err := NewFileUserRepository(path)
+	require.NoError(t, err)
+	users, err := reopened.ListUsers()
+	require.NoError(t, err)
+	assert.Equal(t, []*User{{ID: 1, Username: "alicia", Email: "alice@example.com"}}, users)
+
+	// IDs of deleted users are not reused after reopening
+	carol := &User{Username: "carol", Email: "carol@example.com"}
+	require.NoError(t, reopened.CreateUser(carol))
+	assert.Equal(t, 3, carol.ID)
+
+	assert.ErrorIs(t, reopened.DeleteUser(99), ErrUserNotFound)
+}
+
+// TestFileUserRepositoryIDOffset tests that a new file repository honours an
+// ID offset, and a reopened one never goes back below it
+func TestFileUserRepositoryIDOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	repo, err := NewFileUserRepository(path, WithIDOffset(1000))
+	require.NoError(t, err)
+	alice := &User{Username: "alice", Email: "alice@example.com"}
+	require.NoError(t, repo.CreateUser(alice))
+	assert.Equal(t, 1000, alice.ID)
+
+	reopened, err := NewFileUserRepository(path, WithIDOffset(1000))
+	require.NoError(t, err)
+	bob := &User{Username: "bob", Email: "bob@example.com"}
+	require.NoError(t, reopened.CreateUser(bob))
+	assert.Equal(t, 1001, bob.ID)
+}
+
+// TestFileUserRepositoryImport tests that imported users are saved along
+// with an ID counter past them
+func TestFileUserRepositoryImport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	repo, err := NewFileUserRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, repo.ImportUsers([]*User{{ID: 40, Username: "alice", Email: "alice@example.com"}}))
+
+	reopened, err := NewFileUserRepository(path)
+	require.NoError(t, err)
+	bob := &User{Username: "bob", Email: "bob@example.com"}
+	require.NoError(t, reopened.CreateUser(bob))
+	assert.Equal(t, 41, bob.ID)
+}
+
+// TestFileUserRepositoryEmailEncryption tests that emails are only written
+// to disk encrypted and read back with the key
+func TestFileUserRepositoryEmailEncryption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	key := bytes.Repeat([]byte{7}, 32)
+
+	repo, err := NewFileUserRepository(path, WithEmailEncryption(key))
+	require.NoError(t, err)
+	alice := &User{Username: "alice", Email: "alice@example.com"}
+	require.NoError(t, repo.CreateUser(alice))
+	stored, err := repo.GetUser(alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", stored.Email, "only the file is encrypted")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "alice@example.com")
+	assert.Contains(t, string(data), `"username": "alice"`)
+	assert.Contains(t, string(data), encryptedEmailPrefix)
+
+	reopened, err := NewFileUserRepository(path, WithEmailEncryption(key))
+	require.NoError(t, err)
+	found, err := reopened.FindByEmail("alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, found.ID)
+
+	_, err = NewFileUserRepository(path)
+	assert.ErrorIs(t, err, ErrEmailEncrypted, "a file with encrypted emails needs the key")
+	_, err = NewFileUserRepository(path, WithEmailEncryption(bytes.Repeat([]byte{8}, 32)))
+	assert.ErrorIs(t, err, ErrEmailEncrypted, "a different key cannot decrypt the emails")
+	_, err = NewFileUserRepository(path, WithEmailEncryption([]byte("short")))
+	assert.Error(t, err)
+}
+
+// TestFileUserRepositoryEncryptsPlaintextFile tests that a file written
+// before encryption was enabled still loads
+func TestFileUserRepositoryEncryptsPlaintextFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	plain, err := NewFileUserRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, plain.CreateUser(&User{Username: "alice", Email: "alice@example.com"}))
+
+	key := bytes.Repeat([]byte{7}, 16)
+	repo, err := NewFileUserRepository(path, WithEmailEncryption(key))
+	require.NoError(t, err)
+	user, err := repo.GetUser(1)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+
+	require.NoError(t, repo.CreateUser(&User{Username: "bob", Email: "bob@example.com"}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "@example.com")
+}
+
+// TestFileUserRepositoryCorruptFile tests that an unreadable file is reported
+func TestFileUserRepositoryCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	_, err := NewFileUserRepository(path)
+	assert.ErrorContains(t, err, "decoding "+path)
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileUserRepository is an InMemoryUserRepository that persists its contents
+// to a JSON file after every change, so users survive restarts. With
+// WithFlushInterval, changes are batched and saved at most once per interval,
+// and Close must be called to save the last batch.
+type FileUserRepository struct {
+	*InMemoryUserRepository
+	path      string
+	saveMutex sync.Mutex
+	saves     int          // files written, guarded by saveMutex
+	emails    *emailCipher // nil unless emails are encrypted at rest
+
+	// With a flush interval, changes schedule a save on flushTimer rather
+	// than saving immediately
+	flushMutex   sync.Mutex
+	flushTimer   *time.Timer
+	flushErr     error // from the last scheduled save
+	pendingFlush sync.WaitGroup
+	closed       bool
+}
+
+// fileContents is the JSON layout of a FileUserRepository's file
+type fileContents struct {
+	NextID int     `json:"next_id"`
+	Users  []*User `json:"users"`
+}
+
+// NewFileUserRepository opens the repository stored at path, starting empty
+// if the file does not exist yet. The file is only written on the first change.
+func NewFileUserRepository(path string, opts ...RepositoryOption) (*FileUserRepository, error) {
+	repo := &FileUserRepository{
+		InMemoryUserRepository: NewUserRepositoryWithOptions(opts...),
+		path:                   path,
+	}
+	if key := repo.emailKey; key != nil {
+		emails, err := newEmailCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		repo.emails = emails
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var contents fileContents
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	// Restore moves the counter past the highest stored ID, so a file edited
+	// by hand with a stale next_id cannot make creates reuse IDs
+	state := RepoState{users: make(map[int]User, len(contents.Users)), nextID: max(contents.NextID, repo.nextID)}
+	for _, user := range contents.Users {
+		if user.Email, err = repo.emails.decrypt(user.Email); err != nil {
+			return nil, fmt.Errorf("decoding %s: user %d: %w", path, user.ID, err)
+		}
+		state.users[user.ID] = *user
+	}
+	repo.Restore(state)
+
+	return repo, nil
+}
+
+// CreateUser adds a new user and saves the repository
+func (r *FileUserRepository) CreateUser(user *User) error {
+	if err := r.InMemoryUserRepository.CreateUser(user); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+// UpdateUser updates an existing user and saves the repository
+func (r *FileUserRepository) UpdateUser(user *User) error {
+	if err := r.InMemoryUserRepository.UpdateUser(user); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+// UpdateUsers updates users in one batch and saves the repository once if
+// any of them changed. A failed save is reported for every updated user.
+func (r *FileUserRepository) UpdateUsers(users []*User) []error {
+	errs := r.InMemoryUserRepository.UpdateUsers(users)
+	if !slices.Contains(errs, nil) {
+		return errs
+	}
+	if err := r.persist(); err != nil {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+	}
+	return errs
+}
+
+// ImportUsers stores users with their own IDs and saves the repository
+func (r *FileUserRepository) ImportUsers(users []*User) error {
+	err := r.InMemoryUserRepository.ImportUsers(users)
+	if saveErr := r.persist(); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	return err
+}
+
+// DeleteUser removes a user and saves the repository
+func (r *FileUserRepository) DeleteUser(id int) error {
+	if err := r.InMemoryUserRepository.DeleteUser(id); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+// save writes the current contents to a temporary file and renames it over
+// the repository file, so a crash never leaves a partially written file.
+// The snapshot is taken while holding saveMutex, so the last save to finish
+// always includes every change made before it started.
+func (r *FileUserRepository) save() error {
+	r.saveMutex.Lock()
+	defer r.saveMutex.Unlock()
+
+	state := r.Snapshot()
+	contents := fileContents{NextID: state.nextID, Users: make([]*User, 0, len(state.users))}
+	for _, user := range state.users {
+		if r.emails != nil {
+			var err error
+			if user.Email, err = r.emails.encrypt(user.Email); err != nil {
+				return err
+			}
+		}
+		contents.Users = append(contents.Users, &user)
+	}
+	sort.Slice(contents.Users, func(i, j int) bool {
+		return contents.Users[i].ID < contents.Users[j].ID
+	})
+
+	data, err := json.MarshalIndent(contents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding users: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("saving %s: %w", r.path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("saving %s: %w", r.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("saving %s: %w", r.path, err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("saving %s: %w", r.path, err)
+	}
+	r.saves++
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"time"
+)
+
+// WithFlushInterval makes a FileUserRepository coalesce writes: instead of
+// saving after every change, the first change schedules a save interval
+// later that includes every change made by then. Bulk imports then write the
+// file once per interval rather than once per user, at the cost of losing up
+// to interval's worth of changes if the process dies without calling Close.
+// Repositories that are not file-backed ignore this option.
+func WithFlushInterval(interval time.Duration) RepositoryOption {
+	return func(r *InMemoryUserRepository) {
+		r.flushInterval = interval
+	}
+}
+
+// persist saves the repository, or with a flush interval, schedules a save
+// unless one is already pending. Once the repository is closed it always
+// saves immediately.
+func (r *FileUserRepository) persist() error {
+	if r.flushInterval <= 0 {
+		return r.save()
+	}
+
+	r.flushMutex.Lock()
+	defer r.flushMutex.Unlock()
+
+	if r.closed {
+		return r.save()
+	}
+	if r.flushTimer == nil {
+		r.pendingFlush.Add(1)
+		r.flushTimer = time.AfterFunc(r.flushInterval, r.flush)
+	}
+	return nil
+}
+
+// flush runs a scheduled save. Failures are logged and remembered, so Close
+// can retry them.
+func (r *FileUserRepository) flush() {
+	defer r.pendingFlush.Done()
+
+	r.flushMutex.Lock()
+	r.flushTimer = nil
+	r.flushMutex.Unlock()
+
+	err := r.save()
+	if err != nil {
+		r.logger.Error("flushing users failed", "path", r.path, "error", err)
+	}
+
+	r.flushMutex.Lock()
+	r.flushErr = err
+	r.flushMutex.Unlock()
+}
+
+// Close stops background compaction and, with a flush interval, writes any
+// changes not yet saved, so that everything changed before Close is on disk
+// when it returns. Changes made after Close are saved immediately.
+func (r *FileUserRepository) Close() error {
+	r.InMemoryUserRepository.Close()
+
+	r.flushMutex.Lock()
+	r.closed = true
+	timer := r.flushTimer
+	r.flushTimer = nil
+	r.flushMutex.Unlock()
+
+	if timer != nil && timer.Stop() {
+		// The scheduled save never started, so make it now
+		r.pendingFlush.Done()
+		return r.save()
+	}
+
+	r.pendingFlush.Wait()
+	r.flushMutex.Lock()
+	failed := r.flushErr != nil
+	r.flushMutex.Unlock()
+	if failed {
+		return r.save()
+	}
+	return nil
+}
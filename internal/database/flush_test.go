@@ -0,0 +1,82 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// savesOf returns how many times repo has written its file
+func savesOf(repo *FileUserRepository) int {
+	repo.saveMutex.Lock()
+	defer repo.saveMutex.Unlock()
+	return repo.saves
+}
+
+// TestFlushIntervalCoalescesWrites tests that a burst of creates is written
+// to the file in a bounded number of saves, and that Close saves the rest
+func TestFlushIntervalCoalescesWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	repo, err := NewFileUserRepository(path, WithFlushInterval(time.Hour))
+	require.NoError(t, err)
+
+	const n = 500
+	for i := range n {
+		require.NoError(t, repo.CreateUser(&User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i)}))
+	}
+	assert.Zero(t, savesOf(repo), "no save is due within the interval")
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, repo.Close())
+	assert.Equal(t, 1, savesOf(repo))
+
+	reopened, err := NewFileUserRepository(path)
+	require.NoError(t, err)
+	users, err := reopened.ListUsers()
+	require.NoError(t, err)
+	assert.Len(t, users, n)
+
+	// Once closed, changes are saved immediately
+	require.NoError(t, repo.DeleteUser(1))
+	assert.Equal(t, 2, savesOf(repo))
+}
+
+// TestFlushIntervalSavesInBackground tests that pending changes are written
+// once the interval passes, without waiting for Close
+func TestFlushIntervalSavesInBackground(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	repo, err := NewFileUserRepository(path, WithFlushInterval(10*time.Millisecond))
+	require.NoError(t, err)
+
+	for i := range 100 {
+		require.NoError(t, repo.CreateUser(&User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i)}))
+	}
+	assert.Eventually(t, func() bool { return savesOf(repo) > 0 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, repo.Close())
+	assert.LessOrEqual(t, savesOf(repo), 100, "every create must not save on its own")
+
+	reopened, err := NewFileUserRepository(path)
+	require.NoError(t, err)
+	users, err := reopened.ListUsers()
+	require.NoError(t, err)
+	assert.Len(t, users, 100)
+}
+
+// TestCloseWithoutChanges tests that closing an unchanged repository does
+// not write the file
+func TestCloseWithoutChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	repo, err := NewFileUserRepository(path, WithFlushInterval(time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -47,4 +47,38 @@ func (m *MockUserRepository) ListUsers() ([]*User, error) {
 	}
 	
 	return args.Get(0).([]*User), args.Error(1)
-}
\ No newline at end of file
+}
+
+// ListUsersAfter is a mocked method
+func (m *MockUserRepository) ListUsersAfter(id, limit int) ([]*User, error) {
+	args := m.Called(id, limit)
+	
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	
+	return args.Get(0).([]*User), args.Error(1)
+}
+
+// CountUsers is a mocked method
+func (m *MockUserRepository) CountUsers() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// FindByEmail is a mocked method
+func (m *MockUserRepository) FindByEmail(email string) (*User, error) {
+	args := m.Called(email)
+	
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	
+	return args.Get(0).(*User), args.Error(1)
+}
+
+// Migrate is a mocked method
+func (m *MockUserRepository) Migrate() error {
+	args := m.Called()
+	return args.Error(0)
+}
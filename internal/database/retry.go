@@ -0,0 +1,118 @@
+package database
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryingUserRepository wraps a UserRepository so that calls failing with
+// a backend error are retried up to retries times, waiting backoff before
+// the first retry and twice as long before each one after. Errors describing
+// the request, such as ErrUserNotFound or ErrEmailTaken, are returned at
+// once, as is ErrCircuitOpen from a breaker it wraps. A create that failed
+// after reaching the backend may be applied twice, so retries suit backends
+// whose failures happen before anything is written.
+type RetryingUserRepository struct {
+	repo    UserRepository
+	retries int
+	backoff time.Duration
+	sleep   func(time.Duration)
+}
+
+// NewRetryingUserRepository wraps repo so failed calls are retried up to
+// retries times, with a retries below 0 meaning 0, starting from backoff
+func NewRetryingUserRepository(repo UserRepository, retries int, backoff time.Duration) *RetryingUserRepository {
+	return &RetryingUserRepository{
+		repo:    repo,
+		retries: max(retries, 0),
+		backoff: backoff,
+		sleep:   time.Sleep,
+	}
+}
+
+// call runs fn, retrying it while it fails with a retryable error
+func (r *RetryingUserRepository) call(fn func() error) error {
+	delay := r.backoff
+	err := fn()
+	for attempt := 0; attempt < r.retries && isRetryable(err); attempt++ {
+		r.sleep(delay)
+		delay *= 2
+		err = fn()
+	}
+	return err
+}
+
+// isRetryable reports whether a call failing with err may succeed if tried again
+func isRetryable(err error) bool {
+	return isBackendFailure(err) && !errors.Is(err, ErrCircuitOpen)
+}
+
+// GetUser retrieves a user by ID, retrying failures
+func (r *RetryingUserRepository) GetUser(id int) (user *User, err error) {
+	err = r.call(func() error {
+		user, err = r.repo.GetUser(id)
+		return err
+	})
+	return user, err
+}
+
+// CreateUser adds a new user, retrying failures
+func (r *RetryingUserRepository) CreateUser(user *User) error {
+	return r.call(func() error { return r.repo.CreateUser(user) })
+}
+
+// UpdateUser updates an existing user, retrying failures
+func (r *RetryingUserRepository) UpdateUser(user *User) error {
+	return r.call(func() error { return r.repo.UpdateUser(user) })
+}
+
+// DeleteUser removes a user, retrying failures
+func (r *RetryingUserRepository) DeleteUser(id int) error {
+	return r.call(func() error { return r.repo.DeleteUser(id) })
+}
+
+// ListUsers returns all users, retrying failures
+func (r *RetryingUserRepository) ListUsers() (users []*User, err error) {
+	err = r.call(func() error {
+		users, err = r.repo.ListUsers()
+		return err
+	})
+	return users, err
+}
+
+// ListUsersAfter returns a page of users, retrying failures
+func (r *RetryingUserRepository) ListUsersAfter(id, limit int) (users []*User, err error) {
+	err = r.call(func() error {
+		users, err = r.repo.ListUsersAfter(id, limit)
+		return err
+	})
+	return users, err
+}
+
+// CountUsers counts users, retrying failures
+func (r *RetryingUserRepository) CountUsers() (count int, err error) {
+	err = r.call(func() error {
+		count, err = r.repo.CountUsers()
+		return err
+	})
+	return count, err
+}
+
+// FindByEmail looks a user up by email address, retrying failures
+func (r *RetryingUserRepository) FindByEmail(email string) (user *User, err error) {
+	err = r.call(func() error {
+		user, err = r.repo.FindByEmail(email)
+		return err
+	})
+	return user, err
+}
+
+// Migrate migrates the wrapped repository, retrying failures
+func (r *RetryingUserRepository) Migrate() error {
+	return r.call(r.repo.Migrate)
+}
+
+// Unwrap returns the repository being retried
+func (r *RetryingUserRepository) Unwrap() UserRepository {
+	return r.repo
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRetryingRepository tests that failures are retried with doubling
+// backoff until the call succeeds
+func TestRetryingRepository(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	retrying := NewRetryingUserRepository(mockRepo, 3, 10*time.Millisecond)
+	var delays []time.Duration
+	retrying.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	alice := &User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	mockRepo.On("GetUser", 1).Return(nil, assert.AnError).Twice()
+	mockRepo.On("GetUser", 1).Return(alice, nil).Once()
+
+	user, err := retrying.GetUser(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, alice, user)
+	mockRepo.AssertNumberOfCalls(t, "GetUser", 3)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, delays)
+}
+
+// TestRetryingRepositoryErrors tests which errors are retried and that the
+// last error is returned once retries run out
+func TestRetryingRepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedCalls int
+	}{
+		{"Backend failure is retried", assert.AnError, 4},
+		{"Not found is not retried", ErrUserNotFound, 1},
+		{"Email taken is not retried", ErrEmailTaken, 1},
+		{"Invalid user is not retried", ErrInvalidUser, 1},
+		{"Open circuit is not retried", ErrCircuitOpen, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			retrying := NewRetryingUserRepository(mockRepo, 3, time.Millisecond)
+			retrying.sleep = func(time.Duration) {}
+			user := &User{Username: "alice", Email: "alice@example.com"}
+			mockRepo.On("CreateUser", user).Return(tc.err)
+
+			err := retrying.CreateUser(user)
+
+			assert.ErrorIs(t, err, tc.err)
+			mockRepo.AssertNumberOfCalls(t, "CreateUser", tc.expectedCalls)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"time"
+)
+
+// deletedUser is a soft-deleted user waiting to be compacted
+type deletedUser struct {
+	user *User
+	at   time.Time
+}
+
+// WithSoftDelete makes DeleteUser keep deleted users, hidden from every
+// other method, until Compact removes those deleted more than retention ago.
+// Soft-deleted users live only in memory; a FileUserRepository never saves them.
+func WithSoftDelete(retention time.Duration) RepositoryOption {
+	return func(r *InMemoryUserRepository) {
+		r.softDelete = true
+		r.retention = retention
+	}
+}
+
+// WithClock sets the clock soft deletes are timed with, for tests
+func WithClock(now func() time.Time) RepositoryOption {
+	return func(r *InMemoryUserRepository) {
+		r.now = now
+	}
+}
+
+// Compact permanently removes users soft-deleted more than the retention
+// period ago and returns how many it removed. Without soft delete there is
+// never anything to remove.
+func (r *InMemoryUserRepository) Compact() (removed int, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cutoff := r.now().Add(-r.retention)
+	for id, deleted := range r.deleted {
+		if deleted.at.Before(cutoff) {
+			delete(r.deleted, id)
+			removed++
+		}
+	}
+	if removed > 0 {
+		r.logger.Debug("users compacted", "removed", removed)
+	}
+
+	return removed, nil
+}
+
+// minCompactionInterval bounds how often the background compaction runs,
+// however short the interval it is started with
+const minCompactionInterval = time.Millisecond
+
+// StartCompaction launches a background goroutine that calls Compact every
+// interval, or every millisecond for shorter and non-positive intervals. It
+// runs until Close is called.
+func (r *InMemoryUserRepository) StartCompaction(interval time.Duration) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(max(interval, minCompactionInterval))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.Compact()
+			case <-r.stopCompaction:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the compaction goroutine, if started, and waits for it to
+// exit. It always returns nil.
+func (r *InMemoryUserRepository) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.stopCompaction)
+	})
+	r.wg.Wait()
+	return nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSoftDelete tests that soft-deleted users are hidden like deleted ones
+func TestSoftDelete(t *testing.T) {
+	repo := NewUserRepositoryWithOptions(WithSoftDelete(time.Hour), WithUniqueEmails())
+	alice := &User{Username: "alice", Email: "alice@example.com"}
+	require.NoError(t, repo.CreateUser(alice))
+	require.NoError(t, repo.DeleteUser(alice.ID))
+
+	_, err := repo.GetUser(alice.ID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	_, err = repo.FindByEmail("alice@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	users, err := repo.ListUsers()
+	require.NoError(t, err)
+	assert.Empty(t, users)
+	assert.ErrorIs(t, repo.DeleteUser(alice.ID), ErrUserNotFound)
+	assert.ErrorIs(t, repo.UpdateUser(alice), ErrUserNotFound)
+
+	// The email address is free again, and the ID is not reused
+	again := &User{Username: "alice2", Email: "alice@example.com"}
+	require.NoError(t, repo.CreateUser(again))
+	assert.Equal(t, 2, again.ID)
+}
+
+// TestCompact tests that only users deleted longer than the retention
+// period ago are compacted
+func TestCompact(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := NewUserRepositoryWithOptions(WithSoftDelete(time.Hour), WithClock(func() time.Time { return now }))
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, repo.CreateUser(&User{Username: name, Email: name + "@example.com"}))
+	}
+	require.NoError(t, repo.DeleteUser(1))
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, repo.DeleteUser(2))
+
+	removed, err := repo.Compact()
+	require.NoError(t, err)
+	assert.Zero(t, removed, "nothing has been deleted for longer than an hour yet")
+
+	now = now.Add(45 * time.Minute)
+	removed, err = repo.Compact()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Len(t, repo.deleted, 1)
+
+	now = now.Add(time.Hour)
+	removed, err = repo.Compact()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Empty(t, repo.deleted)
+
+	users, err := repo.ListUsers()
+	require.NoError(t, err)
+	assert.Len(t, users, 1, "live users are never compacted")
+
+	// Without soft delete there is nothing to compact
+	removed, err = NewUserRepository().Compact()
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
+
+// TestStartCompaction tests that the background compaction removes expired
+// users and stops on Close
+func TestStartCompaction(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var elapsed atomic.Int64
+	clock := func() time.Time { return now.Add(time.Duration(elapsed.Load())) }
+	repo := NewUserRepositoryWithOptions(WithSoftDelete(time.Hour), WithClock(clock))
+
+	require.NoError(t, repo.CreateUser(&User{Username: "alice", Email: "alice@example.com"}))
+	require.NoError(t, repo.DeleteUser(1))
+
+	repo.StartCompaction(time.Millisecond)
+	elapsed.Store(int64(2 * time.Hour))
+	assert.Eventually(t, func() bool {
+		repo.mutex.RLock()
+		defer repo.mutex.RUnlock()
+		return len(repo.deleted) == 0
+	}, time.Second, time.Millisecond)
+
+	repo.Close()
+	repo.Close()
+}
+
+// TestStartCompactionShortInterval tests that intervals too short for a
+// ticker, such as half of a 1ns retention, still start the compaction
+func TestStartCompactionShortInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second, time.Nanosecond} {
+		repo := NewUserRepositoryWithOptions(WithSoftDelete(time.Nanosecond))
+		require.NoError(t, repo.CreateUser(&User{Username: "alice", Email: "alice@example.com"}))
+		require.NoError(t, repo.DeleteUser(1))
+
+		require.NotPanics(t, func() { repo.StartCompaction(interval) })
+		assert.Eventually(t, func() bool {
+			repo.mutex.RLock()
+			defer repo.mutex.RUnlock()
+			return len(repo.deleted) == 0
+		}, time.Second, time.Millisecond, interval)
+
+		repo.Close()
+	}
+}
@@ -0,0 +1,47 @@
+package database
+
+import "unsafe"
+
+// RepositoryStats summarises a repository's internal state for debugging
+type RepositoryStats struct {
+	// Users counts live users; SoftDeleted counts those awaiting compaction
+	Users       int
+	SoftDeleted int
+	// NextID is the ID the next created user will get
+	NextID int
+	// ApproxBytes is a rough estimate of the memory held by users and the
+	// indexes over them
+	ApproxBytes int64
+}
+
+// StatsReporter is implemented by repositories that can report their
+// RepositoryStats
+type StatsReporter interface {
+	Stats() RepositoryStats
+}
+
+// userOverhead estimates the bytes a user costs besides its strings: the
+// User itself plus its entries in the users, emails and byEmail maps
+const userOverhead = int64(unsafe.Sizeof(User{})) + 3*16
+
+// Stats reports how many users the repository holds and roughly how much
+// memory they take
+func (r *InMemoryUserRepository) Stats() RepositoryStats {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stats := RepositoryStats{
+		Users:       len(r.users),
+		SoftDeleted: len(r.deleted),
+		NextID:      r.nextID,
+	}
+	for _, user := range r.users {
+		// The email is held again as the index key
+		stats.ApproxBytes += userOverhead + int64(len(user.Username)+2*len(user.Email))
+	}
+	for _, deleted := range r.deleted {
+		stats.ApproxBytes += int64(unsafe.Sizeof(deleted)) + userOverhead +
+			int64(len(deleted.user.Username)+len(deleted.user.Email))
+	}
+	return stats
+}
@@ -1,8 +1,29 @@
 package database
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUserNotFound is returned when no user has the requested ID
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidUser is returned by Validate for users with missing or malformed fields
+	ErrInvalidUser = errors.New("invalid user")
+	// ErrEmailTaken is returned when storing a user whose email address,
+	// compared case-insensitively, already belongs to another user
+	ErrEmailTaken = errors.New("email already in use")
+	// ErrCapacityExceeded is returned when creating a user in a repository
+	// that already holds as many users as it is allowed
+	ErrCapacityExceeded = errors.New("user capacity exceeded")
 )
 
 // User represents a user in the system
@@ -10,6 +31,34 @@ type User struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	// LoginCount is a counter maintained through IncrementField. New users
+	// start at 0 and updates keep the stored count, whatever the user passed.
+	LoginCount int `json:"login_count,omitempty"`
+}
+
+// Normalize trims the email address and lowercases it, so equal addresses
+// are stored identically. Strictly the local part is case-sensitive, but no
+// mail provider we deal with treats it so.
+func (u *User) Normalize() {
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+}
+
+// Validate checks that the user has a username and a well-formed email
+// address, both valid UTF-8 so they survive being encoded as JSON
+func (u *User) Validate() error {
+	if !utf8.ValidString(u.Username) {
+		return fmt.Errorf("%w: username is not valid UTF-8", ErrInvalidUser)
+	}
+	if !utf8.ValidString(u.Email) {
+		return fmt.Errorf("%w: email is not valid UTF-8", ErrInvalidUser)
+	}
+	if strings.TrimSpace(u.Username) == "" {
+		return fmt.Errorf("%w: username is required", ErrInvalidUser)
+	}
+	if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+		return fmt.Errorf("%w: email is not a valid address", ErrInvalidUser)
+	}
+	return nil
 }
 
 // UserRepository interface defines methods for user data operations
@@ -19,6 +68,23 @@ type UserRepository interface {
 	UpdateUser(user *User) error
 	DeleteUser(id int) error
 	ListUsers() ([]*User, error)
+	// ListUsersAfter returns up to limit users with IDs greater than id,
+	// ordered by ID, or every such user when limit is 0
+	ListUsersAfter(id, limit int) ([]*User, error)
+	// CountUsers returns how many users there are, without listing them
+	CountUsers() (int, error)
+	// FindByEmail returns the user with the given email address, compared
+	// case-insensitively, or ErrUserNotFound
+	FindByEmail(email string) (*User, error)
+	// Migrate brings the backing store's schema up to date. It is called
+	// once at startup and must be safe to run repeatedly.
+	Migrate() error
+}
+
+// BulkUpdater is implemented by repositories that can update many users at
+// once. UpdateUsers returns one error per user, in input order.
+type BulkUpdater interface {
+	UpdateUsers(users []*User) []error
 }
 
 // InMemoryUserRepository implements UserRepository with an in-memory storage
@@ -26,17 +92,131 @@ type InMemoryUserRepository struct {
 	users map[int]*User
 	mutex sync.RWMutex
 	nextID int
+	logger *slog.Logger
+	uniqueEmails bool
+	capacity int // most users held; 0 means unlimited
+	
+	// byEmail indexes user IDs by normalized email; emails records the key
+	// each user is indexed under, since callers may mutate stored users
+	byEmail map[string]map[int]struct{}
+	emails  map[int]string
+	
+	// With soft delete, deleted users wait in deleted until compacted
+	now        func() time.Time
+	softDelete bool
+	retention  time.Duration
+	deleted    map[int]deletedUser
+	
+	// emailKey encrypts emails at rest and flushInterval batches writes;
+	// only file-backed repositories use them
+	emailKey      []byte
+	flushInterval time.Duration
+	
+	stopCompaction chan struct{}
+	closeOnce      sync.Once
+	wg             sync.WaitGroup
+	
+	// seed holds the users WithSeed creates once every option is applied
+	seed []*User
 }
 
-// NewUserRepository creates a new InMemoryUserRepository
-func NewUserRepository() *InMemoryUserRepository {
-	return &InMemoryUserRepository{
-		users:  make(map[int]*User),
-		mutex:  sync.RWMutex{},
-		nextID: 1,
+// RepositoryOption configures an InMemoryUserRepository
+type RepositoryOption func(*InMemoryUserRepository)
+
+// WithLogger logs mutations at debug level and lookups of missing users at
+// info level, with the user ID as the "id" attribute
+func WithLogger(logger *slog.Logger) RepositoryOption {
+	return func(r *InMemoryUserRepository) {
+		r.logger = logger
+	}
+}
+
+// WithUniqueEmails rejects creating or updating a user whose email address,
+// compared case-insensitively, belongs to another user with ErrEmailTaken
+func WithUniqueEmails() RepositoryOption {
+	return func(r *InMemoryUserRepository) {
+		r.uniqueEmails = true
+	}
+}
+
+// WithSeed creates users when the repository is constructed, assigning IDs
+// sequentially in argument order. Seed users are normalized, validated and
+// checked against the other options, such as WithUniqueEmails, like any
+// created user; construction panics with the reason if one is refused.
+func WithSeed(users ...*User) RepositoryOption {
+	return func(r *InMemoryUserRepository) {
+		r.seed = append(r.seed, users...)
+	}
+}
+
+// WithCapacity limits the repository to limit users; creating more fails
+// with ErrCapacityExceeded. A limit below 1 means unlimited.
+func WithCapacity(limit int) RepositoryOption {
+	return func(r *InMemoryUserRepository) {
+		r.capacity = max(limit, 0)
 	}
 }
 
+// WithIDOffset makes the repository assign IDs starting at first rather than
+// 1, so repositories given disjoint ranges (say 1 and 1000000) can later be
+// merged without ID collisions. A first below 1 is ignored.
+func WithIDOffset(first int) RepositoryOption {
+	return func(r *InMemoryUserRepository) {
+		if first > 0 {
+			r.nextID = first
+		}
+	}
+}
+
+// NewUserRepositoryWithOptions creates an empty InMemoryUserRepository configured by opts
+func NewUserRepositoryWithOptions(opts ...RepositoryOption) *InMemoryUserRepository {
+	repo := &InMemoryUserRepository{
+		users:   make(map[int]*User),
+		mutex:   sync.RWMutex{},
+		nextID:  1,
+		logger:  slog.New(slog.DiscardHandler),
+		byEmail: make(map[string]map[int]struct{}),
+		emails:  make(map[int]string),
+		now:     time.Now,
+		deleted: make(map[int]deletedUser),
+		
+		stopCompaction: make(chan struct{}),
+	}
+	
+	for _, opt := range opts {
+		opt(repo)
+	}
+	for i, user := range repo.seed {
+		if err := repo.createSeed(user); err != nil {
+			panic(fmt.Sprintf("database: seed user %d (%q): %v", i, user.Email, err))
+		}
+	}
+	repo.seed = nil
+	
+	return repo
+}
+
+// createSeed creates a seed user with the same checks as a created one
+func (r *InMemoryUserRepository) createSeed(user *User) error {
+	user.Normalize()
+	if err := user.Validate(); err != nil {
+		return err
+	}
+	return r.CreateUser(user)
+}
+
+// NewUserRepositoryWithLimit creates an empty InMemoryUserRepository that
+// holds at most limit users, for simulating a full store
+func NewUserRepositoryWithLimit(limit int) *InMemoryUserRepository {
+	return NewUserRepositoryWithOptions(WithCapacity(limit))
+}
+
+// NewUserRepository creates a new InMemoryUserRepository, optionally seeded
+// with users as WithSeed describes
+func NewUserRepository(seed ...*User) *InMemoryUserRepository {
+	return NewUserRepositoryWithOptions(WithSeed(seed...))
+}
+
 // GetUser retrieves a user by ID
 func (r *InMemoryUserRepository) GetUser(id int) (*User, error) {
 	r.mutex.RLock()
@@ -44,64 +224,335 @@ func (r *InMemoryUserRepository) GetUser(id int) (*User, error) {
 	
 	user, exists := r.users[id]
 	if !exists {
-		return nil, errors.New("user not found")
+		r.logger.Info("user not found", "op", "get", "id", id)
+		return nil, ErrUserNotFound
 	}
 	
 	return user, nil
 }
 
 // CreateUser adds a new user to the repository
+// Returns ErrEmailTaken if emails are unique and another user has the same
+// one, or ErrCapacityExceeded if the repository is full
 func (r *InMemoryUserRepository) CreateUser(user *User) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
+	if r.capacity > 0 && len(r.users) >= r.capacity {
+		r.logger.Info("user capacity exceeded", "capacity", r.capacity)
+		return ErrCapacityExceeded
+	}
+	if r.emailTaken(user.Email, 0) {
+		return ErrEmailTaken
+	}
+	r.create(user)
+	
+	return nil
+}
+
+// create assigns the next ID and stores the user; callers hold the write lock
+func (r *InMemoryUserRepository) create(user *User) {
 	// Assign a new ID
 	user.ID = r.nextID
 	r.nextID++
+	user.LoginCount = 0
 	
 	// Store the user
 	r.users[user.ID] = user
+	r.indexEmail(user)
 	
-	return nil
+	r.logger.Debug("user created", "id", user.ID)
 }
 
 // UpdateUser updates an existing user
+// Returns ErrEmailTaken if emails are unique and another user has the same one
 func (r *InMemoryUserRepository) UpdateUser(user *User) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
-	if _, exists := r.users[user.ID]; !exists {
-		return errors.New("user not found")
+	return r.update(user)
+}
+
+// UpdateUsers updates each of users under a single lock, so no other change
+// interleaves with the batch. The returned errors are in input order, nil
+// where the update succeeded and ErrUserNotFound or ErrEmailTaken where not.
+func (r *InMemoryUserRepository) UpdateUsers(users []*User) []error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	
+	errs := make([]error, len(users))
+	for i, user := range users {
+		errs[i] = r.update(user)
+	}
+	
+	return errs
+}
+
+// update stores user over the existing user with its ID; callers hold the write lock
+func (r *InMemoryUserRepository) update(user *User) error {
+	existing, exists := r.users[user.ID]
+	if !exists {
+		r.logger.Info("user not found", "op", "update", "id", user.ID)
+		return ErrUserNotFound
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return ErrEmailTaken
 	}
+	user.LoginCount = existing.LoginCount
 	
 	r.users[user.ID] = user
+	r.unindexEmail(user.ID)
+	r.indexEmail(user)
+	r.logger.Debug("user updated", "id", user.ID)
 	
 	return nil
 }
 
-// DeleteUser removes a user from the repository
+// DeleteUser removes a user from the repository. With soft delete the user
+// is kept, invisible to every other method, until Compact removes it.
 func (r *InMemoryUserRepository) DeleteUser(id int) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if _, exists := r.users[id]; !exists {
-		return errors.New("user not found")
+		r.logger.Info("user not found", "op", "delete", "id", id)
+		return ErrUserNotFound
 	}
 	
+	if r.softDelete {
+		r.deleted[id] = deletedUser{user: r.users[id], at: r.now()}
+	}
 	delete(r.users, id)
+	r.unindexEmail(id)
+	r.logger.Debug("user deleted", "id", id, "soft", r.softDelete)
 	
 	return nil
 }
 
-// ListUsers returns all users in the repository
+// FindByEmail returns the user with the given email address, compared
+// case-insensitively. If several users share it, the one with the lowest ID
+// is returned.
+func (r *InMemoryUserRepository) FindByEmail(email string) (*User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	found := 0
+	for id := range r.byEmail[emailKey(email)] {
+		if found == 0 || id < found {
+			found = id
+		}
+	}
+	if found == 0 {
+		r.logger.Info("user not found", "op", "find-by-email")
+		return nil, ErrUserNotFound
+	}
+	
+	return r.users[found], nil
+}
+
+// emailKey normalizes an email address for the index
+func emailKey(email string) string {
+	return strings.ToLower(email)
+}
+
+// emailTaken reports whether emails are unique and a user other than the
+// one with id has email; callers hold the lock
+func (r *InMemoryUserRepository) emailTaken(email string, id int) bool {
+	if !r.uniqueEmails {
+		return false
+	}
+	for other := range r.byEmail[emailKey(email)] {
+		if other != id {
+			return true
+		}
+	}
+	return false
+}
+
+// indexEmail adds user to the email index; callers hold the write lock
+func (r *InMemoryUserRepository) indexEmail(user *User) {
+	key := emailKey(user.Email)
+	if r.byEmail[key] == nil {
+		r.byEmail[key] = make(map[int]struct{})
+	}
+	r.byEmail[key][user.ID] = struct{}{}
+	r.emails[user.ID] = key
+}
+
+// unindexEmail removes the user with id from the email index; callers hold
+// the write lock
+func (r *InMemoryUserRepository) unindexEmail(id int) {
+	key, ok := r.emails[id]
+	if !ok {
+		return
+	}
+	delete(r.byEmail[key], id)
+	if len(r.byEmail[key]) == 0 {
+		delete(r.byEmail, key)
+	}
+	delete(r.emails, id)
+}
+
+// ListUsers returns all users in the repository ordered by ID
 func (r *InMemoryUserRepository) ListUsers() ([]*User, error) {
+	return r.FilterUsers(func(*User) bool { return true })
+}
+
+// ListUsersAfter returns up to limit users with IDs greater than id, ordered
+// by ID, or every such user when limit is 0. Paging by the last ID seen never
+// skips or repeats users when others are created or deleted in between.
+func (r *InMemoryUserRepository) ListUsersAfter(id, limit int) ([]*User, error) {
+	users, err := r.FilterUsers(func(user *User) bool { return user.ID > id })
+	if limit > 0 && len(users) > limit {
+		users = users[:limit]
+	}
+	return users, err
+}
+
+// CountUsers returns how many users the repository holds, not counting
+// soft-deleted ones
+func (r *InMemoryUserRepository) CountUsers() (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	return len(r.users), nil
+}
+
+// FilterUsers returns the users for which pred returns true, ordered by ID.
+// It is the single iteration primitive behind listing; it is deliberately not
+// part of UserRepository since a predicate cannot cross a process boundary.
+// pred is called with the repository locked and must not call back into it.
+func (r *InMemoryUserRepository) FilterUsers(pred func(*User) bool) ([]*User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	users := make([]*User, 0, len(r.users))
 	for _, user := range r.users {
-		users = append(users, user)
+		if pred(user) {
+			users = append(users, user)
+		}
 	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
 	
 	return users, nil
-}
\ No newline at end of file
+}
+
+// Name identifies the repository in health reports
+func (r *InMemoryUserRepository) Name() string {
+	return "user-repository"
+}
+
+// Check reports the repository healthy as long as its lock can be taken,
+// which fails only if ctx is done first
+func (r *InMemoryUserRepository) Check(ctx context.Context) error {
+	locked := make(chan struct{})
+	go func() {
+		r.mutex.RLock()
+		r.mutex.RUnlock()
+		close(locked)
+	}()
+	
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Migrate is a no-op: the in-memory repository has no schema to evolve
+func (r *InMemoryUserRepository) Migrate() error {
+	return nil
+}
+
+// RepoState is a point-in-time copy of an InMemoryUserRepository's contents
+type RepoState struct {
+	users  map[int]User
+	nextID int
+}
+
+// Snapshot captures the current repository state so it can be restored later
+func (r *InMemoryUserRepository) Snapshot() RepoState {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	state := RepoState{
+		users:  make(map[int]User, len(r.users)),
+		nextID: r.nextID,
+	}
+	for id, user := range r.users {
+		state.users[id] = *user
+	}
+	
+	return state
+}
+
+// Restore replaces the repository contents with a previously captured state.
+// Soft-deleted users are not part of a state, so Restore discards them.
+func (r *InMemoryUserRepository) Restore(state RepoState) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	
+	r.users = make(map[int]*User, len(state.users))
+	r.deleted = make(map[int]deletedUser)
+	r.byEmail = make(map[string]map[int]struct{})
+	r.emails = make(map[int]string, len(state.users))
+	for id, user := range state.users {
+		r.users[id] = &user
+		r.indexEmail(&user)
+	}
+	r.nextID = state.nextID
+	r.reindexNextID()
+	
+	r.logger.Debug("repository restored", "users", len(r.users))
+}
+
+// ImportUsers stores users with the IDs they already carry, replacing any
+// user with the same ID, and then moves the ID counter past the highest ID
+// so later creates cannot collide with an imported user. Every ID must be
+// positive. With unique emails an import stops at the first user whose email
+// belongs to another user, returning ErrEmailTaken; the users before it stay imported.
+func (r *InMemoryUserRepository) ImportUsers(users []*User) error {
+	for _, user := range users {
+		if user.ID <= 0 {
+			return fmt.Errorf("%w: imported users need a positive id, got %d", ErrInvalidUser, user.ID)
+		}
+	}
+	
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	defer r.reindexNextID()
+	
+	for _, user := range users {
+		if r.emailTaken(user.Email, user.ID) {
+			return fmt.Errorf("%w: importing user %d", ErrEmailTaken, user.ID)
+		}
+		r.users[user.ID] = user
+		delete(r.deleted, user.ID)
+		r.unindexEmail(user.ID)
+		r.indexEmail(user)
+	}
+	r.logger.Debug("users imported", "users", len(users))
+	
+	return nil
+}
+
+// ReindexNextID moves the ID counter past the highest stored ID. Imports
+// call it themselves; it is only needed after changing users behind the
+// repository's back. The counter never moves backwards, so IDs of deleted
+// users are not handed out again.
+func (r *InMemoryUserRepository) ReindexNextID() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	
+	r.reindexNextID()
+}
+
+// reindexNextID implements ReindexNextID; callers hold the write lock
+func (r *InMemoryUserRepository) reindexNextID() {
+	for id := range r.users {
+		r.nextID = max(r.nextID, id+1)
+	}
+}
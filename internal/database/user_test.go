@@ -1,9 +1,15 @@
 package database
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestGetUser tests the GetUser method
@@ -92,6 +98,48 @@ func TestUpdateUser(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found")
 }
 
+// TestUpdateUsers tests that UpdateUsers reports an error per user in input order
+func TestUpdateUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []int
+		expectedErr []error
+	}{
+		{"All present", []int{2, 1}, []error{nil, nil}},
+		{"Missing ID", []int{1, 99, 2}, []error{nil, ErrUserNotFound, nil}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewUserRepository(
+				&User{Username: "alice", Email: "alice@example.com"},
+				&User{Username: "bob", Email: "bob@example.com"},
+			)
+
+			users := make([]*User, len(tc.ids))
+			for i, id := range tc.ids {
+				users[i] = &User{ID: id, Username: fmt.Sprintf("renamed%d", id), Email: fmt.Sprintf("renamed%d@example.com", id)}
+			}
+
+			errs := repo.UpdateUsers(users)
+			require.Len(t, errs, len(tc.ids))
+			for i, err := range errs {
+				assert.ErrorIs(t, err, tc.expectedErr[i], "index %d", i)
+				if tc.expectedErr[i] != nil {
+					continue
+				}
+				stored, err := repo.GetUser(tc.ids[i])
+				require.NoError(t, err)
+				assert.Equal(t, users[i].Username, stored.Username)
+			}
+
+			all, err := repo.ListUsers()
+			require.NoError(t, err)
+			assert.Len(t, all, 2, "a missing ID must not create a user")
+		})
+	}
+}
+
 // TestDeleteUser tests the DeleteUser method
 func TestDeleteUser(t *testing.T) {
 	repo := NewUserRepository()
@@ -148,4 +196,446 @@ func TestListUsers(t *testing.T) {
 	users, err = repo.ListUsers()
 	assert.NoError(t, err)
 	assert.Len(t, users, userCount)
-}
\ No newline at end of file
+	
+	// Users are ordered by ID so pages are stable
+	for i, user := range users {
+		assert.Equal(t, i+1, user.ID)
+	}
+}
+
+// TestListUsersAfter tests paging through users by the last ID seen
+func TestListUsersAfter(t *testing.T) {
+	repo := NewUserRepository()
+	for _, name := range []string{"alice", "bob", "carol", "dave"} {
+		require.NoError(t, repo.CreateUser(&User{Username: name, Email: name + "@example.com"}))
+	}
+	require.NoError(t, repo.DeleteUser(2))
+	
+	tests := []struct {
+		name        string
+		after       int
+		limit       int
+		expectedIDs []int
+	}{
+		{"From the start", 0, 2, []int{1, 3}},
+		{"Skips deleted IDs", 1, 2, []int{3, 4}},
+		{"Last page", 3, 2, []int{4}},
+		{"Past the end", 4, 2, []int{}},
+		{"No limit", 0, 0, []int{1, 3, 4}},
+	}
+	
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			users, err := repo.ListUsersAfter(tc.after, tc.limit)
+			require.NoError(t, err)
+			
+			ids := make([]int, 0, len(users))
+			for _, user := range users {
+				ids = append(ids, user.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+// TestCountUsers tests that counts follow creates and deletes
+func TestCountUsers(t *testing.T) {
+	repo := NewUserRepository()
+	
+	count, err := repo.CountUsers()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	
+	for _, name := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, repo.CreateUser(&User{Username: name, Email: name + "@example.com"}))
+	}
+	require.NoError(t, repo.DeleteUser(2))
+	
+	count, err = repo.CountUsers()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+// TestSnapshotRestore tests that Restore rolls back changes made after Snapshot
+func TestSnapshotRestore(t *testing.T) {
+	repo := NewUserRepository()
+	
+	original := &User{Username: "original", Email: "original@example.com"}
+	err := repo.CreateUser(original)
+	assert.NoError(t, err)
+	
+	state := repo.Snapshot()
+	
+	// Mutate the repository: update, delete and create
+	err = repo.UpdateUser(&User{ID: original.ID, Username: "changed", Email: "changed@example.com"})
+	assert.NoError(t, err)
+	err = repo.CreateUser(&User{Username: "extra", Email: "extra@example.com"})
+	assert.NoError(t, err)
+	err = repo.DeleteUser(original.ID)
+	assert.NoError(t, err)
+	
+	repo.Restore(state)
+	
+	users, err := repo.ListUsers()
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	
+	restored, err := repo.GetUser(original.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "original", restored.Username)
+	assert.Equal(t, "original@example.com", restored.Email)
+	
+	// IDs continue from the snapshot, not from the discarded mutations
+	next := &User{Username: "next", Email: "next@example.com"}
+	err = repo.CreateUser(next)
+	assert.NoError(t, err)
+	assert.Equal(t, original.ID+1, next.ID)
+}
+
+// TestNewUserRepositoryWithSeed tests seeding the repository at construction
+func TestNewUserRepositoryWithSeed(t *testing.T) {
+	repo := NewUserRepository(
+		&User{Username: "alice", Email: "alice@example.com"},
+		&User{Username: "bob", Email: "bob@example.com"},
+		&User{Username: "carol", Email: "carol@example.com"},
+	)
+	
+	users, err := repo.ListUsers()
+	assert.NoError(t, err)
+	assert.Len(t, users, 3)
+	
+	// Seed users get sequential IDs in argument order
+	for id, username := range map[int]string{1: "alice", 2: "bob", 3: "carol"} {
+		user, err := repo.GetUser(id)
+		assert.NoError(t, err)
+		assert.Equal(t, username, user.Username)
+	}
+	
+	// New users continue after the seed
+	user := &User{Username: "dave", Email: "dave@example.com"}
+	assert.NoError(t, repo.CreateUser(user))
+	assert.Equal(t, 4, user.ID)
+}
+
+// TestSeedChecks tests that seed users are normalized and refused like
+// created ones, whichever order the options come in
+func TestSeedChecks(t *testing.T) {
+	alice := func() *User { return &User{Username: "alice", Email: " Alice@Example.com"} }
+	
+	repo := NewUserRepositoryWithOptions(WithSeed(alice()), WithUniqueEmails())
+	user, err := repo.FindByEmail("alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+	
+	assert.PanicsWithValue(t, `database: seed user 1 ("alice@example.com"): email already in use`, func() {
+		NewUserRepositoryWithOptions(WithSeed(alice(), alice()), WithUniqueEmails())
+	})
+	assert.PanicsWithValue(t, `database: seed user 1 ("bob@example.com"): user capacity exceeded`, func() {
+		NewUserRepositoryWithOptions(WithCapacity(1), WithSeed(alice(), &User{Username: "bob", Email: "bob@example.com"}))
+	})
+	assert.PanicsWithValue(t, `database: seed user 0 ("bob"): invalid user: email is not a valid address`, func() {
+		NewUserRepository(&User{Username: "bob", Email: "bob"})
+	})
+	
+	// Without unique emails, duplicates are accepted as CreateUser accepts them
+	assert.NotPanics(t, func() { NewUserRepository(alice(), alice()) })
+}
+
+// TestRepositoryLogger tests the structured log output of the repository
+func TestRepositoryLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := NewUserRepositoryWithOptions(WithLogger(logger))
+	
+	err := repo.CreateUser(&User{Username: "logged", Email: "logged@example.com"})
+	assert.NoError(t, err)
+	
+	_, err = repo.GetUser(42)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	
+	// One JSON record per line
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var record map[string]interface{}
+		assert.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	
+	assert.Len(t, records, 2)
+	assert.Equal(t, "DEBUG", records[0]["level"])
+	assert.Equal(t, "user created", records[0]["msg"])
+	assert.Equal(t, float64(1), records[0]["id"])
+	
+	assert.Equal(t, "INFO", records[1]["level"])
+	assert.Equal(t, "user not found", records[1]["msg"])
+	assert.Equal(t, float64(42), records[1]["id"])
+}
+
+// TestMigrate tests that migrating the in-memory repository is a harmless no-op
+func TestMigrate(t *testing.T) {
+	repo := NewUserRepository(&User{Username: "user1", Email: "user1@example.com"})
+
+	assert.NoError(t, repo.Migrate())
+	assert.NoError(t, repo.Migrate(), "Migrate should be idempotent")
+
+	users, err := repo.ListUsers()
+	assert.NoError(t, err)
+	assert.Len(t, users, 1, "Migrate should not touch existing data")
+}
+
+// TestFilterUsers tests selecting users with a predicate
+func TestFilterUsers(t *testing.T) {
+	repo := NewUserRepository(
+		&User{Username: "alice", Email: "alice@example.com"},
+		&User{Username: "bob", Email: "bob@other.org"},
+		&User{Username: "carol", Email: "carol@example.com"},
+	)
+
+	tests := []struct {
+		name        string
+		pred        func(*User) bool
+		expectedIDs []int
+	}{
+		{"All", func(*User) bool { return true }, []int{1, 2, 3}},
+		{"None", func(*User) bool { return false }, []int{}},
+		{"By email domain", func(u *User) bool { return strings.HasSuffix(u.Email, "@example.com") }, []int{1, 3}},
+		{"By ID", func(u *User) bool { return u.ID == 2 }, []int{2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			users, err := repo.FilterUsers(tc.pred)
+			assert.NoError(t, err)
+			
+			ids := make([]int, 0, len(users))
+			for _, user := range users {
+				ids = append(ids, user.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+// TestFindByEmail tests looking users up through the email index
+func TestFindByEmail(t *testing.T) {
+	repo := NewUserRepository(
+		&User{Username: "alice", Email: "alice@example.com"},
+		&User{Username: "bob", Email: "Bob@Example.com"},
+	)
+
+	tests := []struct {
+		name       string
+		email      string
+		expectedID int
+	}{
+		{"Exact", "alice@example.com", 1},
+		{"Different case", "ALICE@example.COM", 1},
+		{"Stored with capitals", "bob@example.com", 2},
+		{"Absent", "carol@example.com", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user, err := repo.FindByEmail(tc.email)
+			
+			if tc.expectedID == 0 {
+				assert.ErrorIs(t, err, ErrUserNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedID, user.ID)
+		})
+	}
+}
+
+// TestFindByEmailIndexMaintained tests that updates, deletes and restores
+// keep the email index in step with the users
+func TestFindByEmailIndexMaintained(t *testing.T) {
+	repo := NewUserRepository(&User{Username: "alice", Email: "alice@example.com"})
+	state := repo.Snapshot()
+	
+	// Mutating the stored user in place must not confuse the index
+	user, err := repo.GetUser(1)
+	assert.NoError(t, err)
+	user.Email = "alicia@example.com"
+	assert.NoError(t, repo.UpdateUser(user))
+	
+	_, err = repo.FindByEmail("alice@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	found, err := repo.FindByEmail("alicia@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.ID)
+	
+	assert.NoError(t, repo.DeleteUser(1))
+	_, err = repo.FindByEmail("alicia@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	
+	repo.Restore(state)
+	found, err = repo.FindByEmail("alice@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.ID)
+}
+
+// TestUserNormalize tests normalizing email addresses
+func TestUserNormalize(t *testing.T) {
+	tests := []struct {
+		email    string
+		expected string
+	}{
+		{"Test@Example.com", "test@example.com"},
+		{"  padded@example.com\t", "padded@example.com"},
+		{"already@example.com", "already@example.com"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.email, func(t *testing.T) {
+			user := &User{Username: "user", Email: tc.email}
+			user.Normalize()
+			assert.Equal(t, tc.expected, user.Email)
+		})
+	}
+}
+
+// TestUniqueEmails tests rejecting users whose email is already taken
+func TestUniqueEmails(t *testing.T) {
+	repo := NewUserRepositoryWithOptions(WithUniqueEmails())
+	assert.NoError(t, repo.CreateUser(&User{Username: "test", Email: "test@example.com"}))
+	other := &User{Username: "other", Email: "other@example.com"}
+	assert.NoError(t, repo.CreateUser(other))
+	
+	assert.ErrorIs(t, repo.CreateUser(&User{Username: "dup", Email: "Test@Example.com"}), ErrEmailTaken)
+	assert.ErrorIs(t, repo.UpdateUser(&User{ID: other.ID, Username: "other", Email: "TEST@example.com"}), ErrEmailTaken)
+	
+	// A user keeps its own email across updates, and freed emails can be reused
+	assert.NoError(t, repo.UpdateUser(&User{ID: 1, Username: "renamed", Email: "test@example.com"}))
+	assert.NoError(t, repo.DeleteUser(1))
+	assert.NoError(t, repo.CreateUser(&User{Username: "dup", Email: "test@example.com"}))
+	
+	// Without the option duplicates are allowed
+	assert.NoError(t, NewUserRepository().CreateUser(&User{Username: "a", Email: "a@example.com"}))
+	lenient := NewUserRepository(&User{Username: "a", Email: "a@example.com"})
+	assert.NoError(t, lenient.CreateUser(&User{Username: "b", Email: "A@example.com"}))
+}
+
+// TestUserRepositoryLimit tests that creating users fails once the limit is reached
+func TestUserRepositoryLimit(t *testing.T) {
+	repo := NewUserRepositoryWithLimit(2)
+	
+	assert.NoError(t, repo.CreateUser(&User{Username: "alice", Email: "alice@example.com"}))
+	assert.NoError(t, repo.CreateUser(&User{Username: "bob", Email: "bob@example.com"}))
+	
+	carol := &User{Username: "carol", Email: "carol@example.com"}
+	assert.ErrorIs(t, repo.CreateUser(carol), ErrCapacityExceeded)
+	assert.Equal(t, 0, carol.ID, "a rejected user must not be assigned an ID")
+	
+	// Deleting a user frees room for another
+	assert.NoError(t, repo.DeleteUser(1))
+	assert.NoError(t, repo.CreateUser(carol))
+	assert.Equal(t, 3, carol.ID)
+	
+	// Updates are not affected by the limit
+	carol.Username = "caroline"
+	assert.NoError(t, repo.UpdateUser(carol))
+}
+
+// TestIDOffset tests that repositories with different offsets assign
+// non-overlapping IDs
+func TestIDOffset(t *testing.T) {
+	shardA := NewUserRepositoryWithOptions(WithIDOffset(1))
+	shardB := NewUserRepositoryWithOptions(WithIDOffset(1_000_000))
+	
+	ids := map[int]bool{}
+	for i := range 3 {
+		for _, repo := range []*InMemoryUserRepository{shardA, shardB} {
+			user := &User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+			assert.NoError(t, repo.CreateUser(user))
+			assert.False(t, ids[user.ID], "ID %d assigned twice", user.ID)
+			ids[user.ID] = true
+		}
+	}
+	
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true, 1_000_000: true, 1_000_001: true, 1_000_002: true}, ids)
+	
+	// Offsets below 1 keep the default
+	user := &User{Username: "user", Email: "user@example.com"}
+	assert.NoError(t, NewUserRepositoryWithOptions(WithIDOffset(0)).CreateUser(user))
+	assert.Equal(t, 1, user.ID)
+}
+
+// TestImportUsers tests that users created after an import with explicit
+// IDs never collide with the imported ones
+func TestImportUsers(t *testing.T) {
+	repo := NewUserRepository(&User{Username: "alice", Email: "alice@example.com"})
+	
+	imported := []*User{
+		{ID: 500, Username: "bob", Email: "bob@example.com"},
+		{ID: 120, Username: "carol", Email: "carol@example.com"},
+	}
+	require.NoError(t, repo.ImportUsers(imported))
+	
+	dave := &User{Username: "dave", Email: "dave@example.com"}
+	require.NoError(t, repo.CreateUser(dave))
+	assert.Equal(t, 501, dave.ID)
+	
+	stored, err := repo.GetUser(500)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", stored.Username)
+	found, err := repo.FindByEmail("carol@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 120, found.ID)
+	
+	// IDs must be positive, and nothing is imported otherwise
+	err = repo.ImportUsers([]*User{{ID: 900, Username: "erin", Email: "erin@example.com"}, {Username: "frank", Email: "frank@example.com"}})
+	assert.ErrorIs(t, err, ErrInvalidUser)
+	_, err = repo.GetUser(900)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+// TestReindexNextID tests that the ID counter moves past users restored with
+// a stale counter, and never moves backwards
+func TestReindexNextID(t *testing.T) {
+	repo := NewUserRepository()
+	repo.Restore(RepoState{users: map[int]User{7: {ID: 7, Username: "alice", Email: "alice@example.com"}}, nextID: 1})
+	
+	bob := &User{Username: "bob", Email: "bob@example.com"}
+	require.NoError(t, repo.CreateUser(bob))
+	assert.Equal(t, 8, bob.ID)
+	
+	require.NoError(t, repo.DeleteUser(bob.ID))
+	repo.ReindexNextID()
+	carol := &User{Username: "carol", Email: "carol@example.com"}
+	require.NoError(t, repo.CreateUser(carol))
+	assert.Equal(t, 9, carol.ID, "the deleted user's ID must not be reused")
+}
+
+// TestUserValidate tests validating user fields
+func TestUserValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    User
+		isValid bool
+	}{
+		{"Valid", User{Username: "user1", Email: "user1@example.com"}, true},
+		{"Missing username", User{Email: "user1@example.com"}, false},
+		{"Blank username", User{Username: "  ", Email: "user1@example.com"}, false},
+		{"Missing email", User{Username: "user1"}, false},
+		{"Malformed email", User{Username: "user1", Email: "not-an-email"}, false},
+		{"Email with display name", User{Username: "user1", Email: "User <user1@example.com>"}, false},
+		{"Non-ASCII username", User{Username: "zoë", Email: "user1@example.com"}, true},
+		{"Invalid UTF-8 username", User{Username: "user\xff1", Email: "user1@example.com"}, false},
+		{"Truncated UTF-8 username", User{Username: "zo\xc3", Email: "user1@example.com"}, false},
+		{"Invalid UTF-8 email", User{Username: "user1", Email: "user\xfe1@example.com"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.user.Validate()
+			if tc.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidUser)
+			}
+		})
+	}
+}
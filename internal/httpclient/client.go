@@ -0,0 +1,136 @@
+// Package httpclient provides the HTTP client used for outbound calls and by
+// the integration tests, which retries GET requests that fail in ways a
+// retry can fix, such as a server that is still starting up.
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultRetries is how many times a failed GET is retried
+	DefaultRetries = 3
+	// DefaultBackoff is the wait before the first retry; it doubles before
+	// each retry after that
+	DefaultBackoff = 50 * time.Millisecond
+	// DefaultTimeout bounds a whole request, retries included
+	DefaultTimeout = 5 * time.Second
+)
+
+// Option configures a client created by New
+type Option func(*config)
+
+type config struct {
+	transport *retryTransport
+	timeout   time.Duration
+}
+
+// WithRetries sets how many times a failed GET is retried, with 0 disabling
+// retries
+func WithRetries(n int) Option {
+	return func(c *config) {
+		c.transport.retries = max(n, 0)
+	}
+}
+
+// WithBackoff sets the wait before the first retry
+func WithBackoff(d time.Duration) Option {
+	return func(c *config) {
+		c.transport.backoff = d
+	}
+}
+
+// WithTimeout bounds a whole request, retries included, with 0 meaning no limit
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+// WithTransport sends requests through rt instead of http.DefaultTransport
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *config) {
+		c.transport.base = rt
+	}
+}
+
+// New returns a client that retries GET requests failing with 502, 503 or
+// 504, or with a network error such as a refused connection or a timeout.
+// Other methods are sent once, since retrying them could apply them twice.
+func New(opts ...Option) *http.Client {
+	c := &config{
+		transport: &retryTransport{
+			base:    http.DefaultTransport,
+			retries: DefaultRetries,
+			backoff: DefaultBackoff,
+		},
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return &http.Client{Transport: c.transport, Timeout: c.timeout}
+}
+
+// retryTransport is the http.RoundTripper behind clients created by New
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+// RoundTrip sends req, retrying it with doubling backoff while it fails in
+// a retryable way. The last response or error is returned once retries
+// run out.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || (req.Body != nil && req.GetBody == nil) {
+		return t.base.RoundTrip(req)
+	}
+
+	delay := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt == t.retries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// shouldRetry reports whether a request that got resp or err may succeed if
+// sent again
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer returns a server answering the first failures requests with
+// status and later ones with 200, along with its request count
+func flakyServer(t *testing.T, failures int, status int) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(requests.Add(1)) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &requests
+}
+
+// TestRetries tests which responses are retried and when retries run out
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		failures         int
+		status           int
+		expectedStatus   int
+		expectedRequests int32
+	}{
+		{"Fails twice then succeeds", "GET", 2, http.StatusServiceUnavailable, http.StatusOK, 3},
+		{"Bad gateway", "GET", 1, http.StatusBadGateway, http.StatusOK, 2},
+		{"Gateway timeout", "GET", 1, http.StatusGatewayTimeout, http.StatusOK, 2},
+		{"Retries run out", "GET", 5, http.StatusServiceUnavailable, http.StatusServiceUnavailable, 4},
+		{"Server error is not retried", "GET", 1, http.StatusInternalServerError, http.StatusInternalServerError, 1},
+		{"POST is not retried", "POST", 1, http.StatusServiceUnavailable, http.StatusServiceUnavailable, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, requests := flakyServer(t, tc.failures, tc.status)
+			client := New(WithBackoff(time.Millisecond))
+
+			req, err := http.NewRequest(tc.method, ts.URL, strings.NewReader(""))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.expectedRequests, requests.Load())
+		})
+	}
+}
+
+// TestRetriesNetworkErrors tests that a server that is not listening yet is
+// retried until it starts
+func TestRetriesNetworkErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	// Start the server only after the first attempts were refused
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return
+		}
+		server := &http.Server{Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})}
+		t.Cleanup(func() { server.Close() })
+		server.Serve(ln)
+	}()
+
+	client := New(WithRetries(8), WithBackoff(10*time.Millisecond))
+	resp, err := client.Get("http://" + addr)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestRetriesDisabled tests that WithRetries(0) sends requests once
+func TestRetriesDisabled(t *testing.T) {
+	ts, requests := flakyServer(t, 1, http.StatusServiceUnavailable)
+
+	resp, err := New(WithRetries(0)).Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), requests.Load())
+}
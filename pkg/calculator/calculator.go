@@ -3,14 +3,74 @@ package calculator
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
 )
 
+// Errors returned by Calculator methods, for use with errors.Is
+var (
+	// ErrDivideByZero is returned when dividing by zero
+	ErrDivideByZero = errors.New("division by zero")
+	// ErrNegativeInput is returned when an operation only accepts non-negative input
+	ErrNegativeInput = errors.New("must not be negative")
+	// ErrOverflow is returned when a result does not fit in its return type
+	ErrOverflow = errors.New("result overflows uint64")
+	// ErrLengthMismatch is returned when vectors of different lengths are combined
+	ErrLengthMismatch = errors.New("vector length mismatch")
+	// ErrTooFewValues is returned when a statistic needs more values than given
+	ErrTooFewValues = errors.New("too few values")
+	// ErrUnknownRoundingMode is returned by RoundMode for unsupported modes
+	ErrUnknownRoundingMode = errors.New("unknown rounding mode")
+	// ErrPlacesOutOfRange is returned by RoundMode for more places than a float64 can hold
+	ErrPlacesOutOfRange = errors.New("places must be between -15 and 15")
+	// ErrInvalidWindow is returned by MovingAverage for a window that does not fit the values
+	ErrInvalidWindow = errors.New("invalid window")
+	// ErrNonPositiveLog is returned by Log for a zero or negative argument
+	ErrNonPositiveLog = errors.New("logarithm is only defined for positive numbers")
+	// ErrInvalidBase is returned by Log for a base that is not positive or is 1
+	ErrInvalidBase = errors.New("logarithm base must be positive and not 1")
+	// ErrInvalidMultiple is returned by RoundToMultiple for a multiple that is not positive
+	ErrInvalidMultiple = errors.New("multiple must be a positive number")
+	// ErrPercentileOutOfRange is returned by Percentile for a percentile outside [0, 100]
+	ErrPercentileOutOfRange = errors.New("percentile must be between 0 and 100")
+	// ErrUnknownOperation is returned by Properties for operations it does not describe
+	ErrUnknownOperation = errors.New("unknown operation")
+)
+
+// DefaultEpsilon is the tolerance used for float comparisons when none is given
+const DefaultEpsilon = 1e-9
+
 // Calculator performs mathematical operations
-type Calculator struct{}
+type Calculator struct {
+	// exprCache holds parsed expressions for Evaluate; nil disables caching
+	exprCache *ExpressionCache
+	// maxExprDepth bounds expression nesting in Evaluate; 0 means DefaultMaxExprDepth
+	maxExprDepth int
+}
+
+// Option configures optional Calculator behaviour
+type Option func(*Calculator)
+
+// WithMaxExprDepth sets how deeply expressions passed to Evaluate may nest
+// parentheses and signs. Values below 1 keep DefaultMaxExprDepth.
+func WithMaxExprDepth(depth int) Option {
+	return func(c *Calculator) {
+		if depth > 0 {
+			c.maxExprDepth = depth
+		}
+	}
+}
 
 // NewCalculator creates a new Calculator instance
-func NewCalculator() *Calculator {
-	return &Calculator{}
+func NewCalculator(opts ...Option) *Calculator {
+	c := &Calculator{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.exprCache = NewExpressionCache(DefaultExpressionCacheSize)
+	c.exprCache.maxDepth = c.maxExprDepth
+	return c
 }
 
 // Add adds two numbers and returns the result
@@ -29,10 +89,141 @@ func (c *Calculator) Multiply(a, b float64) float64 {
 }
 
 // Divide divides a by b and returns the result
-// Returns an error if b is zero
+// Returns ErrDivideByZero if b is zero
 func (c *Calculator) Divide(a, b float64) (float64, error) {
 	if b == 0 {
-		return 0, errors.New("division by zero")
+		return 0, ErrDivideByZero
 	}
 	return a / b, nil
-}
\ No newline at end of file
+}
+
+// DivideRat divides a by b exactly using rational arithmetic
+// Returns ErrDivideByZero if b is zero
+func (c *Calculator) DivideRat(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivideByZero
+	}
+	return new(big.Rat).Quo(a, b), nil
+}
+
+// MaxFibonacci is the largest n whose Fibonacci number fits in a uint64
+const MaxFibonacci = 93
+
+// Fibonacci returns the nth Fibonacci number, with F(0) = 0 and F(1) = 1
+// Returns ErrNegativeInput if n is negative or ErrOverflow if it is greater than MaxFibonacci
+func (c *Calculator) Fibonacci(n int) (uint64, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("n %w", ErrNegativeInput)
+	}
+	if n > MaxFibonacci {
+		return 0, fmt.Errorf("n is too large: %w", ErrOverflow)
+	}
+
+	var a, b uint64 = 0, 1
+	for i := 0; i < n; i++ {
+		a, b = b, a+b
+	}
+	return a, nil
+}
+
+// MinOf returns the smaller of a and b
+// If either is NaN the result is NaN, so a bad input is never silently dropped
+func (c *Calculator) MinOf(a, b float64) float64 {
+	return math.Min(a, b)
+}
+
+// MaxOf returns the larger of a and b
+// If either is NaN the result is NaN, so a bad input is never silently dropped
+func (c *Calculator) MaxOf(a, b float64) float64 {
+	return math.Max(a, b)
+}
+
+// Abs returns the absolute value of a
+// Abs(-0) is 0 and Abs(NaN) is NaN
+func (c *Calculator) Abs(a float64) float64 {
+	return math.Abs(a)
+}
+
+// Sign returns -1 if a is negative, 1 if it is positive and 0 otherwise
+// Negative zero is zero, and NaN has no sign so it also gives 0
+func (c *Calculator) Sign(a float64) int {
+	switch {
+	case a < 0:
+		return -1
+	case a > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Hypotenuse returns sqrt(a*a + b*b), the length of the hypotenuse of a
+// right triangle with legs a and b. Unlike the naive formula it does not
+// overflow or underflow in the intermediate squares, so Hypotenuse(3e200, 4e200)
+// is about 5e200 rather than +Inf. The result is only infinite if it exceeds MaxFloat64.
+func (c *Calculator) Hypotenuse(a, b float64) float64 {
+	return math.Hypot(a, b)
+}
+
+// Log returns the logarithm of x in the given base, such as math.E for the
+// natural logarithm. Results are exact to within floating point error, so
+// Log(1000, 10) may not be exactly 3.
+// Returns ErrNonPositiveLog if x <= 0, or ErrInvalidBase if base <= 0 or base == 1.
+func (c *Calculator) Log(x, base float64) (float64, error) {
+	if base <= 0 || base == 1 || math.IsNaN(base) {
+		return 0, fmt.Errorf("%w, got %g", ErrInvalidBase, base)
+	}
+	if x <= 0 || math.IsNaN(x) {
+		return 0, fmt.Errorf("%w, got %g", ErrNonPositiveLog, x)
+	}
+	return math.Log(x) / math.Log(base), nil
+}
+
+// DotProduct returns the sum of the pairwise products of a and b
+// The dot product of two empty vectors is 0
+// Returns ErrLengthMismatch if a and b differ in length
+func (c *Calculator) DotProduct(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("%w: %d and %d", ErrLengthMismatch, len(a), len(b))
+	}
+
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum, nil
+}
+
+// Evaluate parses and computes an arithmetic expression such as "2 * (3 + 4)"
+// Returns an error wrapping ErrInvalidExpression if it cannot be parsed, or
+// ErrDivideByZero if it divides by zero
+func (c *Calculator) Evaluate(expr string) (float64, error) {
+	return c.EvaluateWithVars(expr, nil)
+}
+
+// EvaluateWithVars is like Evaluate but expr may use the variables in vars,
+// such as "x * y + 2" with x and y given
+// Returns an error wrapping ErrUndefinedVariable, naming the variable, if
+// expr uses one that vars lacks
+func (c *Calculator) EvaluateWithVars(expr string, vars map[string]float64) (float64, error) {
+	var parsed Expr
+	var err error
+	if c.exprCache != nil {
+		parsed, err = c.exprCache.Parse(expr)
+	} else {
+		parsed, err = ParseExprWithMaxDepth(expr, c.maxExprDepth)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Eval(vars)
+}
+
+// AlmostEqual reports whether a and b differ by no more than epsilon
+// NaN is never almost equal to anything, including itself
+func (c *Calculator) AlmostEqual(a, b, epsilon float64) bool {
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= epsilon
+}
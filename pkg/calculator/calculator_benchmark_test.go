@@ -58,4 +58,31 @@ func BenchmarkDivideWithAllocs(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = calc.Divide(6.0, 3.0)
 	}
-}
\ No newline at end of file
+}
+
+// benchmarkExpr is long enough for parsing to dominate evaluation
+const benchmarkExpr = "((1 + 2) * (3 - 4) / 5 + 6 * 7 - (8 / 9 + 10)) * -(11 + 12 * 13)"
+
+// BenchmarkEvaluateCached benchmarks repeated evaluation of one expression,
+// which is parsed once and then served from the cache
+func BenchmarkEvaluateCached(b *testing.B) {
+	calc := NewCalculator()
+	b.ResetTimer()
+	b.ReportAllocs()
+	
+	for i := 0; i < b.N; i++ {
+		calc.Evaluate(benchmarkExpr)
+	}
+}
+
+// BenchmarkEvaluateUncached benchmarks repeated evaluation of one expression,
+// parsing it every time
+func BenchmarkEvaluateUncached(b *testing.B) {
+	calc := &Calculator{}
+	b.ResetTimer()
+	b.ReportAllocs()
+	
+	for i := 0; i < b.N; i++ {
+		calc.Evaluate(benchmarkExpr)
+	}
+}
@@ -1,6 +1,8 @@
 package calculator
 
 import (
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,13 +24,14 @@ func TestAdd(t *testing.T) {
 		{"Mixed numbers", -2, 3, 1},
 		{"Zeros", 0, 0, 0},
 		{"Decimals", 1.5, 2.5, 4},
+		{"Inexact decimals", 0.1, 0.2, 0.3},
 	}
 
-	// Run test cases
+	// Run test cases; float results are compared within a tolerance
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			result := calc.Add(tc.a, tc.b)
-			assert.Equal(t, tc.expected, result, "Add(%f, %f) should equal %f", tc.a, tc.b, tc.expected)
+			assert.True(t, calc.AlmostEqual(tc.expected, result, DefaultEpsilon), "Add(%f, %f) should equal %f, got %f", tc.a, tc.b, tc.expected, result)
 		})
 	}
 }
@@ -89,21 +92,21 @@ func TestDivide(t *testing.T) {
 		name        string
 		a, b        float64
 		expected    float64
-		expectError bool
+		expectedErr error
 	}{
-		{"Positive numbers", 6, 3, 2, false},
-		{"Negative numbers", -6, -3, 2, false},
-		{"Mixed numbers", -6, 3, -2, false},
-		{"Division by zero", 5, 0, 0, true},
-		{"Decimals", 5, 2, 2.5, false},
+		{"Positive numbers", 6, 3, 2, nil},
+		{"Negative numbers", -6, -3, 2, nil},
+		{"Mixed numbers", -6, 3, -2, nil},
+		{"Division by zero", 5, 0, 0, ErrDivideByZero},
+		{"Decimals", 5, 2, 2.5, nil},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			result, err := calc.Divide(tc.a, tc.b)
 			
-			if tc.expectError {
-				assert.Error(t, err, "Expected error but got none")
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
 			} else {
 				assert.NoError(t, err, "Unexpected error")
 				assert.Equal(t, tc.expected, result)
@@ -112,6 +115,244 @@ func TestDivide(t *testing.T) {
 	}
 }
 
+// TestDivideRat tests exact rational division
+func TestDivideRat(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		a, b        string
+		expected    string
+		expectedErr error
+	}{
+		{"Repeating decimal", "1", "3", "1/3", nil},
+		{"Simplifies to integer", "4", "2", "2", nil},
+		{"Simplifies fraction", "6", "4", "3/2", nil},
+		{"Decimal operands", "0.5", "0.25", "2", nil},
+		{"Fraction operands", "1/2", "3/4", "2/3", nil},
+		{"Negative", "-1", "3", "-1/3", nil},
+		{"Division by zero", "1", "0", "", ErrDivideByZero},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, _ := new(big.Rat).SetString(tc.a)
+			b, _ := new(big.Rat).SetString(tc.b)
+
+			result, err := calc.DivideRat(a, b)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result.RatString())
+			}
+		})
+	}
+}
+
+// TestFibonacci tests the Fibonacci method including its bounds
+func TestFibonacci(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		n           int
+		expected    uint64
+		expectedErr error
+	}{
+		{"F(0)", 0, 0, nil},
+		{"F(1)", 1, 1, nil},
+		{"F(2)", 2, 1, nil},
+		{"F(10)", 10, 55, nil},
+		{"Largest representable", MaxFibonacci, 12200160415121876738, nil},
+		{"Overflow", MaxFibonacci + 1, 0, ErrOverflow},
+		{"Negative", -1, 0, ErrNegativeInput},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Fibonacci(tc.n)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
+
+// TestMinMax tests the MinOf and MaxOf methods including equal values and NaN
+func TestMinMax(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		a, b        float64
+		expectedMin float64
+		expectedMax float64
+	}{
+		{"Ordered", 1, 2, 1, 2},
+		{"Reversed", 2, 1, 1, 2},
+		{"Negative", -3, 2, -3, 2},
+		{"Equal values", 5, 5, 5, 5},
+		{"Infinity", math.Inf(-1), math.Inf(1), math.Inf(-1), math.Inf(1)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedMin, calc.MinOf(tc.a, tc.b))
+			assert.Equal(t, tc.expectedMax, calc.MaxOf(tc.a, tc.b))
+		})
+	}
+
+	t.Run("NaN operand", func(t *testing.T) {
+		for _, pair := range [][2]float64{{math.NaN(), 1}, {1, math.NaN()}} {
+			assert.True(t, math.IsNaN(calc.MinOf(pair[0], pair[1])))
+			assert.True(t, math.IsNaN(calc.MaxOf(pair[0], pair[1])))
+		}
+	})
+}
+
+// TestHypotenuse tests the Hypotenuse method, including operands whose
+// squares overflow or underflow float64
+func TestHypotenuse(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name     string
+		a, b     float64
+		expected float64
+	}{
+		{"3-4-5 triangle", 3, 4, 5},
+		{"Negative legs", -5, -12, 13},
+		{"Zero leg", 0, 7, 7},
+		{"Large operands", 3e200, 4e200, 5e200},
+		{"Near MaxFloat64", math.MaxFloat64 / 2, math.MaxFloat64 / 2, math.MaxFloat64 / math.Sqrt2},
+		{"Tiny operands", 3e-200, 4e-200, 5e-200},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InEpsilon(t, tc.expected, calc.Hypotenuse(tc.a, tc.b), 1e-15)
+		})
+	}
+
+	t.Run("Naive formula overflows", func(t *testing.T) {
+		a, b := 3e200, 4e200
+		assert.True(t, math.IsInf(math.Sqrt(a*a+b*b), 1))
+		assert.False(t, math.IsInf(calc.Hypotenuse(a, b), 0))
+	})
+
+	t.Run("Result beyond MaxFloat64", func(t *testing.T) {
+		assert.True(t, math.IsInf(calc.Hypotenuse(math.MaxFloat64, math.MaxFloat64), 1))
+	})
+}
+
+// TestLog tests the Log method in common bases and its domain errors
+func TestLog(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		x, base     float64
+		expected    float64
+		expectedErr error
+	}{
+		{"Natural log", math.E * math.E, math.E, 2, nil},
+		{"Base 2", 1024, 2, 10, nil},
+		{"Base 10", 1000, 10, 3, nil},
+		{"Fractional result", 0.5, 2, -1, nil},
+		{"Fractional base", 8, 0.5, -3, nil},
+		{"Log of one", 1, 7, 0, nil},
+		{"Zero", 0, 10, 0, ErrNonPositiveLog},
+		{"Negative", -1, 10, 0, ErrNonPositiveLog},
+		{"Base one", 10, 1, 0, ErrInvalidBase},
+		{"Base zero", 10, 0, 0, ErrInvalidBase},
+		{"Negative base", 10, -2, 0, ErrInvalidBase},
+		{"NaN", math.NaN(), 10, 0, ErrNonPositiveLog},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Log(tc.x, tc.base)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.InDelta(t, tc.expected, result, 1e-12)
+			}
+		})
+	}
+}
+
+// TestAbsSign tests the Abs and Sign methods including negative zero and NaN
+func TestAbsSign(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name         string
+		a            float64
+		expectedAbs  float64
+		expectedSign int
+	}{
+		{"Positive", 2.5, 2.5, 1},
+		{"Negative", -2.5, 2.5, -1},
+		{"Zero", 0, 0, 0},
+		{"Negative zero", math.Copysign(0, -1), 0, 0},
+		{"Negative infinity", math.Inf(-1), math.Inf(1), -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			abs := calc.Abs(tc.a)
+			assert.Equal(t, tc.expectedAbs, abs)
+			assert.False(t, math.Signbit(abs), "Abs must not return negative zero")
+			assert.Equal(t, tc.expectedSign, calc.Sign(tc.a))
+		})
+	}
+
+	t.Run("NaN", func(t *testing.T) {
+		assert.True(t, math.IsNaN(calc.Abs(math.NaN())))
+		assert.Equal(t, 0, calc.Sign(math.NaN()))
+	})
+}
+
+// TestDotProduct tests the DotProduct method including mismatched lengths
+func TestDotProduct(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		a, b        []float64
+		expected    float64
+		expectedErr error
+	}{
+		{"Equal length", []float64{1, 2, 3}, []float64{4, 5, 6}, 32, nil},
+		{"Negative components", []float64{1, -2}, []float64{-3, 4}, -11, nil},
+		{"Empty vectors", []float64{}, []float64{}, 0, nil},
+		{"Nil vectors", nil, nil, 0, nil},
+		{"Length mismatch", []float64{1, 2}, []float64{1}, 0, ErrLengthMismatch},
+		{"One empty", []float64{}, []float64{1}, 0, ErrLengthMismatch},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.DotProduct(tc.a, tc.b)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
+
 // Helper function example with t.Helper()
 func assertOperationResult(t *testing.T, expected, actual float64, operation string, a, b float64) {
 	t.Helper() // Marks this as a helper function for better error reporting
@@ -132,4 +373,32 @@ func TestCalculatorWithHelper(t *testing.T) {
 	result, err := calc.Divide(6, 3)
 	assert.NoError(t, err)
 	assertOperationResult(t, 2, result, "Divide", 6, 3)
-}
\ No newline at end of file
+}
+
+// TestAlmostEqual tests AlmostEqual around the epsilon boundary
+func TestAlmostEqual(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name     string
+		a, b     float64
+		epsilon  float64
+		expected bool
+	}{
+		{"Identical values", 1, 1, 0, true},
+		{"Difference below epsilon", 1, 1.0005, 0.001, true},
+		{"Difference equal to epsilon", 1, 1.5, 0.5, true},
+		{"Difference above epsilon", 1, 1.5000001, 0.5, false},
+		{"Order does not matter", 1.5000001, 1, 0.5, false},
+		{"Classic rounding error", 0.30000000000000004, 0.3, DefaultEpsilon, true},
+		{"Zero epsilon requires exact match", 0.30000000000000004, 0.3, 0, false},
+		{"Equal infinities", math.Inf(1), math.Inf(1), 0, true},
+		{"NaN", math.NaN(), math.NaN(), 1, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, calc.AlmostEqual(tc.a, tc.b, tc.epsilon))
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package calculator
+
+// AddComplex adds two complex numbers and returns the result
+func (c *Calculator) AddComplex(a, b complex128) complex128 {
+	return a + b
+}
+
+// SubtractComplex subtracts b from a and returns the result
+func (c *Calculator) SubtractComplex(a, b complex128) complex128 {
+	return a - b
+}
+
+// MultiplyComplex multiplies two complex numbers and returns the result
+func (c *Calculator) MultiplyComplex(a, b complex128) complex128 {
+	return a * b
+}
+
+// DivideComplex divides a by b and returns the result
+// Returns ErrDivideByZero if b is zero
+func (c *Calculator) DivideComplex(a, b complex128) (complex128, error) {
+	if b == 0 {
+		return 0, ErrDivideByZero
+	}
+	return a / b, nil
+}
@@ -0,0 +1,64 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestComplexArithmetic tests addition, subtraction and multiplication of
+// complex numbers
+func TestComplexArithmetic(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name     string
+		op       func(a, b complex128) complex128
+		a, b     complex128
+		expected complex128
+	}{
+		{"Add", calc.AddComplex, 1 + 2i, 3 - 1i, 4 + 1i},
+		{"Subtract", calc.SubtractComplex, 1 + 2i, 3 - 1i, -2 + 3i},
+		{"Multiply", calc.MultiplyComplex, 1 + 2i, 3 - 1i, 5 + 5i},
+		{"Multiply i by i", calc.MultiplyComplex, 1i, 1i, -1},
+		{"Multiply by zero", calc.MultiplyComplex, 1 + 2i, 0, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.op(tc.a, tc.b))
+		})
+	}
+}
+
+// TestDivideComplex tests complex division, including by zero
+func TestDivideComplex(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		a, b        complex128
+		expected    complex128
+		expectedErr error
+	}{
+		{"Divide", 5 + 5i, 3 - 1i, 1 + 2i, nil},
+		{"Divide by i", 1, 1i, -1i, nil},
+		{"Divide by zero", 1 + 2i, 0, 0, ErrDivideByZero},
+		{"Divide zero by zero", 0, 0, 0, ErrDivideByZero},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.DivideComplex(tc.a, tc.b)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, real(tc.expected), real(result), 1e-12)
+			assert.InDelta(t, imag(tc.expected), imag(result), 1e-12)
+		})
+	}
+}
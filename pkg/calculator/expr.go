@@ -0,0 +1,369 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// Errors returned when parsing expressions, for use with errors.Is
+var (
+	// ErrInvalidExpression is returned when an expression cannot be parsed
+	ErrInvalidExpression = errors.New("invalid expression")
+	// ErrExpressionTooDeep is returned when an expression nests parentheses or
+	// signs more deeply than allowed. It wraps ErrInvalidExpression.
+	ErrExpressionTooDeep = fmt.Errorf("%w: nested too deeply", ErrInvalidExpression)
+	// ErrExpressionTooLong is returned when an expression is longer than
+	// MaxExprLength. It wraps ErrInvalidExpression.
+	ErrExpressionTooLong = fmt.Errorf("%w: too long", ErrInvalidExpression)
+	// ErrUndefinedVariable is returned when evaluating an expression that
+	// uses a variable without a value
+	ErrUndefinedVariable = errors.New("undefined variable")
+)
+
+// DefaultMaxExprDepth is how deeply expressions may nest unless configured
+// otherwise. It keeps recursive parsing of hostile input from exhausting the stack.
+const DefaultMaxExprDepth = 64
+
+// MaxExprLength is the longest expression, in bytes, that will be parsed.
+// It bounds the size of the tree parsing builds.
+const MaxExprLength = 10000
+
+// Expr is a parsed arithmetic expression. It is immutable, so one Expr may be
+// evaluated any number of times, concurrently.
+type Expr interface {
+	// Eval computes the value of the expression, looking up the values of
+	// variables in vars, which may be nil if the expression has none
+	Eval(vars map[string]float64) (float64, error)
+}
+
+// numberExpr is a literal number
+type numberExpr float64
+
+func (e numberExpr) Eval(map[string]float64) (float64, error) {
+	return float64(e), nil
+}
+
+// variableExpr is a named value supplied at evaluation time
+type variableExpr string
+
+func (e variableExpr) Eval(vars map[string]float64) (float64, error) {
+	v, ok := vars[string(e)]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUndefinedVariable, string(e))
+	}
+	return v, nil
+}
+
+// negateExpr is a unary minus applied to an operand
+type negateExpr struct {
+	operand Expr
+}
+
+func (e negateExpr) Eval(vars map[string]float64) (float64, error) {
+	v, err := e.operand.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	return -v, nil
+}
+
+// binaryExpr is one of + - * / applied to two operands
+type binaryExpr struct {
+	op          byte
+	left, right Expr
+}
+
+// Eval walks chains such as 1+2+3, which nest to the left with one node per
+// operator, in a loop rather than recursively, so long chains cannot
+// exhaust the stack
+func (e binaryExpr) Eval(vars map[string]float64) (float64, error) {
+	spine := []binaryExpr{e}
+	left := e.left
+	for {
+		inner, ok := left.(binaryExpr)
+		if !ok {
+			break
+		}
+		spine = append(spine, inner)
+		left = inner.left
+	}
+
+	result, err := left.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	for i := len(spine) - 1; i >= 0; i-- {
+		b, err := spine[i].right.Eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		if result, err = applyOperator(spine[i].op, result, b); err != nil {
+			return 0, err
+		}
+	}
+	return result, nil
+}
+
+// applyOperator computes a op b for one of + - * /
+func applyOperator(op byte, a, b float64) (float64, error) {
+	switch op {
+	case '+':
+		return a + b, nil
+	case '-':
+		return a - b, nil
+	case '*':
+		return a * b, nil
+	default:
+		if b == 0 {
+			return 0, ErrDivideByZero
+		}
+		return a / b, nil
+	}
+}
+
+// ParseExpr parses an arithmetic expression made of numbers, variables, the
+// operators + - * /, unary minus and parentheses, with the usual precedence.
+// Variable names start with a letter or underscore, followed by letters,
+// digits or underscores. Nesting is limited to DefaultMaxExprDepth and
+// length to MaxExprLength.
+func ParseExpr(input string) (Expr, error) {
+	return ParseExprWithMaxDepth(input, DefaultMaxExprDepth)
+}
+
+// ParseExprWithMaxDepth is like ParseExpr but allows parentheses and signs to
+// nest maxDepth deep, or DefaultMaxExprDepth if maxDepth is not positive.
+// Deeper expressions fail with ErrExpressionTooDeep.
+func ParseExprWithMaxDepth(input string, maxDepth int) (Expr, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxExprDepth
+	}
+	if len(input) > MaxExprLength {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d allowed", ErrExpressionTooLong, len(input), MaxExprLength)
+	}
+	p := &parser{input: input, maxDepth: maxDepth}
+	p.next()
+
+	expr, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != tokEOF {
+		return nil, p.unexpected()
+	}
+	return expr, nil
+}
+
+// tokenKind classifies the tokens of an expression
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOperator
+	tokLParen
+	tokRParen
+	tokInvalid
+)
+
+// parser is a recursive descent parser over a single expression string
+type parser struct {
+	input string
+	pos   int // offset of the next unread byte
+
+	depth    int // nesting of the parenthesis or sign being parsed
+	maxDepth int
+
+	tok    tokenKind
+	text   string
+	tokPos int
+}
+
+// next advances to the next token
+func (p *parser) next() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+
+	p.tokPos = p.pos
+	if p.pos == len(p.input) {
+		p.tok, p.text = tokEOF, ""
+		return
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '+' || c == '-' || c == '*' || c == '/':
+		p.tok = tokOperator
+		p.pos++
+	case c == '(':
+		p.tok = tokLParen
+		p.pos++
+	case c == ')':
+		p.tok = tokRParen
+		p.pos++
+	case c == '.' || isDigit(c):
+		p.tok = tokNumber
+		p.pos = scanNumber(p.input, p.pos)
+	case isIdentStart(c):
+		p.tok = tokIdent
+		for p.pos < len(p.input) && (isIdentStart(p.input[p.pos]) || isDigit(p.input[p.pos])) {
+			p.pos++
+		}
+	default:
+		p.tok = tokInvalid
+		p.pos++
+	}
+	p.text = p.input[p.tokPos:p.pos]
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentStart reports whether c may begin a variable name
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// scanNumber returns the offset just past the number starting at start,
+// including an optional exponent such as 1.5e-3
+func scanNumber(s string, start int) int {
+	i := start
+	for i < len(s) && (s[i] == '.' || (s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	return i
+}
+
+// parseSum parses terms joined by + and -
+func (p *parser) parseSum() (Expr, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok == tokOperator && (p.text == "+" || p.text == "-") {
+		op := p.text[0]
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseProduct parses unary operands joined by * and /
+func (p *parser) parseProduct() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok == tokOperator && (p.text == "*" || p.text == "/") {
+		op := p.text[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses an operand with any leading signs
+func (p *parser) parseUnary() (Expr, error) {
+	if p.tok == tokOperator && (p.text == "-" || p.text == "+") {
+		negate := p.text == "-"
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if negate {
+			return negateExpr{operand: operand}, nil
+		}
+		return operand, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a number, a variable or a parenthesized expression
+func (p *parser) parsePrimary() (Expr, error) {
+	switch p.tok {
+	case tokNumber:
+		v, err := strconv.ParseFloat(p.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed number %q at position %d", ErrInvalidExpression, p.text, p.tokPos)
+		}
+		p.next()
+		return numberExpr(v), nil
+	case tokIdent:
+		name := p.text
+		p.next()
+		return variableExpr(name), nil
+	case tokLParen:
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		p.next()
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != tokRParen {
+			return nil, p.unexpected()
+		}
+		p.next()
+		return inner, nil
+	default:
+		return nil, p.unexpected()
+	}
+}
+
+// enter descends one nesting level, failing if that exceeds maxDepth
+func (p *parser) enter() error {
+	if p.depth == p.maxDepth {
+		return fmt.Errorf("%w: more than %d levels at position %d", ErrExpressionTooDeep, p.maxDepth, p.tokPos)
+	}
+	p.depth++
+	return nil
+}
+
+// leave returns from a nesting level entered with enter
+func (p *parser) leave() {
+	p.depth--
+}
+
+// unexpected describes the current token as a parse error
+func (p *parser) unexpected() error {
+	if p.tok == tokEOF {
+		return fmt.Errorf("%w: unexpected end of expression", ErrInvalidExpression)
+	}
+	return fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidExpression, p.text, p.tokPos)
+}
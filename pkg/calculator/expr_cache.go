@@ -0,0 +1,91 @@
+package calculator
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultExpressionCacheSize is the number of parsed expressions a Calculator
+// created by NewCalculator keeps
+const DefaultExpressionCacheSize = 256
+
+// ExpressionCache is a fixed-size LRU cache of parsed expressions keyed by
+// their source text. It is safe for concurrent use.
+type ExpressionCache struct {
+	size     int
+	maxDepth int // passed to ParseExprWithMaxDepth
+	mutex    sync.Mutex
+	order    *list.List // front is most recently used
+	items    map[string]*list.Element
+}
+
+// cacheEntry is the value stored in each element of ExpressionCache.order
+type cacheEntry struct {
+	input string
+	expr  Expr
+}
+
+// NewExpressionCache creates a cache holding at most size expressions
+func NewExpressionCache(size int) *ExpressionCache {
+	return &ExpressionCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// Parse returns the parsed form of input, parsing it only on a cache miss.
+// Inputs that fail to parse are not cached.
+func (c *ExpressionCache) Parse(input string) (Expr, error) {
+	if expr, ok := c.get(input); ok {
+		return expr, nil
+	}
+
+	expr, err := ParseExprWithMaxDepth(input, c.maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	c.put(input, expr)
+	return expr, nil
+}
+
+// Len returns the number of cached expressions
+func (c *ExpressionCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *ExpressionCache) get(input string) (Expr, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.items[input]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).expr, true
+}
+
+func (c *ExpressionCache) put(input string, expr Expr) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+	// Another goroutine may have parsed the same input meanwhile
+	if elem, ok := c.items[input]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[input] = c.order.PushFront(&cacheEntry{input: input, expr: expr})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).input)
+	}
+}
@@ -0,0 +1,235 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvaluate tests parsing and evaluating expressions
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		expected    float64
+		expectedErr error
+	}{
+		{"Number", "42", 42, nil},
+		{"Decimal", "1.5", 1.5, nil},
+		{"Exponent", "1.5e3", 1500, nil},
+		{"Addition", "1 + 2", 3, nil},
+		{"Left associative", "10 - 4 - 3", 3, nil},
+		{"Precedence", "2 + 3 * 4", 14, nil},
+		{"Parentheses", "(2 + 3) * 4", 20, nil},
+		{"Division", "7 / 2", 3.5, nil},
+		{"Unary minus", "-3 * -(2 + 1)", 9, nil},
+		{"Unary plus", "+4", 4, nil},
+		{"No spaces", "2*(3+4)", 14, nil},
+		{"Division by zero", "1 / (2 - 2)", 0, ErrDivideByZero},
+		{"Empty", "", 0, ErrInvalidExpression},
+		{"Trailing operator", "1 +", 0, ErrInvalidExpression},
+		{"Unbalanced parenthesis", "(1 + 2", 0, ErrInvalidExpression},
+		{"Extra parenthesis", "1 + 2)", 0, ErrInvalidExpression},
+		{"Unknown character", "2 ^ 3", 0, ErrInvalidExpression},
+		{"Malformed number", "1.2.3", 0, ErrInvalidExpression},
+		{"Adjacent numbers", "1 2", 0, ErrInvalidExpression},
+	}
+
+	calc := NewCalculator()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Evaluate(tc.expr)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
+
+// TestEvaluateWithVars tests expressions that use variables
+func TestEvaluateWithVars(t *testing.T) {
+	vars := map[string]float64{"x": 3, "y": 4, "rate_2": 0.5}
+
+	tests := []struct {
+		name          string
+		expr          string
+		expected      float64
+		expectedErr   error
+		expectedInErr string
+	}{
+		{"Defined variables", "x * y + 2", 14, nil, ""},
+		{"Underscore and digit", "rate_2 * 10", 5, nil, ""},
+		{"Negated variable", "-x + y", 1, nil, ""},
+		{"Exponent is not a variable", "2e1 + x", 23, nil, ""},
+		{"Missing variable", "x * z", 0, ErrUndefinedVariable, `undefined variable "z"`},
+		{"Case sensitive", "X + 1", 0, ErrUndefinedVariable, `undefined variable "X"`},
+		{"Number before name", "2x", 0, ErrInvalidExpression, ""},
+	}
+
+	calc := NewCalculator()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.EvaluateWithVars(tc.expr, vars)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.ErrorContains(t, err, tc.expectedInErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+
+	t.Run("Parsed once, evaluated with different values", func(t *testing.T) {
+		first, err := calc.EvaluateWithVars("a - b", map[string]float64{"a": 5, "b": 1})
+		require.NoError(t, err)
+		second, err := calc.EvaluateWithVars("a - b", map[string]float64{"a": 1, "b": 5})
+		require.NoError(t, err)
+		assert.Equal(t, 4.0, first)
+		assert.Equal(t, -4.0, second)
+	})
+
+	t.Run("Evaluate has no variables", func(t *testing.T) {
+		_, err := calc.Evaluate("x")
+		assert.ErrorIs(t, err, ErrUndefinedVariable)
+	})
+}
+
+// nested wraps "1" in depth levels of parentheses
+func nested(depth int) string {
+	return strings.Repeat("(", depth) + "1" + strings.Repeat(")", depth)
+}
+
+// TestExpressionMaxDepth tests that nesting beyond the limit fails gracefully
+func TestExpressionMaxDepth(t *testing.T) {
+	tests := []struct {
+		name        string
+		calc        *Calculator
+		expr        string
+		expectedErr error
+	}{
+		{"Parentheses at default limit", NewCalculator(), nested(DefaultMaxExprDepth), nil},
+		{"Parentheses beyond default limit", NewCalculator(), nested(DefaultMaxExprDepth + 1), ErrExpressionTooDeep},
+		{"Signs at default limit", NewCalculator(), strings.Repeat("-", DefaultMaxExprDepth) + "1", nil},
+		{"Signs beyond default limit", NewCalculator(), strings.Repeat("-", DefaultMaxExprDepth+1) + "1", ErrExpressionTooDeep},
+		{"Mixed beyond default limit", NewCalculator(), strings.Repeat("-(", DefaultMaxExprDepth/2+1) + "1" + strings.Repeat(")", DefaultMaxExprDepth/2+1), ErrExpressionTooDeep},
+		{"Far beyond default limit", NewCalculator(), nested(MaxExprLength/2 - 1), ErrExpressionTooDeep},
+		{"Uncached beyond default limit", &Calculator{}, nested(DefaultMaxExprDepth + 1), ErrExpressionTooDeep},
+		{"At configured limit", NewCalculator(WithMaxExprDepth(3)), nested(3), nil},
+		{"Beyond configured limit", NewCalculator(WithMaxExprDepth(3)), nested(4), ErrExpressionTooDeep},
+		{"Invalid limit keeps default", NewCalculator(WithMaxExprDepth(-1)), nested(DefaultMaxExprDepth), nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.calc.Evaluate(tc.expr)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.ErrorIs(t, err, ErrInvalidExpression)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 1.0, result)
+			}
+		})
+	}
+}
+
+// TestExpressionLength tests that long operator chains evaluate up to
+// MaxExprLength and are rejected beyond it
+func TestExpressionLength(t *testing.T) {
+	calc := NewCalculator()
+
+	// "1" followed by "+1" repeated, MaxExprLength-1 bytes long
+	atLimit := "1" + strings.Repeat("+1", MaxExprLength/2-1)
+	result, err := calc.Evaluate(atLimit)
+	require.NoError(t, err)
+	assert.Equal(t, float64(MaxExprLength/2), result)
+
+	_, err = calc.Evaluate(atLimit + "+1")
+	assert.ErrorIs(t, err, ErrExpressionTooLong)
+	assert.ErrorIs(t, err, ErrInvalidExpression)
+
+	// Chains longer than any parseable expression still evaluate without
+	// recursing once per operator
+	var chain Expr = numberExpr(0)
+	for range 1_000_000 {
+		chain = binaryExpr{op: '+', left: chain, right: numberExpr(1)}
+	}
+	result, err = chain.Eval(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1_000_000.0, result)
+}
+
+// TestEvaluateCachedMatchesUncached tests that caching parsed expressions does not change results
+func TestEvaluateCachedMatchesUncached(t *testing.T) {
+	cached := NewCalculator()
+	uncached := &Calculator{}
+
+	exprs := []string{"1 + 2 * 3", "(4 - 1) / 2", "-(2.5 * 4)", "1 / 0", "1 +", "8 / 4 / 2"}
+	for _, expr := range exprs {
+		// Evaluate twice so the second cached evaluation is a cache hit
+		for i := 0; i < 2; i++ {
+			want, wantErr := uncached.Evaluate(expr)
+			got, gotErr := cached.Evaluate(expr)
+
+			assert.Equal(t, want, got, expr)
+			assert.Equal(t, wantErr, gotErr, expr)
+		}
+	}
+}
+
+// TestExpressionCacheEviction tests that the least recently used expression is evicted
+func TestExpressionCacheEviction(t *testing.T) {
+	cache := NewExpressionCache(2)
+
+	first, err := cache.Parse("1 + 1")
+	require.NoError(t, err)
+	_, err = cache.Parse("2 + 2")
+	require.NoError(t, err)
+
+	// Touch "1 + 1" so "2 + 2" becomes the least recently used
+	again, err := cache.Parse("1 + 1")
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	_, err = cache.Parse("3 + 3")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cache.Len())
+	assert.Contains(t, cache.items, "1 + 1")
+	assert.Contains(t, cache.items, "3 + 3")
+	assert.NotContains(t, cache.items, "2 + 2")
+
+	// Parse errors are not cached
+	_, err = cache.Parse("(")
+	assert.ErrorIs(t, err, ErrInvalidExpression)
+	assert.Equal(t, 2, cache.Len())
+}
+
+// TestExpressionCacheConcurrent tests concurrent use of the cache
+func TestExpressionCacheConcurrent(t *testing.T) {
+	calc := NewCalculator()
+
+	done := make(chan struct{})
+	for g := 0; g < 8; g++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for i := 0; i < 200; i++ {
+				result, err := calc.Evaluate(fmt.Sprintf("%d * 2", i%20))
+				assert.NoError(t, err)
+				assert.Equal(t, float64(i%20*2), result)
+			}
+		}()
+	}
+	for g := 0; g < 8; g++ {
+		<-done
+	}
+}
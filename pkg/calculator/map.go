@@ -0,0 +1,33 @@
+package calculator
+
+import "fmt"
+
+// MapOp applies the binary operation op to every element of values with
+// operand as the second operand, such as multiplying each element by 2, and
+// returns the results in a new slice. op is add, subtract, multiply or divide.
+// Returns ErrUnknownOperation for any other op, or ErrDivideByZero when
+// dividing by a zero operand, before any element is processed
+func (c *Calculator) MapOp(values []float64, op string, operand float64) ([]float64, error) {
+	var apply func(float64) float64
+	switch op {
+	case "add":
+		apply = func(v float64) float64 { return c.Add(v, operand) }
+	case "subtract":
+		apply = func(v float64) float64 { return c.Subtract(v, operand) }
+	case "multiply":
+		apply = func(v float64) float64 { return c.Multiply(v, operand) }
+	case "divide":
+		if operand == 0 {
+			return nil, ErrDivideByZero
+		}
+		apply = func(v float64) float64 { return v / operand }
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownOperation, op)
+	}
+
+	result := make([]float64, len(values))
+	for i, v := range values {
+		result[i] = apply(v)
+	}
+	return result, nil
+}
@@ -0,0 +1,47 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMapOp tests applying each operation to every element
+func TestMapOp(t *testing.T) {
+	calc := NewCalculator()
+	values := []float64{1, 2, 4}
+
+	tests := []struct {
+		name        string
+		values      []float64
+		op          string
+		operand     float64
+		expected    []float64
+		expectedErr error
+	}{
+		{"Add", values, "add", 3, []float64{4, 5, 7}, nil},
+		{"Subtract", values, "subtract", 1, []float64{0, 1, 3}, nil},
+		{"Multiply", values, "multiply", 2, []float64{2, 4, 8}, nil},
+		{"Divide", values, "divide", 4, []float64{0.25, 0.5, 1}, nil},
+		{"Empty", []float64{}, "multiply", 2, []float64{}, nil},
+		{"Divide by zero", values, "divide", 0, nil, ErrDivideByZero},
+		{"Divide empty by zero", nil, "divide", 0, nil, ErrDivideByZero},
+		{"Unknown operation", values, "power", 2, nil, ErrUnknownOperation},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.MapOp(tc.values, tc.op, tc.operand)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+
+	assert.Equal(t, []float64{1, 2, 4}, values, "the input is not modified")
+}
@@ -0,0 +1,60 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidMatrix is returned for matrices that are empty or ragged,
+	// meaning their rows differ in length
+	ErrInvalidMatrix = errors.New("invalid matrix")
+	// ErrDimensionMismatch is returned when multiplying matrices whose inner
+	// dimensions differ
+	ErrDimensionMismatch = errors.New("matrix dimension mismatch")
+)
+
+// MatrixMultiply returns the product of a, an m×n matrix, and b, an n×p
+// matrix, as an m×p matrix. Matrices are slices of rows.
+// Returns ErrInvalidMatrix if either matrix is empty or ragged, or
+// ErrDimensionMismatch if a has a different number of columns than b has rows.
+func (c *Calculator) MatrixMultiply(a, b [][]float64) ([][]float64, error) {
+	if err := checkMatrix("a", a); err != nil {
+		return nil, err
+	}
+	if err := checkMatrix("b", b); err != nil {
+		return nil, err
+	}
+	if len(a[0]) != len(b) {
+		return nil, fmt.Errorf("%w: a is %dx%d and b is %dx%d",
+			ErrDimensionMismatch, len(a), len(a[0]), len(b), len(b[0]))
+	}
+
+	product := make([][]float64, len(a))
+	for i, row := range a {
+		product[i] = make([]float64, len(b[0]))
+		for j := range b[0] {
+			var sum float64
+			for k, v := range row {
+				sum += v * b[k][j]
+			}
+			product[i][j] = sum
+		}
+	}
+	return product, nil
+}
+
+// checkMatrix returns an error wrapping ErrInvalidMatrix, naming the matrix,
+// unless m has at least one row and all its rows have the same, non-zero length
+func checkMatrix(name string, m [][]float64) error {
+	if len(m) == 0 || len(m[0]) == 0 {
+		return fmt.Errorf("%w: %s is empty", ErrInvalidMatrix, name)
+	}
+	for i, row := range m {
+		if len(row) != len(m[0]) {
+			return fmt.Errorf("%w: %s is ragged: row %d has %d columns, row 0 has %d",
+				ErrInvalidMatrix, name, i, len(row), len(m[0]))
+		}
+	}
+	return nil
+}
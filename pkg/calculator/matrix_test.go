@@ -0,0 +1,82 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMatrixMultiply tests the MatrixMultiply method including malformed matrices
+func TestMatrixMultiply(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		a, b        [][]float64
+		expected    [][]float64
+		expectedErr error
+		errMessage  string
+	}{
+		{
+			name:     "2x2 product",
+			a:        [][]float64{{1, 2}, {3, 4}},
+			b:        [][]float64{{5, 6}, {7, 8}},
+			expected: [][]float64{{19, 22}, {43, 50}},
+		},
+		{
+			name:     "Non-square product",
+			a:        [][]float64{{1, 2, 3}},
+			b:        [][]float64{{1}, {-1}, {2}},
+			expected: [][]float64{{5}},
+		},
+		{
+			name:     "Identity",
+			a:        [][]float64{{1, 0}, {0, 1}},
+			b:        [][]float64{{2.5, -1}, {0, 3}},
+			expected: [][]float64{{2.5, -1}, {0, 3}},
+		},
+		{
+			name:        "Dimension mismatch",
+			a:           [][]float64{{1, 2}, {3, 4}},
+			b:           [][]float64{{1, 2, 3}},
+			expectedErr: ErrDimensionMismatch,
+			errMessage:  "matrix dimension mismatch: a is 2x2 and b is 1x3",
+		},
+		{
+			name:        "Ragged input",
+			a:           [][]float64{{1, 2}, {3}},
+			b:           [][]float64{{1}, {2}},
+			expectedErr: ErrInvalidMatrix,
+			errMessage:  "invalid matrix: a is ragged: row 1 has 1 columns, row 0 has 2",
+		},
+		{
+			name:        "Empty matrix",
+			a:           [][]float64{{1}},
+			b:           [][]float64{},
+			expectedErr: ErrInvalidMatrix,
+			errMessage:  "invalid matrix: b is empty",
+		},
+		{
+			name:        "Empty rows",
+			a:           [][]float64{{}, {}},
+			b:           [][]float64{{1}},
+			expectedErr: ErrInvalidMatrix,
+			errMessage:  "invalid matrix: a is empty",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.MatrixMultiply(tc.a, tc.b)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.EqualError(t, err, tc.errMessage)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
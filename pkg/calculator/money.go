@@ -0,0 +1,116 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidMoney is returned by ParseMoney for malformed amounts, and by the
+// cents arithmetic for results beyond the range of int64 cents
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// moneyPattern matches an optional minus sign, an optional dollar sign, an
+// integer part either grouped by commas in threes or not grouped at all, and
+// up to two decimal places
+var moneyPattern = regexp.MustCompile(`^-?\$?(\d{1,3}(,\d{3})+|\d+)(\.\d{1,2})?$`)
+
+// ParseMoney parses a dollar amount such as "$1,234.56", "-$5" or "0.5".
+// Returns an error wrapping ErrInvalidMoney if s is not such an amount, or is
+// beyond ±math.MaxInt64 cents.
+func ParseMoney(s string) (float64, error) {
+	cents, err := ParseCents(s)
+	if err != nil {
+		return 0, err
+	}
+	return float64(cents) / 100, nil
+}
+
+// ParseCents parses a dollar amount like ParseMoney, returning it as an exact
+// number of cents, so "$1,234.56" is 123456
+func ParseCents(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if !moneyPattern.MatchString(s) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	whole, fraction, _ := strings.Cut(strings.NewReplacer("$", "", ",", "").Replace(s), ".")
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+	cents, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidMoney, s)
+	}
+	return cents, nil
+}
+
+// AddCents returns a+b, or an error wrapping ErrInvalidMoney if the sum is
+// beyond the range of int64
+func AddCents(a, b int64) (int64, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, fmt.Errorf("%w: result is out of range", ErrInvalidMoney)
+	}
+	return sum, nil
+}
+
+// SubtractCents returns a-b, or an error wrapping ErrInvalidMoney if the
+// difference is beyond the range of int64
+func SubtractCents(a, b int64) (int64, error) {
+	diff := a - b
+	if (b > 0 && diff > a) || (b < 0 && diff < a) {
+		return 0, fmt.Errorf("%w: result is out of range", ErrInvalidMoney)
+	}
+	return diff, nil
+}
+
+// FormatCents formats an amount of cents as dollars, with commas grouping the
+// thousands, such as "$1,234.56" or "-$5.00"
+func FormatCents(cents int64) string {
+	// The magnitude of math.MinInt64 only fits in a uint64
+	magnitude := uint64(cents)
+	if cents < 0 {
+		magnitude = -magnitude
+	}
+	return formatMoneyDigits(cents < 0, strconv.FormatUint(magnitude, 10))
+}
+
+// FormatMoney formats v as a dollar amount rounded to cents, with commas
+// grouping the thousands, such as "$1,234.56" or "-$5.00". Amounts beyond
+// the range of int64 cents are formatted exactly as well; infinities and NaN
+// are formatted as "$NaN".
+func FormatMoney(v float64) string {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return "$NaN"
+	}
+	cents := math.Round(math.Abs(v) * 100)
+	return formatMoneyDigits(cents != 0 && v < 0, new(big.Float).SetFloat64(cents).Text('f', 0))
+}
+
+// formatMoneyDigits formats the decimal digits of an amount of cents
+func formatMoneyDigits(negative bool, digits string) string {
+	for len(digits) < 3 {
+		digits = "0" + digits
+	}
+	whole, fraction := digits[:len(digits)-2], digits[len(digits)-2:]
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	b.WriteByte('$')
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	b.WriteByte('.')
+	b.WriteString(fraction)
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseMoney tests parsing dollar amounts
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    float64
+		expectedErr error
+	}{
+		{"Symbol and grouping", "$1,234.56", 1234.56, nil},
+		{"Millions", "$1,234,567", 1234567, nil},
+		{"Leading symbol only", "$5", 5, nil},
+		{"Plain number", "1234.5", 1234.5, nil},
+		{"Negative", "-$5.25", -5.25, nil},
+		{"Surrounding space", " $10.00 ", 10, nil},
+		{"Misplaced comma", "$12,34.56", 0, ErrInvalidMoney},
+		{"Leading comma", "$,123", 0, ErrInvalidMoney},
+		{"Too many decimals", "$1.234", 0, ErrInvalidMoney},
+		{"Symbol after sign only", "$-5", 0, ErrInvalidMoney},
+		{"Garbage", "lots of money", 0, ErrInvalidMoney},
+		{"Empty", "", 0, ErrInvalidMoney},
+		{"Symbol alone", "$", 0, ErrInvalidMoney},
+		{"Beyond int64 cents", "99999999999999999999", 0, ErrInvalidMoney},
+		{"Negative beyond int64 cents", "-$92,233,720,368,547,758.09", 0, ErrInvalidMoney},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseMoney(tc.input)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+// TestFormatMoney tests formatting dollar amounts
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{1234.56, "$1,234.56"},
+		{1234567, "$1,234,567.00"},
+		{999.999, "$1,000.00"},
+		{0.5, "$0.50"},
+		{-5.25, "-$5.25"},
+		{-0.001, "$0.00"},
+		{0, "$0.00"},
+		{1e20, "$100,000,000,000,000,000,000.00"},
+		{-1e20, "-$100,000,000,000,000,000,000.00"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatMoney(tc.value))
+		})
+	}
+}
+
+// TestCents tests parsing, arithmetic and formatting on exact cents
+func TestCents(t *testing.T) {
+	cents, err := ParseCents("$1,234.5")
+	require.NoError(t, err)
+	assert.Equal(t, int64(123450), cents)
+
+	cents, err = ParseCents("-$92,233,720,368,547,758.08")
+	require.NoError(t, err)
+	assert.Equal(t, int64(math.MinInt64), cents)
+	assert.Equal(t, "-$92,233,720,368,547,758.08", FormatCents(cents))
+	assert.Equal(t, "$92,233,720,368,547,758.07", FormatCents(math.MaxInt64))
+	assert.Equal(t, "$0.05", FormatCents(5))
+	assert.Equal(t, "-$0.05", FormatCents(-5))
+
+	// 0.1 + 0.2 is exact in cents
+	sum, err := AddCents(10, 20)
+	require.NoError(t, err)
+	assert.Equal(t, "$0.30", FormatCents(sum))
+
+	tests := []struct {
+		name string
+		op   func(a, b int64) (int64, error)
+		a, b int64
+	}{
+		{"Add beyond maximum", AddCents, math.MaxInt64, 1},
+		{"Add beyond minimum", AddCents, math.MinInt64, -1},
+		{"Subtract beyond maximum", SubtractCents, math.MaxInt64, -1},
+		{"Subtract beyond minimum", SubtractCents, math.MinInt64, 1},
+		{"Subtract minimum from zero", SubtractCents, 0, math.MinInt64},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.op(tc.a, tc.b)
+			assert.ErrorIs(t, err, ErrInvalidMoney)
+		})
+	}
+}
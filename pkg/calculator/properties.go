@@ -0,0 +1,37 @@
+package calculator
+
+import "fmt"
+
+// OperationProperties describes the algebraic properties of a binary operation
+type OperationProperties struct {
+	// Commutative means op(a, b) == op(b, a)
+	Commutative bool
+	// Associative means op(op(a, b), c) == op(a, op(b, c))
+	Associative bool
+	// HasIdentity means some e has op(e, a) == op(a, e) == a for every a
+	HasIdentity bool
+	// Identity is that e, and is only meaningful if HasIdentity is set
+	Identity float64
+}
+
+// operationProperties maps each binary operation to its properties. The
+// properties are those of the operations on real numbers; floating point
+// addition and multiplication are only approximately associative.
+var operationProperties = map[string]OperationProperties{
+	"add":      {Commutative: true, Associative: true, HasIdentity: true, Identity: 0},
+	"subtract": {},
+	"multiply": {Commutative: true, Associative: true, HasIdentity: true, Identity: 1},
+	// a / 1 == a, but 1 / a is not, so 1 is only a right identity
+	"divide": {},
+}
+
+// Properties returns the algebraic properties of the binary operation named
+// op: add, subtract, multiply or divide.
+// Returns ErrUnknownOperation for any other name
+func (c *Calculator) Properties(op string) (OperationProperties, error) {
+	props, ok := operationProperties[op]
+	if !ok {
+		return OperationProperties{}, fmt.Errorf("%w %q", ErrUnknownOperation, op)
+	}
+	return props, nil
+}
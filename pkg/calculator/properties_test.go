@@ -0,0 +1,39 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProperties tests the algebraic properties reported for each operation
+func TestProperties(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		op          string
+		expected    OperationProperties
+		expectedErr error
+	}{
+		{"Add", "add", OperationProperties{Commutative: true, Associative: true, HasIdentity: true, Identity: 0}, nil},
+		{"Subtract", "subtract", OperationProperties{}, nil},
+		{"Multiply", "multiply", OperationProperties{Commutative: true, Associative: true, HasIdentity: true, Identity: 1}, nil},
+		{"Divide", "divide", OperationProperties{}, nil},
+		{"Unknown", "modulo", OperationProperties{}, ErrUnknownOperation},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			props, err := calc.Properties(tc.op)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, props)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rounding modes accepted by RoundMode
+const (
+	// RoundHalfUp rounds to the nearest value, with ties away from zero
+	RoundHalfUp = "half-up"
+	// RoundHalfEven rounds to the nearest value, with ties to the even
+	// neighbour (banker's rounding)
+	RoundHalfEven = "half-even"
+	// RoundFloor rounds towards negative infinity
+	RoundFloor = "floor"
+	// RoundCeil rounds towards positive infinity
+	RoundCeil = "ceil"
+	// RoundTrunc rounds towards zero
+	RoundTrunc = "trunc"
+)
+
+// maxRoundPlaces is the most decimal places a float64 meaningfully has
+const maxRoundPlaces = 15
+
+// RoundMode rounds value to places decimal places using mode, one of the
+// Round* constants. Negative places round to tens, hundreds and so on.
+// Rounding works on the binary value, so a decimal such as 2.675, stored as
+// 2.67499999..., rounds half-up to 2.67.
+// Returns ErrUnknownRoundingMode for other modes, or ErrPlacesOutOfRange if
+// places is beyond ±15
+func (c *Calculator) RoundMode(value float64, places int, mode string) (float64, error) {
+	var round func(float64) float64
+	switch mode {
+	case RoundHalfUp:
+		round = math.Round
+	case RoundHalfEven:
+		round = math.RoundToEven
+	case RoundFloor:
+		round = math.Floor
+	case RoundCeil:
+		round = math.Ceil
+	case RoundTrunc:
+		round = math.Trunc
+	default:
+		return 0, fmt.Errorf("%w %q", ErrUnknownRoundingMode, mode)
+	}
+	if places < -maxRoundPlaces || places > maxRoundPlaces {
+		return 0, ErrPlacesOutOfRange
+	}
+
+	scale := math.Pow10(places)
+	if math.IsInf(value*scale, 0) {
+		// Values this large have no fractional digits to round away
+		return value, nil
+	}
+	return round(value*scale) / scale, nil
+}
+
+// RoundToMultiple rounds value to the nearest multiple of multiple, with
+// ties away from zero, so 7 rounds to 5 and -8 to -10 with a multiple of 5.
+// Returns ErrInvalidMultiple unless multiple is positive and finite
+func (c *Calculator) RoundToMultiple(value, multiple float64) (float64, error) {
+	if !(multiple > 0) || math.IsInf(multiple, 1) {
+		return 0, fmt.Errorf("%w, got %g", ErrInvalidMultiple, multiple)
+	}
+
+	steps := value / multiple
+	if math.IsInf(steps, 0) {
+		// Values this large are already a whole number of multiples apart
+		return value, nil
+	}
+	return math.Round(steps) * multiple, nil
+}
@@ -0,0 +1,96 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoundMode tests each rounding mode, including half-up against
+// banker's rounding on ties
+func TestRoundMode(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		value       float64
+		places      int
+		mode        string
+		expected    float64
+		expectedErr error
+	}{
+		{"Half-up 2.5", 2.5, 0, RoundHalfUp, 3, nil},
+		{"Half-up 3.5", 3.5, 0, RoundHalfUp, 4, nil},
+		{"Half-even 2.5", 2.5, 0, RoundHalfEven, 2, nil},
+		{"Half-even 3.5", 3.5, 0, RoundHalfEven, 4, nil},
+		{"Half-up negative tie", -2.5, 0, RoundHalfUp, -3, nil},
+		{"Half-even negative tie", -2.5, 0, RoundHalfEven, -2, nil},
+		{"Half-up places", 1.25, 1, RoundHalfUp, 1.3, nil},
+		{"Half-even places", 1.25, 1, RoundHalfEven, 1.2, nil},
+		{"Floor", -1.21, 1, RoundFloor, -1.3, nil},
+		{"Ceil", 1.21, 1, RoundCeil, 1.3, nil},
+		{"Trunc", -1.29, 1, RoundTrunc, -1.2, nil},
+		{"Too large to scale", 1e300, 15, RoundHalfUp, 1e300, nil},
+		{"Negative places", 1234, -2, RoundHalfUp, 1200, nil},
+		{"Unknown mode", 2.5, 0, "up", 0, ErrUnknownRoundingMode},
+		{"Too many places", 2.5, 16, RoundHalfUp, 0, ErrPlacesOutOfRange},
+		{"Too few places", 2.5, -16, RoundHalfUp, 0, ErrPlacesOutOfRange},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.RoundMode(tc.value, tc.places, tc.mode)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tc.expected, result, 1e-12)
+		})
+	}
+}
+
+// TestRoundToMultiple tests rounding to the nearest multiple, including
+// negative values and multiples that are not positive
+func TestRoundToMultiple(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		value       float64
+		multiple    float64
+		expected    float64
+		expectedErr error
+	}{
+		{"7 to nearest 5", 7, 5, 5, nil},
+		{"8 to nearest 5", 8, 5, 10, nil},
+		{"Tie rounds away from zero", 7.5, 5, 10, nil},
+		{"Exact multiple", 15, 5, 15, nil},
+		{"Negative value", -7, 5, -5, nil},
+		{"Negative value rounds down", -8, 5, -10, nil},
+		{"Negative tie", -7.5, 5, -10, nil},
+		{"Fractional multiple", 1.37, 0.25, 1.25, nil},
+		{"Zero", 0, 5, 0, nil},
+		{"Too large to divide", 1e308, 1e-10, 1e308, nil},
+		{"Zero multiple", 7, 0, 0, ErrInvalidMultiple},
+		{"Negative multiple", 7, -5, 0, ErrInvalidMultiple},
+		{"NaN multiple", 7, math.NaN(), 0, ErrInvalidMultiple},
+		{"Infinite multiple", 7, math.Inf(1), 0, ErrInvalidMultiple},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.RoundToMultiple(tc.value, tc.multiple)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tc.expected, result, 1e-12)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Variance returns the variance of values. The population variance divides
+// by the number of values and needs at least one; the sample variance
+// divides by one less and needs at least two.
+// Returns ErrTooFewValues if there are not enough values
+func (c *Calculator) Variance(values []float64, sample bool) (float64, error) {
+	required := 1
+	if sample {
+		required = 2
+	}
+	if len(values) < required {
+		return 0, fmt.Errorf("%w: need at least %d, got %d", ErrTooFewValues, required, len(values))
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	// Summing squared deviations from the mean, rather than using the
+	// sum of squares, avoids cancellation when values are large
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+
+	n := float64(len(values))
+	if sample {
+		n--
+	}
+	return squares / n, nil
+}
+
+// StdDev returns the standard deviation of values, the square root of
+// Variance with the same sample flag and errors
+func (c *Calculator) StdDev(values []float64, sample bool) (float64, error) {
+	variance, err := c.Variance(values, sample)
+	if err != nil {
+		return 0, err
+	}
+	return math.Sqrt(variance), nil
+}
+
+// MovingAverage returns the trailing moving average of values over window
+// values. Element i of the result is the mean of values[i : i+window], so
+// the result has len(values)-window+1 elements and its first element
+// averages the first window values. A window of 1 returns a copy of values
+// and a window of len(values) returns their mean. Each window is summed
+// afresh rather than kept as a running sum, so neither rounding errors nor
+// an overflowing window carry over into the windows after it.
+// Returns ErrInvalidWindow unless 1 <= window <= len(values)
+func (c *Calculator) MovingAverage(values []float64, window int) ([]float64, error) {
+	if window < 1 || window > len(values) {
+		return nil, fmt.Errorf("%w: must be between 1 and %d, got %d", ErrInvalidWindow, len(values), window)
+	}
+
+	averages := make([]float64, 0, len(values)-window+1)
+	for i := range len(values) - window + 1 {
+		var sum float64
+		for _, v := range values[i : i+window] {
+			sum += v
+		}
+		averages = append(averages, sum/float64(window))
+	}
+	return averages, nil
+}
+
+// Percentile returns the p-th percentile of values, interpolating linearly
+// between the two nearest ranks, so p=50 is the median and p=0 and p=100
+// are the minimum and maximum. values is not modified.
+// Returns ErrPercentileOutOfRange unless 0 <= p <= 100, or ErrTooFewValues
+// if values is empty
+func (c *Calculator) Percentile(values []float64, p float64) (float64, error) {
+	if !(p >= 0 && p <= 100) {
+		return 0, fmt.Errorf("%w, got %g", ErrPercentileOutOfRange, p)
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%w: need at least 1, got 0", ErrTooFewValues)
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower], nil
+	}
+	// Weighting each rank avoids the difference of the two values, which
+	// overflows for values of opposite sign near the float64 limits
+	f := rank - float64(lower)
+	return sorted[lower]*(1-f) + sorted[upper]*f, nil
+}
+
+// Median returns the middle value of values, or the mean of the two middle
+// values when there is an even number of them. values is not modified.
+// Returns ErrTooFewValues if values is empty
+func (c *Calculator) Median(values []float64) (float64, error) {
+	return c.Percentile(values, 50)
+}
+
+// Mode returns the values occurring most often, in ascending order, so a
+// multimodal dataset has several modes and one where every value occurs
+// once has them all.
+// Returns ErrTooFewValues if values is empty
+func (c *Calculator) Mode(values []float64) ([]float64, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: need at least 1, got 0", ErrTooFewValues)
+	}
+
+	counts := make(map[float64]int, len(values))
+	most := 0
+	for _, v := range values {
+		counts[v]++
+		most = max(most, counts[v])
+	}
+
+	var modes []float64
+	for v, count := range counts {
+		if count == most {
+			modes = append(modes, v)
+		}
+	}
+	slices.Sort(modes)
+	return modes, nil
+}
@@ -0,0 +1,209 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVariance tests the Variance and StdDev methods for both variants
+func TestVariance(t *testing.T) {
+	calc := NewCalculator()
+
+	// Mean 5, squared deviations summing to 32
+	dataset := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+
+	tests := []struct {
+		name             string
+		values           []float64
+		sample           bool
+		expectedVariance float64
+		expectedStdDev   float64
+		expectedErr      error
+	}{
+		{"Population", dataset, false, 4, 2, nil},
+		{"Sample", dataset, true, 32.0 / 7, 2.138089935299395, nil},
+		{"Single value population", []float64{3}, false, 0, 0, nil},
+		{"Single value sample", []float64{3}, true, 0, 0, ErrTooFewValues},
+		{"Empty population", nil, false, 0, 0, ErrTooFewValues},
+		{"Large offset", []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}, true, 30, 5.477225575051661, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			variance, err := calc.Variance(tc.values, tc.sample)
+			stdDev, stdDevErr := calc.StdDev(tc.values, tc.sample)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.ErrorIs(t, stdDevErr, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, stdDevErr)
+			assert.InDelta(t, tc.expectedVariance, variance, 1e-9)
+			assert.InDelta(t, tc.expectedStdDev, stdDev, 1e-9)
+		})
+	}
+}
+
+// TestMovingAverage tests the trailing window at its boundaries
+func TestMovingAverage(t *testing.T) {
+	calc := NewCalculator()
+	values := []float64{1, 2, 3, 4, 5, 6}
+
+	tests := []struct {
+		name        string
+		values      []float64
+		window      int
+		expected    []float64
+		expectedErr error
+	}{
+		{"Window of 1", values, 1, []float64{1, 2, 3, 4, 5, 6}, nil},
+		{"Window of 3", values, 3, []float64{2, 3, 4, 5}, nil},
+		{"Window of len", values, 6, []float64{3.5}, nil},
+		{"Single value", []float64{-4}, 1, []float64{-4}, nil},
+		{"No drift after a large value", []float64{1e17, 1, 1, 1}, 2, []float64{5e16, 1, 1}, nil},
+		{"Recovers after overflow", []float64{1e308, 1e308, 1, 1}, 2, []float64{math.Inf(1), 5e307, 1}, nil},
+		{"Window of 0", values, 0, nil, ErrInvalidWindow},
+		{"Negative window", values, -1, nil, ErrInvalidWindow},
+		{"Window longer than values", values, 7, nil, ErrInvalidWindow},
+		{"No values", nil, 1, nil, ErrInvalidWindow},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.MovingAverage(tc.values, tc.window)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+				assert.Len(t, result, len(tc.values)-tc.window+1)
+			}
+		})
+	}
+
+	t.Run("Input is not modified", func(t *testing.T) {
+		result, err := calc.MovingAverage(values, 1)
+		assert.NoError(t, err)
+		result[0] = 100
+		assert.Equal(t, 1.0, values[0])
+	})
+}
+
+// TestPercentile tests interpolation between ranks, the boundaries and errors
+func TestPercentile(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		values      []float64
+		p           float64
+		expected    float64
+		expectedErr error
+	}{
+		{"Median of odd count", []float64{3, 1, 2}, 50, 2, nil},
+		{"Median of even count", []float64{4, 1, 3, 2}, 50, 2.5, nil},
+		{"Minimum", []float64{3, 1, 2}, 0, 1, nil},
+		{"Maximum", []float64{3, 1, 2}, 100, 3, nil},
+		{"Interpolated", []float64{10, 20, 30, 40, 50}, 95, 48, nil},
+		{"Single value", []float64{7}, 95, 7, nil},
+		{"Opposite extremes", []float64{1e308, -1e308}, 50, 0, nil},
+		{"Opposite extremes interpolated", []float64{1e308, -1e308}, 75, 5e307, nil},
+		{"Empty", nil, 50, 0, ErrTooFewValues},
+		{"Below range", []float64{1}, -1, 0, ErrPercentileOutOfRange},
+		{"Above range", []float64{1}, 100.5, 0, ErrPercentileOutOfRange},
+		{"NaN", []float64{1}, math.NaN(), 0, ErrPercentileOutOfRange},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Percentile(tc.values, tc.p)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tc.expected, result, 1e-9)
+		})
+	}
+}
+
+// TestPercentileDoesNotMutate tests that the input is left in its order
+func TestPercentileDoesNotMutate(t *testing.T) {
+	values := []float64{3, 1, 2}
+
+	_, err := NewCalculator().Percentile(values, 50)
+
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3, 1, 2}, values)
+}
+
+// TestMedian tests odd and even counts, where the middle two are averaged
+func TestMedian(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		values      []float64
+		expected    float64
+		expectedErr error
+	}{
+		{"Odd count", []float64{9, 1, 5}, 5, nil},
+		{"Even count", []float64{9, 1, 5, 2}, 3.5, nil},
+		{"Single value", []float64{4}, 4, nil},
+		{"Two values", []float64{1, 2}, 1.5, nil},
+		{"Opposite extremes", []float64{1e308, -1e308}, 0, nil},
+		{"Empty", nil, 0, ErrTooFewValues},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Median(tc.values)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tc.expected, result, 1e-9)
+		})
+	}
+}
+
+// TestMode tests single and multiple modes
+func TestMode(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name        string
+		values      []float64
+		expected    []float64
+		expectedErr error
+	}{
+		{"Single mode", []float64{1, 2, 2, 3}, []float64{2}, nil},
+		{"Bimodal", []float64{3, 1, 3, 2, 1}, []float64{1, 3}, nil},
+		{"Every value once", []float64{3, 1, 2}, []float64{1, 2, 3}, nil},
+		{"Single value", []float64{4}, []float64{4}, nil},
+		{"Empty", nil, nil, ErrTooFewValues},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Mode(tc.values)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -7,18 +7,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"testing"
-	"time"
 
 	_ "go-testing/docs" // Import swagger docs
 	"go-testing/internal/api"
 	"go-testing/internal/calculator"
 	"go-testing/internal/database"
+	"go-testing/internal/httpclient"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -42,12 +44,12 @@ func TestMain(m *testing.M) {
 		http.ListenAndServe(":8081", server.Router())
 	}()
 	
-	// Give the server a moment to start
-	time.Sleep(100 * time.Millisecond)
+	// Create a client that retries GETs, so tests do not race the server starting
+	client = httpclient.New(httpclient.WithRetries(5))
 	
-	// Create a client with a timeout
-	client = &http.Client{
-		Timeout: 5 * time.Second,
+	// Wait for the server to start
+	if resp, err := client.Get(serverURL + "/health"); err == nil {
+		resp.Body.Close()
 	}
 	
 	// Run tests
@@ -107,6 +109,25 @@ func TestSwaggerEndpoint(t *testing.T) {
 		assert.Contains(t, swaggerDoc, "info", "Should contain API info")
 		assert.Contains(t, swaggerDoc, "paths", "Should contain API paths")
 	})
+	
+	// Test Swagger YAML endpoint
+	t.Run("Swagger YAML", func(t *testing.T) {
+		resp, err := client.Get(serverURL + "/swagger/doc.yaml")
+		require.NoError(t, err, "Swagger YAML should be reachable")
+		defer resp.Body.Close()
+		
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Should get 200 OK from Swagger YAML")
+		assert.Equal(t, "application/yaml", resp.Header.Get("Content-Type"), "Content-Type should be YAML")
+		
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		
+		var swaggerDoc map[string]interface{}
+		err = yaml.Unmarshal(body, &swaggerDoc)
+		assert.NoError(t, err, "Should be valid YAML")
+		assert.Contains(t, swaggerDoc, "swagger", "Should contain swagger version")
+		assert.Contains(t, swaggerDoc, "paths", "Should contain API paths")
+	})
 }
 
 // TestUserCRUD tests the full CRUD cycle for users